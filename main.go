@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/jobs"
 	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/notify"
+	"github.com/jhonnydsl/clinify-backend/src/payment"
+	"github.com/jhonnydsl/clinify-backend/src/pix"
 	"github.com/jhonnydsl/clinify-backend/src/repository"
 	"github.com/jhonnydsl/clinify-backend/src/routes"
+	"github.com/jhonnydsl/clinify-backend/src/services"
 	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
 	"github.com/joho/godotenv"
 )
@@ -19,11 +30,26 @@ func main() {
 		return
 	}
 
-	email := os.Getenv("SMTP_EMAIL")
-	password := os.Getenv("SMTP_PASSWORD")
+	mailer, err := mailer.NewMailerFromEnv()
+	if err != nil {
+		log.Fatalf("error configuring mailer: %v", err)
+	}
 
-	mailer := mailer.NewMailer(email, password)
+	notifier, err := notify.NewNotifierFromEnv(mailer)
+	if err != nil {
+		log.Fatalf("error configuring notification providers: %v", err)
+	}
 	
+	pixProvider, err := pix.NewProviderFromEnv()
+	if err != nil {
+		log.Fatalf("error configuring pix provider: %v", err)
+	}
+
+	paymentGateway, err := payment.NewGatewayFromEnv()
+	if err != nil {
+		log.Fatalf("error configuring payment gateway: %v", err)
+	}
+
 	err = repository.Connect()
 	if err != nil {
 		log.Fatalf("error connecting to the database: %v", err)
@@ -32,15 +58,118 @@ func main() {
 	}
 	defer repository.DB.Close()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reminders := &jobs.ReminderScheduler{
+		Repo: &repository.ReminderRepository{},
+		Notifications: &services.NotificationService{
+			OutboxRepo: &repository.OutboxRepository{},
+			Email: &services.EmailService{Repo: &repository.EmailTemplateRepository{}},
+		},
+		Interval: time.Minute,
+	}
+	go reminders.Start(ctx)
+
+	notifications := &services.NotificationService{
+		OutboxRepo: &repository.OutboxRepository{},
+		Email: &services.EmailService{Repo: &repository.EmailTemplateRepository{}},
+	}
+
+	waitlistService := &services.WaitlistService{
+		Repo: &repository.WaitlistRepository{},
+		Admin: &services.AdminService{
+			Repo: &repository.AdminRepository{},
+			ExceptionRepo: &repository.AvailabilityExceptionRepository{},
+		},
+		Notifications: notifications,
+	}
+
+	waitlist := &jobs.WaitlistScheduler{
+		Service: waitlistService,
+		Interval: time.Minute,
+	}
+	go waitlist.Start(ctx)
+
+	bookingRequests := &jobs.BookingRequestScheduler{
+		Service: &services.BookingService{
+			Repo: &repository.BookingRepository{},
+			Notifications: notifications,
+			Waitlist: waitlistService,
+		},
+		Interval: time.Minute,
+	}
+	go bookingRequests.Start(ctx)
+
+	if paymentGateway != nil {
+		payments := &jobs.PaymentSyncScheduler{
+			Service: &services.PaymentService{
+				Repo: &repository.PaymentRepository{},
+				BillingRepo: &repository.BillingRepository{},
+				PatientRepo: &repository.PatientRepository{},
+				Gateway: paymentGateway,
+			},
+			Interval: 5 * time.Minute,
+		}
+		go payments.Start(ctx)
+	}
+
+	outbox := &jobs.OutboxWorker{
+		Repo: &repository.OutboxRepository{},
+		Notifier: notifier,
+		Workers: 4,
+		Interval: 5 * time.Second,
+	}
+	outboxDone := make(chan struct{})
+	go func() {
+		outbox.Start(ctx)
+		close(outboxDone)
+	}()
+
 	app := gin.Default()
 	app.Use(middlewares.ErrorMiddlewareHandle())
 
 	v1 := app.Group("/api/v1")
 	{
-		routes.SetupAdminRoutes(v1, mailer)
+		routes.SetupAdminRoutes(v1)
 		routes.SetupPatientRoutes(v1)
 		routes.SetupLoginRoutes(v1)
+		routes.SetupAppointmentRoutes(v1)
+		routes.SetupCalendarRoutes(v1)
+		routes.SetupWaitlistRoutes(v1)
+		routes.SetupBookingRoutes(v1)
+		routes.SetupBillingRoutes(v1)
+		routes.SetupPackageRoutes(v1)
+		routes.SetupReceiptRoutes(v1)
+		routes.SetupCertificateRoutes(v1)
+		routes.SetupTaxRoutes(v1)
+		routes.SetupInsuranceRoutes(v1)
+		routes.SetupReportRoutes(v1)
+		routes.SetupAnalyticsRoutes(v1)
+		routes.SetupPixRoutes(v1, pixProvider)
+		routes.SetupPaymentRoutes(v1, paymentGateway)
+	}
+
+	routes.SetupCalDAVRoutes(app)
+
+	server := &http.Server{Addr: ":8080", Handler: app}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("error starting server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("error shutting down server: %v", err)
 	}
 
-	app.Run(":8080")
-}
\ No newline at end of file
+	// let the outbox finish the messages it is sending before closing the database
+	<-outboxDone
+}
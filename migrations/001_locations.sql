@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS locations (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	address TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_locations_client_id ON locations (client_id);
+
+ALTER TABLE calendar_slots
+	ADD COLUMN IF NOT EXISTS location_id UUID REFERENCES locations(id) ON DELETE SET NULL,
+	ADD COLUMN IF NOT EXISTS modality TEXT NOT NULL DEFAULT 'in_person';
+
+ALTER TABLE appointments
+	ADD COLUMN IF NOT EXISTS location_id UUID REFERENCES locations(id) ON DELETE SET NULL,
+	ADD COLUMN IF NOT EXISTS modality TEXT NOT NULL DEFAULT 'in_person',
+	ADD COLUMN IF NOT EXISTS video_url TEXT NOT NULL DEFAULT '';
+
+-- keep the address psychologists already registered as their first location
+INSERT INTO locations (client_id, name, address)
+SELECT id, 'Consultório', office_address FROM clients
+WHERE office_address IS NOT NULL AND office_address <> ''
+AND NOT EXISTS (SELECT 1 FROM locations l WHERE l.client_id = clients.id);
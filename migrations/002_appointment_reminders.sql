@@ -0,0 +1,12 @@
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS reminders_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	ADD COLUMN IF NOT EXISTS reminder_offsets INTEGER[] NOT NULL DEFAULT '{1440,120}';
+
+-- one row per reminder sent; the primary key is what keeps several
+-- instances of the scheduler from sending the same reminder twice
+CREATE TABLE IF NOT EXISTS appointment_reminders (
+	appointment_id UUID NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+	offset_minutes INTEGER NOT NULL,
+	sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (appointment_id, offset_minutes)
+);
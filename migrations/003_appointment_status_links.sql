@@ -0,0 +1,5 @@
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS min_cancel_notice_hours INTEGER NOT NULL DEFAULT 24;
+
+ALTER TABLE appointments
+	ADD COLUMN IF NOT EXISTS cancelled_by TEXT;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS email_outbox (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID REFERENCES clients(id) ON DELETE CASCADE,
+	recipient TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	payload JSONB NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	max_attempts INTEGER NOT NULL DEFAULT 8,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	locked_until TIMESTAMPTZ,
+	last_error TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_client ON email_outbox (client_id, status);
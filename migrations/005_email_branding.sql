@@ -0,0 +1,15 @@
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS brand_name TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS brand_color TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS brand_logo_url TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS email_footer TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS email_template_overrides (
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	subject TEXT NOT NULL DEFAULT '',
+	html TEXT NOT NULL DEFAULT '',
+	text TEXT NOT NULL DEFAULT '',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (client_id, name)
+);
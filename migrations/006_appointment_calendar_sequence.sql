@@ -0,0 +1,2 @@
+ALTER TABLE appointments
+	ADD COLUMN IF NOT EXISTS ics_sequence INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1,22 @@
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS calendar_feed_token_hash TEXT,
+	ADD COLUMN IF NOT EXISTS calendar_feed_created_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_clients_calendar_feed_token_hash
+	ON clients (calendar_feed_token_hash)
+	WHERE calendar_feed_token_hash IS NOT NULL;
+
+ALTER TABLE appointments
+	ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+	NEW.updated_at = NOW();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_appointments_updated_at ON appointments;
+CREATE TRIGGER trg_appointments_updated_at
+	BEFORE UPDATE ON appointments
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
\ No newline at end of file
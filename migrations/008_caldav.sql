@@ -0,0 +1,25 @@
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS caldav_password_hash TEXT;
+
+-- events created by the psychologist in a CalDAV client. Busy ones block the
+-- matching time in the public availability.
+CREATE TABLE IF NOT EXISTS availability_exceptions (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	uid TEXT NOT NULL,
+	summary TEXT NOT NULL DEFAULT '',
+	starts_at TIMESTAMPTZ NOT NULL,
+	ends_at TIMESTAMPTZ NOT NULL,
+	recurring BOOLEAN NOT NULL DEFAULT FALSE,
+	busy BOOLEAN NOT NULL DEFAULT TRUE,
+	data TEXT NOT NULL,
+	etag TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (client_id, name),
+	UNIQUE (client_id, uid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_availability_exceptions_client_starts_at
+	ON availability_exceptions (client_id, starts_at);
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- the outbox now carries SMS and WhatsApp messages as well; email rows keep
+-- their mailer.Message payload
+ALTER TABLE email_outbox ADD COLUMN IF NOT EXISTS channel TEXT NOT NULL DEFAULT 'email';
+
+ALTER TABLE patients ADD COLUMN IF NOT EXISTS notification_channels TEXT[] NOT NULL DEFAULT '{email}';
@@ -0,0 +1,45 @@
+-- patients waiting for a time with a fully booked psychologist. weekdays
+-- follow calendar_slots (0 = Sunday).
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	weekdays INTEGER[] NOT NULL,
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	modality TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'waiting',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_entries_waiting
+	ON waitlist_entries (patient_id)
+	WHERE status = 'waiting';
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_client ON waitlist_entries (client_id, status, created_at);
+
+-- a freed slot offered to one waiting patient at a time. Only one offer per
+-- slot can be pending; when it expires or is declined the slot moves on to
+-- the next patient in line.
+CREATE TABLE IF NOT EXISTS waitlist_offers (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	entry_id UUID NOT NULL REFERENCES waitlist_entries(id) ON DELETE CASCADE,
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	date DATE NOT NULL,
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	location_id UUID REFERENCES locations(id) ON DELETE SET NULL,
+	modality TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	expires_at TIMESTAMPTZ NOT NULL,
+	appointment_id UUID REFERENCES appointments(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	responded_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_offers_pending_slot
+	ON waitlist_offers (client_id, date, start_time)
+	WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_offers_entry ON waitlist_offers (entry_id, status);
+CREATE INDEX IF NOT EXISTS idx_waitlist_offers_expiry ON waitlist_offers (status, expires_at);
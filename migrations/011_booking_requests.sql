@@ -0,0 +1,31 @@
+-- instant: patients book straight into the agenda. approval: bookings become
+-- requests the psychologist has to accept within booking_hold_hours.
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS booking_mode TEXT NOT NULL DEFAULT 'instant',
+	ADD COLUMN IF NOT EXISTS booking_hold_hours INTEGER NOT NULL DEFAULT 24;
+
+CREATE TABLE IF NOT EXISTS booking_requests (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	date DATE NOT NULL,
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	location_id UUID REFERENCES locations(id) ON DELETE SET NULL,
+	modality TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	expires_at TIMESTAMPTZ NOT NULL,
+	decline_reason TEXT NOT NULL DEFAULT '',
+	appointment_id UUID REFERENCES appointments(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	responded_at TIMESTAMPTZ
+);
+
+-- a pending request holds its slot, so only one can exist per time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_booking_requests_pending_slot
+	ON booking_requests (client_id, date, start_time)
+	WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_booking_requests_client ON booking_requests (client_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_booking_requests_patient ON booking_requests (patient_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_booking_requests_expiry ON booking_requests (status, expires_at);
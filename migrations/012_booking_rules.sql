@@ -0,0 +1,7 @@
+-- limits applied to patient bookings. a cap of 0 means no limit.
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS booking_min_notice_hours INTEGER NOT NULL DEFAULT 2,
+	ADD COLUMN IF NOT EXISTS booking_max_horizon_days INTEGER NOT NULL DEFAULT 60,
+	ADD COLUMN IF NOT EXISTS booking_max_per_day INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS booking_max_per_week INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS booking_max_open_per_patient INTEGER NOT NULL DEFAULT 0;
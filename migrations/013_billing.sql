@@ -0,0 +1,29 @@
+-- amounts are stored in cents. a patient without a custom price pays the
+-- psychologist's default price.
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS session_price_cents BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE patients
+	ADD COLUMN IF NOT EXISTS session_price_cents BIGINT;
+
+-- charges and refunds raise what the patient owes, payments and discounts
+-- lower it
+CREATE TABLE IF NOT EXISTS ledger_entries (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	appointment_id UUID REFERENCES appointments(id) ON DELETE SET NULL,
+	kind TEXT NOT NULL,
+	amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+	description TEXT NOT NULL DEFAULT '',
+	method TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- an appointment is charged once, however many times its status is saved
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_entries_appointment_charge
+	ON ledger_entries (appointment_id)
+	WHERE kind = 'charge';
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_patient ON ledger_entries (patient_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_client ON ledger_entries (client_id, created_at);
@@ -0,0 +1,25 @@
+-- the pix key codes are generated for. name and city are printed in the
+-- payer's banking app.
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS pix_key TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS pix_merchant_name TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS pix_merchant_city TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS pix_charges (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	charge_entry_id UUID REFERENCES ledger_entries(id) ON DELETE SET NULL,
+	txid TEXT NOT NULL UNIQUE,
+	amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+	description TEXT NOT NULL DEFAULT '',
+	br_code TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	end_to_end_id TEXT UNIQUE,
+	payment_entry_id UUID REFERENCES ledger_entries(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	paid_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_pix_charges_client ON pix_charges (client_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_pix_charges_patient ON pix_charges (patient_id, created_at);
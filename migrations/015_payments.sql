@@ -0,0 +1,38 @@
+-- card and boleto payments made through a payment gateway. captured_cents
+-- and refunded_cents are the totals already booked on the patient's ledger.
+CREATE TABLE IF NOT EXISTS payments (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	charge_entry_id UUID REFERENCES ledger_entries(id) ON DELETE SET NULL,
+	gateway TEXT NOT NULL,
+	gateway_charge_id TEXT NOT NULL,
+	method TEXT NOT NULL,
+	status TEXT NOT NULL,
+	amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+	captured_cents BIGINT NOT NULL DEFAULT 0,
+	refunded_cents BIGINT NOT NULL DEFAULT 0,
+	description TEXT NOT NULL DEFAULT '',
+	failure_reason TEXT NOT NULL DEFAULT '',
+	boleto_url TEXT NOT NULL DEFAULT '',
+	boleto_line TEXT NOT NULL DEFAULT '',
+	due_date DATE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (gateway, gateway_charge_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_client ON payments (client_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_payments_patient ON payments (patient_id, created_at);
+
+-- webhook events already handled, so gateway retries are ignored
+CREATE TABLE IF NOT EXISTS payment_events (
+	gateway TEXT NOT NULL,
+	event_id TEXT NOT NULL,
+	received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (gateway, event_id)
+);
+
+-- ledger entries booked for a gateway payment point back to it
+ALTER TABLE ledger_entries
+	ADD COLUMN IF NOT EXISTS payment_id UUID REFERENCES payments(id) ON DELETE SET NULL;
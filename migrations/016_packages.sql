@@ -0,0 +1,41 @@
+-- packages of prepaid sessions sold by the psychologist. validity_days is
+-- counted from the purchase; null means the credits never expire.
+CREATE TABLE IF NOT EXISTS packages (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	sessions INT NOT NULL CHECK (sessions > 0),
+	price_cents BIGINT NOT NULL CHECK (price_cents >= 0),
+	validity_days INT CHECK (validity_days > 0),
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_packages_client ON packages (client_id);
+
+-- a package bought by a patient. name, sessions and price are copied so
+-- later edits to the package do not change past purchases.
+CREATE TABLE IF NOT EXISTS patient_packages (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	package_id UUID REFERENCES packages(id) ON DELETE SET NULL,
+	name TEXT NOT NULL,
+	sessions INT NOT NULL,
+	price_cents BIGINT NOT NULL,
+	expires_on DATE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_patient_packages_patient ON patient_packages (patient_id, created_at);
+
+-- one credit per appointment: reserved when it is booked, used when it is
+-- completed or cancelled late, and deleted (returned) otherwise
+CREATE TABLE IF NOT EXISTS package_credits (
+	appointment_id UUID PRIMARY KEY REFERENCES appointments(id) ON DELETE CASCADE,
+	patient_package_id UUID NOT NULL REFERENCES patient_packages(id) ON DELETE CASCADE,
+	used_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_package_credits_package ON package_credits (patient_package_id);
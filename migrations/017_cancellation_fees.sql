@@ -0,0 +1,14 @@
+-- fees for cancelling inside the min_cancel_notice_hours window and for not
+-- showing up. null charges the patient's session price, and spends a package
+-- credit when the patient has one; 0 charges nothing.
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS late_cancel_fee_cents BIGINT CHECK (late_cancel_fee_cents >= 0),
+	ADD COLUMN IF NOT EXISTS no_show_fee_cents BIGINT CHECK (no_show_fee_cents >= 0),
+	ADD COLUMN IF NOT EXISTS cancellation_policy TEXT NOT NULL DEFAULT '';
+
+-- fee_cents is what was charged for a late cancellation or no-show, 0 when a
+-- package credit covered it
+ALTER TABLE appointments
+	ADD COLUMN IF NOT EXISTS late_cancelled BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS fee_cents BIGINT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS fee_waived_at TIMESTAMPTZ;
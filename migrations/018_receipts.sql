@@ -0,0 +1,36 @@
+-- receipts need the cpf of both the psychologist and the patient.
+-- receipt_counter numbers each psychologist's receipts, 1, 2, 3...
+ALTER TABLE clients
+	ADD COLUMN IF NOT EXISTS cpf TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS receipt_counter INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE patients
+	ADD COLUMN IF NOT EXISTS cpf TEXT NOT NULL DEFAULT '';
+
+-- a receipt keeps a copy of what it certifies, so it reads the same however
+-- profiles and the ledger change later. it covers either one payment
+-- (entry_id) or the payments of one month that have no receipt of their own
+-- (period, the first day of the month).
+CREATE TABLE IF NOT EXISTS receipts (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	number INTEGER NOT NULL,
+	entry_id UUID REFERENCES ledger_entries(id) ON DELETE SET NULL,
+	period DATE,
+	issuer_name TEXT NOT NULL,
+	issuer_crp TEXT NOT NULL,
+	issuer_cpf TEXT NOT NULL,
+	issuer_address TEXT NOT NULL,
+	patient_name TEXT NOT NULL,
+	patient_cpf TEXT NOT NULL,
+	reference TEXT NOT NULL,
+	items JSONB NOT NULL,
+	total_cents BIGINT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (client_id, number)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_receipts_entry ON receipts (entry_id) WHERE entry_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_receipts_period ON receipts (patient_id, period) WHERE period IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_receipts_client ON receipts (client_id, number);
@@ -0,0 +1,17 @@
+-- an attendance certificate (declaração de comparecimento) keeps a copy of
+-- what it states, so the public verification page shows exactly what was
+-- printed. code is what third parties type in to check the document.
+CREATE TABLE IF NOT EXISTS attendance_certificates (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	appointment_id UUID NOT NULL UNIQUE REFERENCES appointments(id) ON DELETE CASCADE,
+	code TEXT NOT NULL UNIQUE,
+	issuer_name TEXT NOT NULL,
+	issuer_crp TEXT NOT NULL,
+	patient_name TEXT NOT NULL,
+	date DATE NOT NULL,
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+-- payer_cpf is who pays for the sessions when it is not the patient, e.g. a
+-- parent of a minor. Carnê-Leão asks for both the payer and the beneficiary.
+ALTER TABLE patients
+	ADD COLUMN IF NOT EXISTS payer_cpf TEXT NOT NULL DEFAULT '';
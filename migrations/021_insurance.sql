@@ -0,0 +1,74 @@
+-- health insurance plans (convênios) the psychologist accepts.
+-- session_price_cents is what the plan pays for each session.
+CREATE TABLE IF NOT EXISTS insurance_plans (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	ans_code TEXT NOT NULL DEFAULT '',
+	session_price_cents BIGINT NOT NULL CHECK (session_price_cents >= 0),
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_insurance_plans_client ON insurance_plans (client_id);
+
+-- a patient's card (carteirinha) on a plan
+CREATE TABLE IF NOT EXISTS patient_coverages (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	patient_id UUID NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	plan_id UUID NOT NULL REFERENCES insurance_plans(id) ON DELETE CASCADE,
+	card_number TEXT NOT NULL,
+	valid_until DATE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (patient_id, plan_id)
+);
+
+-- an authorization guide (guia) from the plan, good for a number of sessions
+-- between valid_from and valid_until. appointments point to the guide they
+-- are performed under.
+CREATE TABLE IF NOT EXISTS insurance_authorizations (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	coverage_id UUID NOT NULL REFERENCES patient_coverages(id) ON DELETE CASCADE,
+	number TEXT NOT NULL,
+	sessions INT NOT NULL CHECK (sessions > 0),
+	valid_from DATE NOT NULL,
+	valid_until DATE NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (coverage_id, number)
+);
+
+ALTER TABLE appointments
+	ADD COLUMN IF NOT EXISTS authorization_id UUID REFERENCES insurance_authorizations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_appointments_authorization ON appointments (authorization_id) WHERE authorization_id IS NOT NULL;
+
+-- a billing batch (lote) of sessions submitted to a plan for one month.
+-- items copy what was submitted; a session is billed in one batch only.
+CREATE TABLE IF NOT EXISTS insurance_batches (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+	plan_id UUID NOT NULL REFERENCES insurance_plans(id) ON DELETE CASCADE,
+	plan_name TEXT NOT NULL,
+	period DATE NOT NULL,
+	total_cents BIGINT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_insurance_batches_client ON insurance_batches (client_id, period);
+
+CREATE TABLE IF NOT EXISTS insurance_batch_items (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	batch_id UUID NOT NULL REFERENCES insurance_batches(id) ON DELETE CASCADE,
+	appointment_id UUID UNIQUE REFERENCES appointments(id) ON DELETE SET NULL,
+	patient_name TEXT NOT NULL,
+	card_number TEXT NOT NULL,
+	authorization_number TEXT NOT NULL,
+	date DATE NOT NULL,
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	amount_cents BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_insurance_batch_items_batch ON insurance_batch_items (batch_id, date, start_time);
@@ -0,0 +1,7 @@
+-- the analytics dashboard aggregates appointments by psychologist and date,
+-- and looks up each patient's completed sessions
+CREATE INDEX IF NOT EXISTS idx_appointments_client_date ON appointments (client_id, date);
+
+CREATE INDEX IF NOT EXISTS idx_appointments_completed
+	ON appointments (client_id, patient_id, date)
+	WHERE status = 'completed';
@@ -0,0 +1,6 @@
+-- signature is an HMAC of what the certificate states, printed on the PDF.
+-- The verification page recomputes it, so a row changed after issue no
+-- longer verifies. Certificates are signed when they are inserted; there are
+-- no unsigned ones to carry over.
+ALTER TABLE attendance_certificates
+	ADD COLUMN IF NOT EXISTS signature TEXT NOT NULL CHECK (signature <> '');
\ No newline at end of file
@@ -0,0 +1,71 @@
+package caldav
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/emersion/go-webdav"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// maxObjectSize limits the body of PUT requests.
+const maxObjectSize = 256 << 10
+
+// Methods lists the HTTP methods the CalDAV handler answers.
+var Methods = []string{
+	http.MethodOptions, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+	"PROPFIND", "PROPPATCH", "REPORT", "MKCOL", "COPY", "MOVE",
+}
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, username, password string) (uuid.UUID, error)
+}
+
+type contextKey struct{}
+
+// BasicAuthMiddleware authenticates calendar apps, which only speak HTTP
+// basic auth, and stores the admin id in the request context.
+func BasicAuthMiddleware(auth Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			unauthorized(c)
+			return
+		}
+
+		ctx, cancel := utils.NewDBContext()
+		defer cancel()
+
+		adminID, err := auth.Authenticate(ctx, username, password)
+		if err != nil {
+			if utils.GetStatusCode(err) != http.StatusUnauthorized {
+				c.AbortWithStatus(utils.GetStatusCode(err))
+				return
+			}
+
+			unauthorized(c)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxObjectSize)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, adminID))
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Basic realm="Clinify", charset="UTF-8"`)
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
+
+func adminFromContext(ctx context.Context) (uuid.UUID, error) {
+	adminID, ok := ctx.Value(contextKey{}).(uuid.UUID)
+	if !ok {
+		return uuid.UUID{}, webdav.NewHTTPError(http.StatusUnauthorized, fmt.Errorf("not authenticated"))
+	}
+
+	return adminID, nil
+}
\ No newline at end of file
@@ -0,0 +1,262 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	goical "github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav"
+	gocaldav "github.com/emersion/go-webdav/caldav"
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// CalendarName is the only calendar each psychologist has.
+const CalendarName = "clinify"
+
+// Store holds the calendar objects of each psychologist.
+type Store interface {
+	ListObjects(ctx context.Context, adminID uuid.UUID) ([]dtos.CalendarObject, error)
+	GetObject(ctx context.Context, adminID uuid.UUID, name string) (dtos.CalendarObject, error)
+	PutObject(ctx context.Context, adminID uuid.UUID, name string, data []byte, ifMatch, ifNoneMatch string) (dtos.CalendarObject, error)
+	DeleteObject(ctx context.Context, adminID uuid.UUID, name string) error
+}
+
+// Backend maps the CalDAV tree onto a Store:
+//
+//	{prefix}/{admin id}/                          principal
+//	{prefix}/{admin id}/calendars/                calendar home
+//	{prefix}/{admin id}/calendars/clinify/        calendar
+//	{prefix}/{admin id}/calendars/clinify/{name}  event
+type Backend struct {
+	Store  Store
+	Prefix string
+}
+
+func NewHandler(store Store, prefix string) http.Handler {
+	prefix = strings.TrimRight(prefix, "/")
+
+	return &gocaldav.Handler{
+		Backend: &Backend{Store: store, Prefix: prefix},
+		Prefix:  prefix,
+	}
+}
+
+func (b *Backend) CurrentUserPrincipal(ctx context.Context) (string, error) {
+	adminID, err := adminFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s/", b.Prefix, adminID), nil
+}
+
+func (b *Backend) CalendarHomeSetPath(ctx context.Context) (string, error) {
+	principal, err := b.CurrentUserPrincipal(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return principal + "calendars/", nil
+}
+
+func (b *Backend) CreateCalendar(ctx context.Context, calendar *gocaldav.Calendar) error {
+	return webdav.NewHTTPError(http.StatusForbidden, fmt.Errorf("creating calendars is not supported"))
+}
+
+func (b *Backend) ListCalendars(ctx context.Context) ([]gocaldav.Calendar, error) {
+	calendar, err := b.calendar(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return []gocaldav.Calendar{calendar}, nil
+}
+
+func (b *Backend) GetCalendar(ctx context.Context, calendarPath string) (*gocaldav.Calendar, error) {
+	calendar, err := b.calendar(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !samePath(calendarPath, calendar.Path) {
+		return nil, webdav.NewHTTPError(http.StatusNotFound, fmt.Errorf("calendar not found"))
+	}
+
+	return &calendar, nil
+}
+
+func (b *Backend) GetCalendarObject(ctx context.Context, objectPath string, req *gocaldav.CalendarCompRequest) (*gocaldav.CalendarObject, error) {
+	adminID, name, err := b.objectName(ctx, objectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := b.Store.GetObject(ctx, adminID, name)
+	if err != nil {
+		return nil, httpError(err)
+	}
+
+	return b.toCalendarObject(adminID, object)
+}
+
+func (b *Backend) ListCalendarObjects(ctx context.Context, calendarPath string, req *gocaldav.CalendarCompRequest) ([]gocaldav.CalendarObject, error) {
+	if _, err := b.GetCalendar(ctx, calendarPath); err != nil {
+		return nil, err
+	}
+
+	adminID, err := adminFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := b.Store.ListObjects(ctx, adminID)
+	if err != nil {
+		return nil, httpError(err)
+	}
+
+	result := make([]gocaldav.CalendarObject, 0, len(objects))
+
+	for _, object := range objects {
+		calendarObject, err := b.toCalendarObject(adminID, object)
+		if err != nil {
+			// a single unreadable event must not hide the whole calendar
+			utils.LogError("listCalendarObjects caldav (error decoding object "+object.Name+")", err)
+			continue
+		}
+
+		result = append(result, *calendarObject)
+	}
+
+	return result, nil
+}
+
+func (b *Backend) QueryCalendarObjects(ctx context.Context, calendarPath string, query *gocaldav.CalendarQuery) ([]gocaldav.CalendarObject, error) {
+	objects, err := b.ListCalendarObjects(ctx, calendarPath, &query.CompRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return gocaldav.Filter(query, objects)
+}
+
+func (b *Backend) PutCalendarObject(ctx context.Context, objectPath string, calendar *goical.Calendar, opts *gocaldav.PutCalendarObjectOptions) (*gocaldav.CalendarObject, error) {
+	adminID, name, err := b.objectName(ctx, objectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := goical.NewEncoder(&buf).Encode(calendar); err != nil {
+		return nil, webdav.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	ifMatch, err := conditionalETag(opts.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	ifNoneMatch, err := conditionalETag(opts.IfNoneMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := b.Store.PutObject(ctx, adminID, name, buf.Bytes(), ifMatch, ifNoneMatch)
+	if err != nil {
+		return nil, httpError(err)
+	}
+
+	return b.toCalendarObject(adminID, object)
+}
+
+func (b *Backend) DeleteCalendarObject(ctx context.Context, objectPath string) error {
+	adminID, name, err := b.objectName(ctx, objectPath)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Store.DeleteObject(ctx, adminID, name); err != nil {
+		return httpError(err)
+	}
+
+	return nil
+}
+
+func (b *Backend) calendar(ctx context.Context) (gocaldav.Calendar, error) {
+	home, err := b.CalendarHomeSetPath(ctx)
+	if err != nil {
+		return gocaldav.Calendar{}, err
+	}
+
+	return gocaldav.Calendar{
+		Path:                  home + CalendarName + "/",
+		Name:                  "Clinify",
+		Description:           "Atendimentos e bloqueios de agenda",
+		MaxResourceSize:       maxObjectSize,
+		SupportedComponentSet: []string{goical.CompEvent},
+	}, nil
+}
+
+// objectName checks the path points into the caller's calendar and returns
+// the object name.
+func (b *Backend) objectName(ctx context.Context, objectPath string) (uuid.UUID, string, error) {
+	adminID, err := adminFromContext(ctx)
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	calendar, err := b.calendar(ctx)
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	dir, name := path.Split(objectPath)
+	if !samePath(dir, calendar.Path) || name == "" {
+		return uuid.UUID{}, "", webdav.NewHTTPError(http.StatusNotFound, fmt.Errorf("calendar object not found"))
+	}
+
+	return adminID, name, nil
+}
+
+func (b *Backend) toCalendarObject(adminID uuid.UUID, object dtos.CalendarObject) (*gocaldav.CalendarObject, error) {
+	data, err := goical.NewDecoder(bytes.NewReader(object.Data)).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gocaldav.CalendarObject{
+		Path:          fmt.Sprintf("%s/%s/calendars/%s/%s", b.Prefix, adminID, CalendarName, object.Name),
+		ModTime:       object.ModTime,
+		ContentLength: int64(len(object.Data)),
+		ETag:          object.ETag,
+		Data:          data,
+	}, nil
+}
+
+func conditionalETag(match webdav.ConditionalMatch) (string, error) {
+	if !match.IsSet() || match.IsWildcard() {
+		return string(match), nil
+	}
+
+	etag, err := match.ETag()
+	if err != nil {
+		return "", webdav.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	return etag, nil
+}
+
+func samePath(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// httpError keeps the status of service errors so clients see 404, 403 or
+// 412 instead of a generic failure.
+func httpError(err error) error {
+	return webdav.NewHTTPError(utils.GetStatusCode(err), err)
+}
\ No newline at end of file
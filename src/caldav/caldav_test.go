@@ -0,0 +1,384 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	goical "github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav"
+	gocaldav "github.com/emersion/go-webdav/caldav"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const (
+	testUser     = "psi@clinify.test"
+	testPassword = "app-password"
+)
+
+// memoryStore keeps calendar objects in memory. Objects in readOnly stand in
+// for appointments, which calendar apps may not change.
+type memoryStore struct {
+	mu       sync.Mutex
+	objects  map[string]dtos.CalendarObject
+	readOnly map[string]bool
+}
+
+func (s *memoryStore) ListObjects(ctx context.Context, adminID uuid.UUID) ([]dtos.CalendarObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objects := make([]dtos.CalendarObject, 0, len(s.objects))
+	for _, object := range s.objects {
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+func (s *memoryStore) GetObject(ctx context.Context, adminID uuid.UUID, name string) (dtos.CalendarObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[name]
+	if !ok {
+		return dtos.CalendarObject{}, utils.NotFoundError("calendar object not found")
+	}
+
+	return object, nil
+}
+
+func (s *memoryStore) PutObject(ctx context.Context, adminID uuid.UUID, name string, data []byte, ifMatch, ifNoneMatch string) (dtos.CalendarObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.readOnly[name] {
+		return dtos.CalendarObject{}, utils.ForbiddenError("appointments can only be changed in Clinify")
+	}
+
+	existing, exists := s.objects[name]
+
+	if ifNoneMatch != "" && exists && (ifNoneMatch == "*" || ifNoneMatch == existing.ETag) {
+		return dtos.CalendarObject{}, utils.PreconditionFailedError("calendar object already exists")
+	}
+
+	if ifMatch != "" && (!exists || (ifMatch != "*" && ifMatch != existing.ETag)) {
+		return dtos.CalendarObject{}, utils.PreconditionFailedError("calendar object was changed")
+	}
+
+	sum := sha256.Sum256(data)
+
+	object := dtos.CalendarObject{Name: name, ETag: hex.EncodeToString(sum[:16]), ModTime: time.Now(), Data: data}
+	s.objects[name] = object
+
+	return object, nil
+}
+
+func (s *memoryStore) DeleteObject(ctx context.Context, adminID uuid.UUID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.readOnly[name] {
+		return utils.ForbiddenError("appointments can only be changed in Clinify")
+	}
+
+	if _, ok := s.objects[name]; !ok {
+		return utils.NotFoundError("calendar object not found")
+	}
+
+	delete(s.objects, name)
+
+	return nil
+}
+
+type staticAuthenticator struct {
+	adminID uuid.UUID
+}
+
+func (a staticAuthenticator) Authenticate(ctx context.Context, username, password string) (uuid.UUID, error) {
+	if username != testUser || password != testPassword {
+		return uuid.UUID{}, utils.UnauthorizedError("invalid credentials")
+	}
+
+	return a.adminID, nil
+}
+
+// newTestServer serves the handler the way SetupCalDAVRoutes mounts it, with
+// one appointment already in the calendar.
+func newTestServer(t *testing.T) (*httptest.Server, *memoryStore, uuid.UUID) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	adminID := uuid.New()
+	store := &memoryStore{objects: make(map[string]dtos.CalendarObject), readOnly: make(map[string]bool)}
+
+	appointment := encodeCalendar(t, newEvent("appointment@clinify", "Atendimento", time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)))
+	if _, err := store.PutObject(context.Background(), adminID, "appointment.ics", appointment, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	store.readOnly["appointment.ics"] = true
+
+	handler := gin.WrapH(NewHandler(store, "/caldav"))
+
+	app := gin.New()
+	dav := app.Group("", BasicAuthMiddleware(staticAuthenticator{adminID: adminID}))
+	for _, method := range Methods {
+		dav.Handle(method, "/caldav/*path", handler)
+	}
+
+	server := httptest.NewServer(app)
+	t.Cleanup(server.Close)
+
+	return server, store, adminID
+}
+
+func newClient(t *testing.T, server *httptest.Server, password string) *gocaldav.Client {
+	t.Helper()
+
+	client, err := gocaldav.NewClient(webdav.HTTPClientWithBasicAuth(server.Client(), testUser, password), server.URL+"/caldav/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func newEvent(uid, summary string, start time.Time) *goical.Event {
+	event := goical.NewEvent()
+	event.Props.SetText(goical.PropUID, uid)
+	event.Props.SetText(goical.PropSummary, summary)
+	event.Props.SetDateTime(goical.PropDateTimeStamp, start)
+	event.Props.SetDateTime(goical.PropDateTimeStart, start)
+	event.Props.SetDateTime(goical.PropDateTimeEnd, start.Add(time.Hour))
+
+	return event
+}
+
+func newCalendar(event *goical.Event) *goical.Calendar {
+	calendar := goical.NewCalendar()
+	calendar.Props.SetText(goical.PropVersion, "2.0")
+	calendar.Props.SetText(goical.PropProductID, "-//Clinify//Test//PT")
+	calendar.Children = append(calendar.Children, event.Component)
+
+	return calendar
+}
+
+func encodeCalendar(t *testing.T, event *goical.Event) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := goical.NewEncoder(&buf).Encode(newCalendar(event)); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDiscovery(t *testing.T) {
+	server, _, adminID := newTestServer(t)
+	client := newClient(t, server, testPassword)
+	ctx := context.Background()
+
+	principal, err := client.FindCurrentUserPrincipal(ctx)
+	if err != nil {
+		t.Fatalf("FindCurrentUserPrincipal() error = %v", err)
+	}
+
+	if want := "/caldav/" + adminID.String() + "/"; principal != want {
+		t.Errorf("principal = %q, want %q", principal, want)
+	}
+
+	home, err := client.FindCalendarHomeSet(ctx, principal)
+	if err != nil {
+		t.Fatalf("FindCalendarHomeSet() error = %v", err)
+	}
+
+	calendars, err := client.FindCalendars(ctx, home)
+	if err != nil {
+		t.Fatalf("FindCalendars() error = %v", err)
+	}
+
+	if len(calendars) != 1 || calendars[0].Path != home+CalendarName+"/" {
+		t.Fatalf("calendars = %+v, want only %s", calendars, home+CalendarName+"/")
+	}
+}
+
+func TestQueryPutAndDeleteException(t *testing.T) {
+	server, store, adminID := newTestServer(t)
+	client := newClient(t, server, testPassword)
+	ctx := context.Background()
+
+	calendarPath := "/caldav/" + adminID.String() + "/calendars/" + CalendarName + "/"
+
+	query := func(start, end time.Time) []string {
+		t.Helper()
+
+		objects, err := client.QueryCalendar(ctx, calendarPath, &gocaldav.CalendarQuery{
+			CompRequest: gocaldav.CalendarCompRequest{
+				Name:  goical.CompCalendar,
+				Comps: []gocaldav.CalendarCompRequest{{Name: goical.CompEvent, Props: []string{goical.PropUID, goical.PropSummary}}},
+			},
+			CompFilter: gocaldav.CompFilter{
+				Name:  goical.CompCalendar,
+				Comps: []gocaldav.CompFilter{{Name: goical.CompEvent, Start: start, End: end}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("QueryCalendar() error = %v", err)
+		}
+
+		uids := make([]string, 0, len(objects))
+		for _, object := range objects {
+			for _, event := range object.Data.Events() {
+				uid, _ := event.Props.Text(goical.PropUID)
+				uids = append(uids, uid)
+			}
+		}
+
+		return uids
+	}
+
+	march := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	if uids := query(march, march.AddDate(0, 1, 0)); len(uids) != 1 || uids[0] != "appointment@clinify" {
+		t.Fatalf("query before PUT = %v, want the appointment", uids)
+	}
+
+	exceptionPath := calendarPath + "blocked.ics"
+	exception := newEvent("blocked-1", "Congresso", time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC))
+
+	if _, err := client.PutCalendarObject(ctx, exceptionPath, newCalendar(exception)); err != nil {
+		t.Fatalf("PutCalendarObject() error = %v", err)
+	}
+
+	if _, err := store.GetObject(ctx, adminID, "blocked.ics"); err != nil {
+		t.Fatalf("exception not stored: %v", err)
+	}
+
+	if uids := query(march, march.AddDate(0, 1, 0)); len(uids) != 2 {
+		t.Fatalf("query after PUT = %v, want the appointment and the exception", uids)
+	}
+
+	// the time range filter must leave out events on other days
+	if uids := query(time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC)); len(uids) != 1 || uids[0] != "blocked-1" {
+		t.Fatalf("query on the 12th = %v, want only the exception", uids)
+	}
+
+	object, err := client.GetCalendarObject(ctx, exceptionPath)
+	if err != nil {
+		t.Fatalf("GetCalendarObject() error = %v", err)
+	}
+
+	if summary, _ := object.Data.Events()[0].Props.Text(goical.PropSummary); summary != "Congresso" {
+		t.Errorf("summary = %q, want %q", summary, "Congresso")
+	}
+
+	if err := client.RemoveAll(ctx, exceptionPath); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+
+	if _, err := store.GetObject(ctx, adminID, "blocked.ics"); err == nil {
+		t.Fatal("exception still stored after DELETE")
+	}
+
+	if err := client.RemoveAll(ctx, calendarPath+"appointment.ics"); err == nil {
+		t.Error("deleting an appointment succeeded, want it refused")
+	}
+}
+
+func TestConditionalPut(t *testing.T) {
+	server, _, adminID := newTestServer(t)
+
+	url := server.URL + "/caldav/" + adminID.String() + "/calendars/" + CalendarName + "/blocked.ics"
+	body := encodeCalendar(t, newEvent("blocked-2", "Ferias", time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)))
+
+	put := func(header, value string) int {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.SetBasicAuth(testUser, testPassword)
+		req.Header.Set("Content-Type", goical.MIMEType)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	if status := put("If-None-Match", "*"); status != http.StatusCreated && status != http.StatusNoContent {
+		t.Fatalf("first PUT status = %d", status)
+	}
+
+	if status := put("If-None-Match", "*"); status != http.StatusPreconditionFailed {
+		t.Errorf("second PUT with If-None-Match status = %d, want %d", status, http.StatusPreconditionFailed)
+	}
+
+	if status := put("If-Match", `"stale"`); status != http.StatusPreconditionFailed {
+		t.Errorf("PUT with a stale If-Match status = %d, want %d", status, http.StatusPreconditionFailed)
+	}
+}
+
+func TestBadCredentials(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	if _, err := newClient(t, server, "wrong").FindCurrentUserPrincipal(context.Background()); err == nil {
+		t.Error("FindCurrentUserPrincipal() with a wrong password succeeded")
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		setAuth  bool
+	}{
+		{"wrong password", "wrong", true},
+		{"no credentials", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("PROPFIND", server.URL+"/caldav/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Depth", "0")
+			if tt.setAuth {
+				req.SetBasicAuth(testUser, tt.password)
+			}
+
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+
+			if resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,82 @@
+// Package certificate renders attendance certificates (declarações de
+// comparecimento) as PDF.
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Certificate holds everything printed on the certificate. VerifyURL is
+// where a third party confirms Code belongs to an authentic document, and
+// Signature is the HMAC the server computed over the certificate's contents.
+type Certificate struct {
+	Code        string
+	VerifyURL   string
+	IssuedAt    time.Time
+	IssuerName  string
+	IssuerCRP   string
+	PatientName string
+	Date        time.Time
+	StartTime   time.Time
+	EndTime     time.Time
+	Signature   string
+}
+
+// Filename is the name the certificate is downloaded as.
+func (c Certificate) Filename() string {
+	return "declaracao-comparecimento-" + c.Date.Format("2006-01-02") + ".pdf"
+}
+
+// PDF renders the certificate on an A4 page.
+func (c Certificate) PDF() ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(25, 25, 25)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetTitle("Declaração de comparecimento", true)
+	pdf.SetAuthor(c.IssuerName, true)
+	pdf.AddPage()
+
+	// the core fonts use cp1252, which covers Portuguese
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.Ln(15)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr("DECLARAÇÃO DE COMPARECIMENTO"), "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.MultiCell(0, 8, tr(fmt.Sprintf("Declaro, para os devidos fins, que %s compareceu a atendimento psicológico no dia %s, das %s às %s.",
+		c.PatientName, c.Date.Format("02/01/2006"), c.StartTime.Format("15:04"), c.EndTime.Format("15:04"))), "", "J", false)
+	pdf.Ln(12)
+
+	pdf.CellFormat(0, 8, tr("Emitido em "+c.IssuedAt.Format("02/01/2006")), "", 1, "L", false, 0, "")
+	pdf.Ln(30)
+
+	pdf.SetX(60)
+	pdf.CellFormat(90, 0, "", "T", 1, "C", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 6, tr(c.IssuerName), "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 5, tr("Psicólogo(a) - CRP "+c.IssuerCRP), "", 1, "C", false, 0, "")
+
+	// the verification block sits at the foot of the page
+	pdf.SetY(245)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.CellFormat(0, 5, tr("Código de verificação: "+c.Code), "T", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 5, tr("Confira a autenticidade deste documento em "+c.VerifyURL), "", 1, "C", false, 0, "")
+	pdf.SetFont("Courier", "", 7)
+	pdf.CellFormat(0, 5, tr("Assinatura: "+c.Signature), "", 1, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
\ No newline at end of file
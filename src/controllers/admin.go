@@ -251,4 +251,23 @@ func (controller *AdminController) DeleteCalendarSlot(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "slot deleted successfully"})
+}
+
+func (controller *AdminController) GetAvaliableSlots(c *gin.Context) {
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	date := c.Query("date")
+	if date == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is required"})
+		return
+	}
+
+	slots, err := controller.Service.GetAvaliableSlotsBySlug(ctx, c.Param("slug"), date, c.Query("location_id"), c.Query("modality"))
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
 }
\ No newline at end of file
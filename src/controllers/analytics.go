@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type AnalyticsController struct {
+	Service *services.AnalyticsService
+}
+
+func (controller *AnalyticsController) GetDashboard(c *gin.Context) {
+	adminIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	adminID, err := uuid.Parse(adminIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	dashboard, err := controller.Service.Dashboard(ctx, adminID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
\ No newline at end of file
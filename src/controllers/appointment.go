@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type AppointmentController struct {
+	Service *services.AppointmentService
+}
+
+var actionPages = map[string]struct {
+	Title   string
+	Prompt  string
+	Button  string
+	Success string
+}{
+	utils.ActionConfirm: {
+		Title:   "Confirmar presença",
+		Prompt:  "Clique no botão abaixo para confirmar sua presença no atendimento.",
+		Button:  "Confirmar presença",
+		Success: "Sua presença foi confirmada. Até breve!",
+	},
+	utils.ActionCancel: {
+		Title:   "Cancelar atendimento",
+		Prompt:  "Clique no botão abaixo para cancelar o seu atendimento.",
+		Button:  "Cancelar atendimento",
+		Success: "Seu atendimento foi cancelado e o psicólogo foi avisado.",
+	},
+}
+
+func (controller *AppointmentController) ShowPatientAction(c *gin.Context) {
+	page, ok := actionPages[c.Param("action")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "page not found"})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", utils.RenderActionPage(page.Title, page.Prompt, c.Request.URL.RequestURI(), page.Button))
+}
+
+func (controller *AppointmentController) RunPatientAction(c *gin.Context) {
+	action := c.Param("action")
+
+	page, ok := actionPages[action]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "page not found"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	_, err := controller.Service.RunPatientAction(ctx, c.Query("token"), action)
+	if err != nil {
+		c.Data(utils.GetStatusCode(err), "text/html; charset=utf-8", utils.RenderActionPage(page.Title, err.Error(), "", ""))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", utils.RenderActionPage(page.Title, page.Success, "", ""))
+}
+
+func (controller *AppointmentController) UpdateStatus(c *gin.Context) {
+	var input dtos.AppointmentStatusInput
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	appointmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment id"})
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	adminIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	adminID, err := uuid.Parse(adminIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	err = controller.Service.UpdateStatus(ctx, appointmentID, adminID, input)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "appointment status updated"})
+}
+
+func (controller *AppointmentController) WaiveFee(c *gin.Context) {
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	appointmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment id"})
+		return
+	}
+
+	adminIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	adminID, err := uuid.Parse(adminIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	err = controller.Service.WaiveFee(ctx, appointmentID, adminID)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "appointment fee waived"})
+}
+
+func (controller *AppointmentController) Reschedule(c *gin.Context) {
+	var input dtos.RescheduleInput
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	appointmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment id"})
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	adminIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	adminID, err := uuid.Parse(adminIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	err = controller.Service.Reschedule(ctx, appointmentID, adminID, input)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "appointment rescheduled"})
+}
+
+func (controller *AppointmentController) GetCancellationSettings(c *gin.Context) {
+	adminIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	adminID, err := uuid.Parse(adminIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	settings, err := controller.Service.GetCancellationSettings(ctx, adminID)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (controller *AppointmentController) UpdateCancellationSettings(c *gin.Context) {
+	var input dtos.CancellationSettingsInput
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	adminIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	adminID, err := uuid.Parse(adminIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	settings, err := controller.Service.UpdateCancellationSettings(ctx, input, adminID)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type CertificateController struct {
+	Service *services.CertificateService
+}
+
+func (controller *CertificateController) GetPDF(c *gin.Context) {
+	adminIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	adminID, err := uuid.Parse(adminIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	appointmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment id"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	pdf, filename, err := controller.Service.PDF(ctx, appointmentID, adminID, uuid.Nil)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func (controller *CertificateController) GetPatientPDF(c *gin.Context) {
+	patientIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid patient id"})
+		return
+	}
+
+	patientID, err := uuid.Parse(patientIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid patient id"})
+		return
+	}
+
+	appointmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment id"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	pdf, filename, err := controller.Service.PDF(ctx, appointmentID, uuid.Nil, patientID)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// Verify is the public page a third party opens to check a certificate. It
+// shows what the certificate states and its signature, so they can be
+// compared with the copy in hand.
+func (controller *CertificateController) Verify(c *gin.Context) {
+	const title = "Verificação de declaração"
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	certificate, err := controller.Service.Verify(ctx, c.Param("code"))
+	if utils.GetStatusCode(err) == http.StatusNotFound {
+		c.Data(http.StatusNotFound, "text/html; charset=utf-8", utils.RenderActionPage(title, "Nenhuma declaração foi encontrada com este código de verificação.", "", ""))
+		return
+	}
+	if utils.GetStatusCode(err) == http.StatusConflict {
+		c.Data(http.StatusConflict, "text/html; charset=utf-8", utils.RenderActionPage(title, "Os dados desta declaração não conferem com a assinatura com que foi emitida. Não a considere autêntica.", "", ""))
+		return
+	}
+	if err != nil {
+		c.Data(utils.GetStatusCode(err), "text/html; charset=utf-8", utils.RenderActionPage(title, err.Error(), "", ""))
+		return
+	}
+
+	message := fmt.Sprintf("Declaração autêntica (código %s): %s compareceu a atendimento psicológico no dia %s, das %s às %s, com %s, CRP %s. Emitida em %s. Assinatura: %s.",
+		certificate.Code,
+		certificate.PatientName,
+		certificate.Date.Format("02/01/2006"),
+		certificate.StartTime.Format("15:04"),
+		certificate.EndTime.Format("15:04"),
+		certificate.IssuerName,
+		certificate.IssuerCRP,
+		certificate.CreatedAt.In(utils.AppLocation()).Format("02/01/2006"),
+		certificate.Signature,
+	)
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", utils.RenderActionPage(title, message, "", ""))
+}
\ No newline at end of file
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type WaitlistController struct {
+	Service *services.WaitlistService
+}
+
+var offerPages = map[string]struct {
+	Title   string
+	Prompt  string
+	Button  string
+	Success string
+}{
+	utils.ActionClaim: {
+		Title:   "Reservar horário",
+		Prompt:  "Clique no botão abaixo para ficar com este horário.",
+		Button:  "Quero este horário",
+		Success: "Horário reservado! Você vai receber a confirmação do agendamento.",
+	},
+	utils.ActionDecline: {
+		Title:   "Liberar horário",
+		Prompt:  "Clique no botão abaixo para liberar o horário. Você continua na lista de espera.",
+		Button:  "Não tenho interesse",
+		Success: "Horário liberado. Você continua na lista de espera.",
+	},
+}
+
+func (controller *WaitlistController) Join(c *gin.Context) {
+	var input dtos.WaitlistInput
+
+	patientIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid patient id"})
+		return
+	}
+
+	patientID, err := uuid.Parse(patientIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid patient id"})
+		return
+	}
+
+	err = c.ShouldBindJSON(&input)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	entry, err := controller.Service.Join(ctx, patientID, input)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (controller *WaitlistController) GetEntry(c *gin.Context) {
+	patientIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid patient id"})
+		return
+	}
+
+	patientID, err := uuid.Parse(patientIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid patient id"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	entry, err := controller.Service.GetEntry(ctx, patientID)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (controller *WaitlistController) Leave(c *gin.Context) {
+	patientIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid patient id"})
+		return
+	}
+
+	patientID, err := uuid.Parse(patientIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid patient id"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	err = controller.Service.Leave(ctx, patientID)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "you left the waitlist"})
+}
+
+func (controller *WaitlistController) GetEntries(c *gin.Context) {
+	adminIDStr, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	adminID, err := uuid.Parse(adminIDStr.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	entries, err := controller.Service.GetEntries(ctx, adminID)
+	if err != nil {
+		c.JSON(utils.GetStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func (controller *WaitlistController) ShowOfferAction(c *gin.Context) {
+	page, ok := offerPages[c.Param("action")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "page not found"})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", utils.RenderActionPage(page.Title, page.Prompt, c.Request.URL.RequestURI(), page.Button))
+}
+
+func (controller *WaitlistController) RunOfferAction(c *gin.Context) {
+	action := c.Param("action")
+
+	page, ok := offerPages[action]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "page not found"})
+		return
+	}
+
+	ctx, cancel := utils.NewDBContext()
+	defer cancel()
+
+	var err error
+	if action == utils.ActionClaim {
+		err = controller.Service.ClaimOffer(ctx, c.Query("token"))
+	} else {
+		err = controller.Service.DeclineOffer(ctx, c.Query("token"))
+	}
+
+	if err != nil {
+		c.Data(utils.GetStatusCode(err), "text/html; charset=utf-8", utils.RenderActionPage(page.Title, err.Error(), "", ""))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", utils.RenderActionPage(page.Title, page.Success, "", ""))
+}
\ No newline at end of file
@@ -0,0 +1,50 @@
+package dtos
+
+// AgendaMetrics measures how the agenda was used. Occupancy is booked minutes
+// (appointments not cancelled) over the minutes offered by calendar slots.
+// The no-show rate is over sessions that were due, completed or missed.
+// Rates go from 0 to 1.
+type AgendaMetrics struct {
+	AvailableMinutes int64   `json:"available_minutes"`
+	BookedMinutes    int64   `json:"booked_minutes"`
+	OccupancyRate    float64 `json:"occupancy_rate"`
+	Appointments     int     `json:"appointments"`
+	Completed        int     `json:"completed"`
+	Cancelled        int     `json:"cancelled"`
+	NoShows          int     `json:"no_shows"`
+	CancellationRate float64 `json:"cancellation_rate"`
+	NoShowRate       float64 `json:"no_show_rate"`
+}
+
+// AnalyticsPeriodOutput is one month of the dashboard. Active patients had a
+// completed session in the month; new ones had their first ever. Churned
+// patients had their last session in the month, nothing booked after it, and
+// have not been seen for a while.
+type AnalyticsPeriodOutput struct {
+	Month string `json:"month"`
+	AgendaMetrics
+	ActivePatients    int     `json:"active_patients"`
+	NewPatients       int     `json:"new_patients"`
+	ReturningPatients int     `json:"returning_patients"`
+	ChurnedPatients   int     `json:"churned_patients"`
+	ChurnRate         float64 `json:"churn_rate"`
+}
+
+// AnalyticsTotalsOutput sums up the whole range. Treatment length covers the
+// patients seen in the range, from their first to their last completed
+// session.
+type AnalyticsTotalsOutput struct {
+	AgendaMetrics
+	TreatedPatients      int     `json:"treated_patients"`
+	NewPatients          int     `json:"new_patients"`
+	ChurnedPatients      int     `json:"churned_patients"`
+	AverageTreatmentDays float64 `json:"average_treatment_days"`
+	AverageSessions      float64 `json:"average_sessions"`
+}
+
+type AnalyticsOutput struct {
+	From    string                  `json:"from"`
+	To      string                  `json:"to"`
+	Totals  AnalyticsTotalsOutput   `json:"totals"`
+	Periods []AnalyticsPeriodOutput `json:"periods"`
+}
\ No newline at end of file
@@ -1,20 +1,119 @@
 package dtos
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type AppointmentInput struct {
-	PatientID string `json:"patient_id" binding:"required"`
-	Date      string `json:"date" binding:"required"`
-	StartTime string `json:"start_time" binding:"required"`
-	EndTime   string `json:"end_time" binding:"required"`
+	PatientID  string `json:"patient_id" binding:"required"`
+	Date       string `json:"date" binding:"required"`
+	StartTime  string `json:"start_time" binding:"required"`
+	EndTime    string `json:"end_time" binding:"required"`
+	LocationID string `json:"location_id"`
+	Modality   string `json:"modality"`
+	VideoURL   string `json:"video_url"`
 }
 
 type AppointmentOutput struct {
-	ID 			uuid.UUID `json:"id"`
-	PatientID 	uuid.UUID `json:"patient_id"`
-	FullName    string    `json:"full_name"`
-	Date 		string    `json:"date"`
-	StartTime 	string    `json:"start_time"`
-	EndTime 	string    `json:"end_time"`
-	Status 		string    `json:"status"`
+	ID 			uuid.UUID  `json:"id"`
+	PatientID 	uuid.UUID  `json:"patient_id"`
+	FullName    string     `json:"full_name"`
+	Date 		string     `json:"date"`
+	StartTime 	string     `json:"start_time"`
+	EndTime 	string     `json:"end_time"`
+	Status 		string     `json:"status"`
+	LocationID  *uuid.UUID `json:"location_id"`
+	Modality    string     `json:"modality"`
+	VideoURL    string     `json:"video_url,omitempty"`
+	LateCancelled bool     `json:"late_cancelled,omitempty"`
+	FeeCents    int64      `json:"fee_cents,omitempty"`
+	FeeWaived   bool       `json:"fee_waived,omitempty"`
+	AuthorizationID *uuid.UUID `json:"authorization_id,omitempty"`
+}
+
+type PatientAppointmentOutput struct {
+	ID 			 uuid.UUID `json:"id"`
+	Date 		 string    `json:"date"`
+	StartTime 	 string    `json:"start_time"`
+	EndTime 	 string    `json:"end_time"`
+	Status 		 string    `json:"status"`
+	Modality     string    `json:"modality"`
+	LocationName string    `json:"location_name,omitempty"`
+	Address      string    `json:"address,omitempty"`
+	VideoURL     string    `json:"video_url,omitempty"`
+}
+
+const (
+	StatusScheduled = "scheduled"
+	StatusConfirmed = "confirmed"
+	StatusCancelled = "cancelled"
+	StatusCompleted = "completed"
+	StatusNoShow    = "no_show"
+)
+
+// AppointmentStatusInput changes an appointment's status. CancelledBy records
+// who cancelled: "patient" when the psychologist enters a cancellation the
+// patient asked for, "admin" (the default) otherwise. WaiveFee skips the
+// late-cancel or no-show fee.
+type AppointmentStatusInput struct {
+	Status      string `json:"status" binding:"required"`
+	CancelledBy string `json:"cancelled_by"`
+	WaiveFee    bool   `json:"waive_fee"`
+}
+
+// CancellationSettingsInput sets the cancellation policy. A null fee charges
+// the session price; Policy replaces the generated text shown to patients.
+type CancellationSettingsInput struct {
+	MinNoticeHours     *int   `json:"min_notice_hours" binding:"required"`
+	LateCancelFeeCents *int64 `json:"late_cancel_fee_cents"`
+	NoShowFeeCents     *int64 `json:"no_show_fee_cents"`
+	Policy             string `json:"policy"`
+}
+
+// CancellationSettingsOutput includes PolicyText, the policy as patients see
+// it in confirmation emails.
+type CancellationSettingsOutput struct {
+	MinNoticeHours     int    `json:"min_notice_hours"`
+	LateCancelFeeCents *int64 `json:"late_cancel_fee_cents"`
+	NoShowFeeCents     *int64 `json:"no_show_fee_cents"`
+	Policy             string `json:"policy"`
+	PolicyText         string `json:"policy_text"`
+	SessionPriceCents  int64  `json:"-"`
+}
+
+type AppointmentDetails struct {
+	ID              uuid.UUID
+	ClientID        uuid.UUID
+	PatientID       uuid.UUID
+	PatientName     string
+	PatientEmail    string
+	PatientPhone    string
+	PatientChannels []string
+	AdminName       string
+	AdminEmail      string
+	Date            time.Time
+	StartTime       time.Time
+	EndTime         time.Time
+	Status          string
+	Modality        string
+	VideoURL        string
+	Address         string
+	MinNoticeHours  int
+	Sequence        int
+	LateCancelFeeCents *int64
+	NoShowFeeCents  *int64
+	SessionPriceCents int64
+	Policy          string
+	LateCancelled   bool
+	FeeCents        int64
+	FeeWaived       bool
+	AuthorizationID *uuid.UUID
+}
+
+type RescheduleInput struct {
+	Date      string `json:"date" binding:"required"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
 }
\ No newline at end of file
@@ -0,0 +1,69 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EntryCharge   = "charge"
+	EntryPayment  = "payment"
+	EntryDiscount = "discount"
+	EntryRefund   = "refund"
+)
+
+// Amounts are in cents (BRL).
+type PricingInput struct {
+	SessionPriceCents *int64 `json:"session_price_cents" binding:"required"`
+}
+
+type PricingOutput struct {
+	SessionPriceCents int64 `json:"session_price_cents"`
+}
+
+// PatientPriceInput sets a custom price for one patient, e.g. a sliding
+// scale. A null price makes the patient pay the default price again.
+type PatientPriceInput struct {
+	SessionPriceCents *int64 `json:"session_price_cents"`
+}
+
+type PatientPriceOutput struct {
+	CustomPriceCents  *int64 `json:"custom_price_cents"`
+	DefaultPriceCents int64  `json:"default_price_cents"`
+	SessionPriceCents int64  `json:"session_price_cents"`
+}
+
+type LedgerEntryInput struct {
+	Kind        string `json:"kind" binding:"required"`
+	AmountCents int64  `json:"amount_cents" binding:"required"`
+	Description string `json:"description"`
+	Method      string `json:"method"`
+}
+
+type LedgerEntryOutput struct {
+	ID            uuid.UUID  `json:"id"`
+	AppointmentID *uuid.UUID `json:"appointment_id,omitempty"`
+	PaymentID     *uuid.UUID `json:"payment_id,omitempty"`
+	Kind          string     `json:"kind"`
+	AmountCents   int64      `json:"amount_cents"`
+	Description   string     `json:"description"`
+	Method        string     `json:"method,omitempty"`
+	BalanceCents  int64      `json:"balance_cents"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// LedgerOutput is the patient's account. A positive balance is what the
+// patient owes, a negative one is credit in their favour.
+type LedgerOutput struct {
+	PatientID    uuid.UUID           `json:"patient_id"`
+	BalanceCents int64               `json:"balance_cents"`
+	Entries      []LedgerEntryOutput `json:"entries"`
+}
+
+type PatientBalanceOutput struct {
+	PatientID    uuid.UUID `json:"patient_id"`
+	PatientName  string    `json:"patient_name"`
+	BalanceCents int64     `json:"balance_cents"`
+	LastEntryAt  time.Time `json:"last_entry_at"`
+}
\ No newline at end of file
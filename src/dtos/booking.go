@@ -0,0 +1,103 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BookingInstant  = "instant"
+	BookingApproval = "approval"
+)
+
+const (
+	RequestPending   = "pending"
+	RequestAccepted  = "accepted"
+	RequestDeclined  = "declined"
+	RequestExpired   = "expired"
+	RequestWithdrawn = "withdrawn"
+)
+
+type BookingSettingsInput struct {
+	Mode      string `json:"mode" binding:"required"`
+	HoldHours *int   `json:"hold_hours" binding:"required"`
+}
+
+type BookingSettingsOutput struct {
+	Mode      string `json:"mode"`
+	HoldHours int    `json:"hold_hours"`
+}
+
+// BookingRulesInput limits what patients can book. Caps set to 0 are not
+// enforced.
+type BookingRulesInput struct {
+	MinNoticeHours    *int `json:"min_notice_hours" binding:"required"`
+	MaxHorizonDays    *int `json:"max_horizon_days" binding:"required"`
+	MaxPerDay         *int `json:"max_per_day" binding:"required"`
+	MaxPerWeek        *int `json:"max_per_week" binding:"required"`
+	MaxOpenPerPatient *int `json:"max_open_per_patient" binding:"required"`
+}
+
+type BookingRulesOutput struct {
+	MinNoticeHours    int `json:"min_notice_hours"`
+	MaxHorizonDays    int `json:"max_horizon_days"`
+	MaxPerDay         int `json:"max_per_day"`
+	MaxPerWeek        int `json:"max_per_week"`
+	MaxOpenPerPatient int `json:"max_open_per_patient"`
+}
+
+// BookingInput is a patient booking one of the available slots of their
+// psychologist.
+type BookingInput struct {
+	Date       string `json:"date" binding:"required"`
+	StartTime  string `json:"start_time" binding:"required"`
+	EndTime    string `json:"end_time" binding:"required"`
+	LocationID string `json:"location_id"`
+	Modality   string `json:"modality"`
+}
+
+// BookingOutput tells the patient whether the session was booked or is
+// waiting for the psychologist's approval.
+type BookingOutput struct {
+	Status        string     `json:"status"`
+	AppointmentID *uuid.UUID `json:"appointment_id,omitempty"`
+	RequestID     *uuid.UUID `json:"request_id,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+type BookingDeclineInput struct {
+	Reason string `json:"reason"`
+}
+
+type BookingRequestOutput struct {
+	ID            uuid.UUID  `json:"id"`
+	PatientID     uuid.UUID  `json:"patient_id"`
+	PatientName   string     `json:"patient_name"`
+	Date          string     `json:"date"`
+	StartTime     string     `json:"start_time"`
+	EndTime       string     `json:"end_time"`
+	LocationID    *uuid.UUID `json:"location_id"`
+	Modality      string     `json:"modality"`
+	Status        string     `json:"status"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	DeclineReason string     `json:"decline_reason,omitempty"`
+	AppointmentID *uuid.UUID `json:"appointment_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type BookingRequest struct {
+	ID         uuid.UUID
+	ClientID   uuid.UUID
+	PatientID  uuid.UUID
+	Patient    PatientContact
+	AdminEmail string
+	Date       time.Time
+	StartTime  time.Time
+	EndTime    time.Time
+	LocationID uuid.NullUUID
+	Modality   string
+	Address    string
+	Status     string
+	ExpiresAt  time.Time
+}
\ No newline at end of file
@@ -0,0 +1,39 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalDAVAccountOutput tells the psychologist how to set up a calendar app.
+// Password is only returned right after it is generated.
+type CalDAVAccountOutput struct {
+	Enabled     bool   `json:"enabled"`
+	ServerURL   string `json:"server_url"`
+	CalendarURL string `json:"calendar_url"`
+	Username    string `json:"username"`
+	Password    string `json:"password,omitempty"`
+}
+
+// CalendarObject is one .ics resource of the CalDAV collection.
+type CalendarObject struct {
+	Name    string
+	ETag    string
+	ModTime time.Time
+	Data    []byte
+}
+
+type AvailabilityException struct {
+	ID        uuid.UUID
+	Name      string
+	UID       string
+	Summary   string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Recurring bool
+	Busy      bool
+	Data      string
+	ETag      string
+	UpdatedAt time.Time
+}
\ No newline at end of file
@@ -0,0 +1,36 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeedOutput describes the admin's subscription feed. URL is only
+// returned right after the token is generated, as only its hash is stored.
+type CalendarFeedOutput struct {
+	Enabled   bool       `json:"enabled"`
+	URL       string     `json:"url,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type CalendarFeedVersion struct {
+	ClientID     uuid.UUID
+	ClientName   string
+	ETag         string
+	LastModified time.Time
+}
+
+type FeedAppointment struct {
+	ID          uuid.UUID
+	PatientName string
+	Date        time.Time
+	StartTime   time.Time
+	EndTime     time.Time
+	Status      string
+	Modality    string
+	VideoURL    string
+	Address     string
+	Sequence    int
+	UpdatedAt   time.Time
+}
\ No newline at end of file
@@ -7,16 +7,20 @@ import (
 )
 
 type CalendarSlotsInput struct {
-	Weekday   int    `json:"weekday" binding:"required"`
-	StartTime string `json:"start_time" binding:"required"`
-	EndTime   string `json:"end_time" binding:"required"`
+	Weekday    int    `json:"weekday" binding:"required"`
+	StartTime  string `json:"start_time" binding:"required"`
+	EndTime    string `json:"end_time" binding:"required"`
+	LocationID string `json:"location_id"`
+	Modality   string `json:"modality"`
 }
 
 type CalendarSlotsOutput struct {
-	ID 			uuid.UUID `json:"id"`
-	Weekday 	string 	  `json:"weekday"`
-	StartTime 	string 	  `json:"start_time"`
-	EndTime 	string 	  `json:"end_time"`
+	ID 			uuid.UUID  `json:"id"`
+	Weekday 	string 	   `json:"weekday"`
+	StartTime 	string 	   `json:"start_time"`
+	EndTime 	string 	   `json:"end_time"`
+	LocationID  *uuid.UUID `json:"location_id"`
+	Modality    string     `json:"modality"`
 }
 
 type CalendarSlotDB struct {
@@ -25,4 +29,13 @@ type CalendarSlotDB struct {
 	Weekday 	int
 	StartTime 	time.Time
 	EndTime 	time.Time
-}
\ No newline at end of file
+	LocationID  uuid.NullUUID
+	Modality    string
+}
+
+type AvailableSlotOutput struct {
+	StartTime  string     `json:"start_time"`
+	EndTime    string     `json:"end_time"`
+	LocationID *uuid.UUID `json:"location_id"`
+	Modality   string     `json:"modality"`
+}
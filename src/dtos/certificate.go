@@ -0,0 +1,24 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Certificate is an attendance certificate as it was issued.
+type Certificate struct {
+	ID            uuid.UUID
+	ClientID      uuid.UUID
+	PatientID     uuid.UUID
+	AppointmentID uuid.UUID
+	Code          string
+	IssuerName    string
+	IssuerCRP     string
+	PatientName   string
+	Date          time.Time
+	StartTime     time.Time
+	EndTime       time.Time
+	Signature     string
+	CreatedAt     time.Time
+}
\ No newline at end of file
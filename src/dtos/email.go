@@ -0,0 +1,35 @@
+package dtos
+
+type EmailBrandingInput struct {
+	ClinicName   string `json:"clinic_name"`
+	PrimaryColor string `json:"primary_color"`
+	LogoURL      string `json:"logo_url"`
+	Footer       string `json:"footer"`
+}
+
+type EmailBrandingOutput struct {
+	ClinicName   string `json:"clinic_name"`
+	PrimaryColor string `json:"primary_color"`
+	LogoURL      string `json:"logo_url"`
+	Footer       string `json:"footer"`
+}
+
+type EmailBrandingDB struct {
+	FullName        string
+	ProfileImageURL string
+	EmailBrandingOutput
+}
+
+type EmailTemplateInput struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+type EmailTemplateOutput struct {
+	Name       string `json:"name"`
+	Overridden bool   `json:"overridden"`
+	Subject    string `json:"subject,omitempty"`
+	HTML       string `json:"html,omitempty"`
+	Text       string `json:"text,omitempty"`
+}
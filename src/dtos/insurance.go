@@ -0,0 +1,116 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InsurancePlanInput describes a health insurance plan (convênio).
+// SessionPriceCents is what the plan pays for each session.
+type InsurancePlanInput struct {
+	Name              string `json:"name" binding:"required"`
+	ANSCode           string `json:"ans_code"`
+	SessionPriceCents *int64 `json:"session_price_cents" binding:"required"`
+	Active            *bool  `json:"active"`
+}
+
+type InsurancePlanOutput struct {
+	ID                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	ANSCode           string    `json:"ans_code"`
+	SessionPriceCents int64     `json:"session_price_cents"`
+	Active            bool      `json:"active"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// CoverageInput puts a patient on a plan. ValidUntil ("2006-01-02") is when
+// the card expires, if it does.
+type CoverageInput struct {
+	PlanID     string `json:"plan_id" binding:"required"`
+	CardNumber string `json:"card_number" binding:"required"`
+	ValidUntil string `json:"valid_until"`
+}
+
+type CoverageOutput struct {
+	ID         uuid.UUID `json:"id"`
+	PatientID  uuid.UUID `json:"patient_id"`
+	PlanID     uuid.UUID `json:"plan_id"`
+	PlanName   string    `json:"plan_name"`
+	CardNumber string    `json:"card_number"`
+	ValidUntil *string   `json:"valid_until"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuthorizationInput records an authorization guide issued by the plan.
+type AuthorizationInput struct {
+	Number     string `json:"number" binding:"required"`
+	Sessions   int    `json:"sessions" binding:"required"`
+	ValidFrom  string `json:"valid_from" binding:"required"`
+	ValidUntil string `json:"valid_until" binding:"required"`
+}
+
+// AuthorizationOutput is a guide and how much of it is taken. Used counts the
+// appointments under the guide that were not cancelled or missed; Exceeded
+// is set when there are more of them than the guide allows.
+type AuthorizationOutput struct {
+	ID         uuid.UUID `json:"id"`
+	CoverageID uuid.UUID `json:"coverage_id"`
+	Number     string    `json:"number"`
+	Sessions   int       `json:"sessions"`
+	Used       int       `json:"used"`
+	Remaining  int       `json:"remaining"`
+	Exceeded   bool      `json:"exceeded"`
+	ValidFrom  string    `json:"valid_from"`
+	ValidUntil string    `json:"valid_until"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Authorization is a guide along with the coverage it was issued for.
+type Authorization struct {
+	AuthorizationOutput
+	PatientID      uuid.UUID
+	CardValidUntil *string
+}
+
+// AppointmentAuthorizationInput puts an appointment under a guide. A null
+// authorization_id takes it out again.
+type AppointmentAuthorizationInput struct {
+	AuthorizationID *string `json:"authorization_id"`
+}
+
+// AppointmentAuthorizationOutput is the guide the appointment is now under,
+// with warnings when the appointment goes beyond what it authorizes.
+type AppointmentAuthorizationOutput struct {
+	AppointmentID uuid.UUID            `json:"appointment_id"`
+	Authorization *AuthorizationOutput `json:"authorization"`
+	Warnings      []string             `json:"warnings"`
+}
+
+type InsuranceBatchInput struct {
+	Month string `json:"month" binding:"required"`
+}
+
+type InsuranceBatchItem struct {
+	AppointmentID       *uuid.UUID `json:"appointment_id"`
+	PatientName         string     `json:"patient_name"`
+	CardNumber          string     `json:"card_number"`
+	AuthorizationNumber string     `json:"authorization_number"`
+	Date                string     `json:"date"`
+	StartTime           string     `json:"start_time"`
+	EndTime             string     `json:"end_time"`
+	AmountCents         int64      `json:"amount_cents"`
+}
+
+// InsuranceBatchOutput is a billing batch (lote) for one plan and month.
+// Items are only filled in when a single batch is asked for.
+type InsuranceBatchOutput struct {
+	ID         uuid.UUID            `json:"id"`
+	PlanID     uuid.UUID            `json:"plan_id"`
+	PlanName   string               `json:"plan_name"`
+	Month      string               `json:"month"`
+	Sessions   int                  `json:"sessions"`
+	TotalCents int64                `json:"total_cents"`
+	Items      []InsuranceBatchItem `json:"items,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
\ No newline at end of file
@@ -0,0 +1,19 @@
+package dtos
+
+import "github.com/google/uuid"
+
+const (
+	ModalityInPerson = "in_person"
+	ModalityOnline   = "online"
+)
+
+type LocationInput struct {
+	Name    string `json:"name" binding:"required"`
+	Address string `json:"address" binding:"required"`
+}
+
+type LocationOutput struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Address string    `json:"address"`
+}
@@ -0,0 +1,35 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OutboxPending = "pending"
+	OutboxSending = "sending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+)
+
+type OutboxOutput struct {
+	ID            uuid.UUID  `json:"id"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+type OutboxMessage struct {
+	ID          uuid.UUID
+	Channel     string
+	Payload     []byte
+	Attempts    int
+	MaxAttempts int
+}
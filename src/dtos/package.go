@@ -0,0 +1,64 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PackageInput describes a package of prepaid sessions. Without validity_days
+// the credits never expire.
+type PackageInput struct {
+	Name         string `json:"name" binding:"required"`
+	Sessions     int    `json:"sessions" binding:"required"`
+	PriceCents   *int64 `json:"price_cents" binding:"required"`
+	ValidityDays *int   `json:"validity_days"`
+	Active       *bool  `json:"active"`
+}
+
+type PackageOutput struct {
+	ID                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	Sessions          int       `json:"sessions"`
+	PriceCents        int64     `json:"price_cents"`
+	SessionPriceCents int64     `json:"session_price_cents"`
+	ValidityDays      *int      `json:"validity_days"`
+	Active            bool      `json:"active"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type PackagePurchaseInput struct {
+	PackageID string `json:"package_id" binding:"required"`
+}
+
+// PatientPackageOutput is a package bought by a patient. Reserved credits are
+// held by upcoming appointments and return to the package if those are
+// cancelled in time.
+type PatientPackageOutput struct {
+	ID         uuid.UUID  `json:"id"`
+	PackageID  *uuid.UUID `json:"package_id,omitempty"`
+	Name       string     `json:"name"`
+	Sessions   int        `json:"sessions"`
+	Used       int        `json:"used"`
+	Reserved   int        `json:"reserved"`
+	Remaining  int        `json:"remaining"`
+	PriceCents int64      `json:"price_cents"`
+	ExpiresOn  *string    `json:"expires_on"`
+	Expired    bool       `json:"expired"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreditsOutput sums up the patient's packages. Remaining only counts
+// packages that have not expired.
+type CreditsOutput struct {
+	PatientID uuid.UUID              `json:"patient_id"`
+	Remaining int                    `json:"remaining"`
+	Packages  []PatientPackageOutput `json:"packages"`
+}
+
+// PatientPackage is a purchase along with who it belongs to.
+type PatientPackage struct {
+	PatientPackageOutput
+	ClientID  uuid.UUID
+	PatientID uuid.UUID
+}
\ No newline at end of file
@@ -24,4 +24,23 @@ type PatientOutput struct {
 	Email 		string    `json:"email"`
 	Phone 		string    `json:"phone"`
 	BirthDate 	string    `json:"birth_date"`
+}
+
+type NotificationPreferencesInput struct {
+	Channels []string `json:"channels" binding:"required"`
+}
+
+type NotificationPreferencesOutput struct {
+	Channels  []string `json:"channels"`
+	Available []string `json:"available"`
+}
+
+// PatientContact is where and how a patient wants to be notified.
+type PatientContact struct {
+	Name     string
+	Email    string
+	Phone    string
+	CPF      string
+	PayerCPF string
+	Channels []string
 }
\ No newline at end of file
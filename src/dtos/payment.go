@@ -0,0 +1,54 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentInput charges a patient by card or boleto. Amount and description
+// default as in PixChargeInput. CardToken comes from the gateway's
+// client-side library. Card payments are captured right away unless Capture
+// is false.
+type PaymentInput struct {
+	Method      string `json:"method" binding:"required"`
+	AmountCents *int64 `json:"amount_cents"`
+	EntryID     string `json:"entry_id"`
+	Description string `json:"description"`
+	CardToken   string `json:"card_token"`
+	Capture     *bool  `json:"capture"`
+	DueDate     string `json:"due_date"`
+}
+
+// PaymentAmountInput captures or refunds part of a payment. Without an amount
+// the whole remaining amount is used.
+type PaymentAmountInput struct {
+	AmountCents int64 `json:"amount_cents"`
+}
+
+type PaymentOutput struct {
+	ID            uuid.UUID  `json:"id"`
+	PatientID     uuid.UUID  `json:"patient_id"`
+	PatientName   string     `json:"patient_name"`
+	ChargeEntryID *uuid.UUID `json:"entry_id,omitempty"`
+	Method        string     `json:"method"`
+	Status        string     `json:"status"`
+	AmountCents   int64      `json:"amount_cents"`
+	CapturedCents int64      `json:"captured_cents"`
+	RefundedCents int64      `json:"refunded_cents"`
+	Description   string     `json:"description"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	BoletoURL     string     `json:"boleto_url,omitempty"`
+	BoletoLine    string     `json:"boleto_line,omitempty"`
+	DueDate       *string    `json:"due_date,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Payment is a payment along with where it lives at the gateway.
+type Payment struct {
+	PaymentOutput
+	ClientID        uuid.UUID
+	Gateway         string
+	GatewayChargeID string
+}
\ No newline at end of file
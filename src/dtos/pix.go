@@ -0,0 +1,55 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PixPending   = "pending"
+	PixPaid      = "paid"
+	PixCancelled = "cancelled"
+)
+
+type PixSettingsInput struct {
+	Key          string `json:"key" binding:"required"`
+	MerchantName string `json:"merchant_name" binding:"required"`
+	MerchantCity string `json:"merchant_city" binding:"required"`
+}
+
+type PixSettingsOutput struct {
+	Key          string `json:"key"`
+	KeyType      string `json:"key_type,omitempty"`
+	MerchantName string `json:"merchant_name"`
+	MerchantCity string `json:"merchant_city"`
+}
+
+// PixChargeInput asks a patient for a payment. Without an amount the patient
+// is charged the ledger charge in EntryID or, failing that, their balance.
+type PixChargeInput struct {
+	AmountCents *int64 `json:"amount_cents"`
+	EntryID     string `json:"entry_id"`
+	Description string `json:"description"`
+	SendEmail   bool   `json:"send_email"`
+}
+
+type PixChargeOutput struct {
+	ID            uuid.UUID  `json:"id"`
+	PatientID     uuid.UUID  `json:"patient_id"`
+	PatientName   string     `json:"patient_name"`
+	ChargeEntryID *uuid.UUID `json:"entry_id,omitempty"`
+	TxID          string     `json:"txid"`
+	AmountCents   int64      `json:"amount_cents"`
+	Description   string     `json:"description"`
+	BRCode        string     `json:"br_code"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+}
+
+// PixCharge is a charge along with the psychologist it belongs to.
+type PixCharge struct {
+	PixChargeOutput
+	ClientID uuid.UUID
+}
\ No newline at end of file
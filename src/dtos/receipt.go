@@ -0,0 +1,54 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReceiptInput issues a receipt for one payment (EntryID, a payment on the
+// ledger) or for what was paid in one month that has ended (Month, as
+// "2006-01").
+type ReceiptInput struct {
+	EntryID string `json:"entry_id"`
+	Month   string `json:"month"`
+}
+
+type CPFInput struct {
+	CPF string `json:"cpf" binding:"required"`
+}
+
+// ReceiptSettingsOutput is the psychologist's data printed on receipts.
+type ReceiptSettingsOutput struct {
+	FullName      string `json:"full_name"`
+	Crp           string `json:"crp"`
+	CPF           string `json:"cpf"`
+	OfficeAddress string `json:"office_address"`
+}
+
+type ReceiptItem struct {
+	Date        string `json:"date"`
+	Description string `json:"description"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+type ReceiptOutput struct {
+	ID          uuid.UUID     `json:"id"`
+	Number      int           `json:"number"`
+	PatientID   uuid.UUID     `json:"patient_id"`
+	PatientName string        `json:"patient_name"`
+	EntryID     *uuid.UUID    `json:"entry_id,omitempty"`
+	Month       *string       `json:"month,omitempty"`
+	Reference   string        `json:"reference"`
+	Items       []ReceiptItem `json:"items"`
+	TotalCents  int64         `json:"total_cents"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// Receipt is a receipt with the parties as they were when it was issued.
+type Receipt struct {
+	ReceiptOutput
+	ClientID      uuid.UUID
+	Issuer        ReceiptSettingsOutput
+	PatientCPF    string
+}
\ No newline at end of file
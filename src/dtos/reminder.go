@@ -0,0 +1,33 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderSettingsInput struct {
+	Enabled        *bool `json:"enabled" binding:"required"`
+	OffsetsMinutes []int `json:"offsets_minutes"`
+}
+
+type ReminderSettingsOutput struct {
+	Enabled        bool  `json:"enabled"`
+	OffsetsMinutes []int `json:"offsets_minutes"`
+}
+
+type DueReminder struct {
+	AppointmentID uuid.UUID
+	ClientID      uuid.UUID
+	OffsetMinutes int
+	PatientName   string
+	Email         string
+	Phone         string
+	Channels      []string
+	Date          time.Time
+	StartTime     time.Time
+	EndTime       time.Time
+	Modality      string
+	VideoURL      string
+	Address       string
+}
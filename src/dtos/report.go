@@ -0,0 +1,60 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReportRevenueByMonth    = "revenue-by-month"
+	ReportBalances          = "balances"
+	ReportRevenueByPatient  = "revenue-by-patient"
+	ReportRevenueByModality = "revenue-by-modality"
+	ReportAverageTicket     = "average-ticket"
+)
+
+// ReportSession is a completed appointment and what it was worth: its
+// charge net of discounts, its share of the package that paid for it, or
+// what its insurance plan was billed.
+type ReportSession struct {
+	PatientID   uuid.UUID
+	PatientName string
+	Date        time.Time
+	Modality    string
+	ValueCents  int64
+}
+
+// Revenue splits what the practice made into sessions performed (billed,
+// by session date) and money that came in (received, payments less refunds,
+// by payment date).
+type Revenue struct {
+	Sessions           int   `json:"sessions"`
+	BilledCents        int64 `json:"billed_cents"`
+	ReceivedCents      int64 `json:"received_cents"`
+	AverageTicketCents int64 `json:"average_ticket_cents"`
+}
+
+type MonthRevenueOutput struct {
+	Month string `json:"month"`
+	Revenue
+}
+
+type PatientRevenueOutput struct {
+	PatientID   uuid.UUID `json:"patient_id"`
+	PatientName string    `json:"patient_name"`
+	Revenue
+}
+
+type ModalityRevenueOutput struct {
+	Modality           string `json:"modality"`
+	Sessions           int    `json:"sessions"`
+	BilledCents        int64  `json:"billed_cents"`
+	AverageTicketCents int64  `json:"average_ticket_cents"`
+}
+
+type AverageTicketOutput struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Revenue
+}
\ No newline at end of file
@@ -0,0 +1,49 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PayerCPFInput sets who pays for the patient's sessions. An empty cpf means
+// the patient pays themselves.
+type PayerCPFInput struct {
+	PayerCPF string `json:"payer_cpf"`
+}
+
+// IncomeEntry is a payment or refund on the ledger, with the cpfs Carnê-Leão
+// asks for.
+type IncomeEntry struct {
+	PatientID   uuid.UUID
+	PatientName string
+	PatientCPF  string
+	PayerCPF    string
+	Kind        string
+	AmountCents int64
+	CreatedAt   time.Time
+}
+
+type MonthIncomeOutput struct {
+	Month      string `json:"month"`
+	TotalCents int64  `json:"total_cents"`
+}
+
+type PatientIncomeOutput struct {
+	PatientID   uuid.UUID `json:"patient_id"`
+	PatientName string    `json:"patient_name"`
+	PatientCPF  string    `json:"patient_cpf"`
+	PayerCPF    string    `json:"payer_cpf"`
+	TotalCents  int64     `json:"total_cents"`
+}
+
+// IncomeSummaryOutput is what was received in a year, net of refunds, for
+// the annual tax return. MissingCPF counts patients who paid but have no cpf
+// on file, whose income Carnê-Leão cannot take.
+type IncomeSummaryOutput struct {
+	Year       int                   `json:"year"`
+	TotalCents int64                 `json:"total_cents"`
+	Months     []MonthIncomeOutput   `json:"months"`
+	Patients   []PatientIncomeOutput `json:"patients"`
+	MissingCPF int                   `json:"missing_cpf"`
+}
\ No newline at end of file
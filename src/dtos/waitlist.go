@@ -0,0 +1,74 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WaitlistWaiting = "waiting"
+	WaitlistBooked  = "booked"
+	WaitlistLeft    = "left"
+)
+
+const (
+	OfferPending  = "pending"
+	OfferClaimed  = "claimed"
+	OfferDeclined = "declined"
+	OfferExpired  = "expired"
+)
+
+type WaitlistInput struct {
+	Weekdays  []int  `json:"weekdays" binding:"required"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+	Modality  string `json:"modality"`
+}
+
+type WaitlistEntryOutput struct {
+	ID          uuid.UUID            `json:"id"`
+	PatientID   uuid.UUID            `json:"patient_id"`
+	PatientName string               `json:"patient_name,omitempty"`
+	Weekdays    []int                `json:"weekdays"`
+	StartTime   string               `json:"start_time"`
+	EndTime     string               `json:"end_time"`
+	Modality    string               `json:"modality,omitempty"`
+	Position    int                  `json:"position"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Offer       *WaitlistOfferOutput `json:"offer,omitempty"`
+}
+
+// WaitlistOfferOutput is the offer currently waiting for the patient's answer.
+type WaitlistOfferOutput struct {
+	ID        uuid.UUID `json:"id"`
+	Date      string    `json:"date"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+	Modality  string    `json:"modality"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type WaitlistOffer struct {
+	ID            uuid.UUID
+	EntryID       uuid.UUID
+	ClientID      uuid.UUID
+	PatientID     uuid.UUID
+	Patient       PatientContact
+	Date          time.Time
+	StartTime     time.Time
+	EndTime       time.Time
+	LocationID    uuid.NullUUID
+	Modality      string
+	Address       string
+	Status        string
+	ExpiresAt     time.Time
+	AppointmentID uuid.NullUUID
+}
+
+// OpenDay is a day of a psychologist's agenda whose offers went unanswered,
+// so its free times can be offered to the next patients in line.
+type OpenDay struct {
+	ClientID uuid.UUID
+	Date     time.Time
+}
\ No newline at end of file
@@ -0,0 +1,184 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+	MethodPublish = "PUBLISH"
+
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Event is a VEVENT. UID must stay the same for the whole life of the
+// appointment and Sequence must grow on every change, otherwise calendar
+// clients add a second event instead of updating the first.
+type Event struct {
+	UID          string
+	Sequence     int
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Status       string
+	LastModified time.Time
+	Organizer    *Person
+	Attendee     *Person
+}
+
+func UID(id fmt.Stringer) string {
+	return id.String() + "@clinify"
+}
+
+// BuildCalendar renders a VCALENDAR with the given events for an iTIP
+// message sent by email (REQUEST, CANCEL).
+func BuildCalendar(method string, events ...Event) []byte {
+	return buildCalendar([]string{"METHOD:" + method}, events)
+}
+
+// BuildFeed renders a VCALENDAR meant for subscription. Clients poll it, so
+// events that are no longer listed get removed from their copy.
+func BuildFeed(name string, refresh time.Duration, events ...Event) []byte {
+	ttl := fmt.Sprintf("PT%dM", int(refresh.Minutes()))
+
+	return buildCalendar([]string{
+		"METHOD:" + MethodPublish,
+		"X-WR-CALNAME:" + escapeText(name),
+		"X-PUBLISHED-TTL:" + ttl,
+		"REFRESH-INTERVAL;VALUE=DURATION:" + ttl,
+	}, events)
+}
+
+// BuildObject renders a VCALENDAR stored as a CalDAV resource, which must not
+// carry a METHOD (RFC 4791 section 4.1).
+func BuildObject(events ...Event) []byte {
+	return buildCalendar(nil, events)
+}
+
+func buildCalendar(properties []string, events []Event) []byte {
+	var b strings.Builder
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//Clinify//Clinify Agenda//PT")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+
+	for _, property := range properties {
+		writeLine(&b, property)
+	}
+
+	for _, event := range events {
+		writeEvent(&b, event)
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+
+	return []byte(b.String())
+}
+
+func writeEvent(b *strings.Builder, event Event) {
+	stamp := time.Now()
+	if !event.LastModified.IsZero() {
+		stamp = event.LastModified
+	}
+
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, "UID:"+escapeText(event.UID))
+	writeLine(b, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+	writeLine(b, "DTSTAMP:"+formatTime(stamp))
+	writeLine(b, "DTSTART:"+formatTime(event.Start))
+	writeLine(b, "DTEND:"+formatTime(event.End))
+	writeLine(b, "SUMMARY:"+escapeText(event.Summary))
+
+	if event.Description != "" {
+		writeLine(b, "DESCRIPTION:"+escapeText(event.Description))
+	}
+
+	if event.Location != "" {
+		writeLine(b, "LOCATION:"+escapeText(event.Location))
+	}
+
+	if event.URL != "" {
+		writeLine(b, "URL:"+event.URL)
+	}
+
+	if event.Status != "" {
+		writeLine(b, "STATUS:"+event.Status)
+	}
+
+	if !event.LastModified.IsZero() {
+		writeLine(b, "LAST-MODIFIED:"+formatTime(event.LastModified))
+	}
+
+	if event.Organizer != nil {
+		writeLine(b, fmt.Sprintf("ORGANIZER;CN=%s:mailto:%s", quoteParam(event.Organizer.Name), event.Organizer.Email))
+	}
+
+	if event.Attendee != nil {
+		writeLine(b, fmt.Sprintf("ATTENDEE;CN=%s;ROLE=REQ-PARTICIPANT;RSVP=FALSE:mailto:%s", quoteParam(event.Attendee.Name), event.Attendee.Email))
+	}
+
+	writeLine(b, "END:VEVENT")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escapes a TEXT value (RFC 5545 section 3.3.11).
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+
+	return replacer.Replace(value)
+}
+
+// quoteParam makes a value safe to use as a property parameter.
+func quoteParam(value string) string {
+	value = strings.NewReplacer(`"`, "'", "\r", " ", "\n", " ").Replace(value)
+	return `"` + value + `"`
+}
+
+// writeLine writes a content line folded at 75 octets (RFC 5545 section 3.1),
+// without splitting UTF-8 sequences. The space that starts each continuation
+// line counts towards its 75 octets.
+func writeLine(b *strings.Builder, line string) {
+	limit := 75
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
\ No newline at end of file
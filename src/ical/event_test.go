@@ -0,0 +1,79 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Sessão de terapia", "Sessão de terapia"},
+		{"Rua A, 10; sala 2", `Rua A\, 10\; sala 2`},
+		{`C:\agenda`, `C:\\agenda`},
+		{"linha 1\nlinha 2", `linha 1\nlinha 2`},
+		{"linha 1\r\nlinha 2", `linha 1\nlinha 2`},
+		{"linha 1\rlinha 2", `linha 1\nlinha 2`},
+		{`\,`, `\\\,`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Sessão"},
+		{"exactly 75 octets", "DESCRIPTION:" + strings.Repeat("a", 63)},
+		{"76 octets", "DESCRIPTION:" + strings.Repeat("a", 64)},
+		{"several continuation lines", "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		{"multi-byte runes", "DESCRIPTION:" + strings.Repeat("ção", 60)},
+		{"four-byte runes", "SUMMARY:" + strings.Repeat("🗓", 50)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeLine(&b, tt.line)
+
+			out := b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets long: %q", i, len(line), line)
+				}
+
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, line)
+				}
+			}
+
+			if len(tt.line) <= 75 && len(lines) != 1 {
+				t.Errorf("a %d octet line was folded into %d lines", len(tt.line), len(lines))
+			}
+
+			// unfolding removes each CRLF and the space after it
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// BookingRequestScheduler expires booking requests the psychologist did not
+// answer in time, releasing the slots they were holding.
+type BookingRequestScheduler struct {
+	Service  *services.BookingService
+	Interval time.Duration
+}
+
+func (s *BookingRequestScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := s.Service.ExpireRequests(runCtx); err != nil {
+			utils.LogError("bookingRequestScheduler (error expiring booking requests)", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,115 @@
+package jobs
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/notify"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const (
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = 6 * time.Hour
+	outboxLockFor     = 5 * time.Minute
+)
+
+// OutboxWorker delivers the notifications queued in email_outbox with a pool of
+// workers. Failed sends are retried with exponential backoff until the
+// message runs out of attempts and is moved to the dead state.
+type OutboxWorker struct {
+	Repo     *repository.OutboxRepository
+	Notifier *notify.Notifier
+	Workers  int
+	Interval time.Duration
+}
+
+// Start polls the outbox until ctx is cancelled, then waits for the messages
+// being sent to finish.
+func (w *OutboxWorker) Start(ctx context.Context) {
+	messages := make(chan dtos.OutboxMessage)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for message := range messages {
+				w.deliver(message)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.dispatch(ctx, messages)
+
+		select {
+		case <-ctx.Done():
+			close(messages)
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *OutboxWorker) dispatch(ctx context.Context, messages chan<- dtos.OutboxMessage) {
+	queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	batch, err := w.Repo.ClaimBatch(queryCtx, w.Workers*4, outboxLockFor)
+	if err != nil {
+		utils.LogError("outboxWorker (error claiming messages)", err)
+		return
+	}
+
+	for _, message := range batch {
+		messages <- message
+	}
+}
+
+func (w *OutboxWorker) deliver(message dtos.OutboxMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := w.Notifier.Deliver(ctx, message.Channel, message.Payload)
+	if err == nil {
+		if err := w.Repo.MarkSent(ctx, message.ID); err != nil {
+			utils.LogError("outboxWorker (error marking message as sent)", err)
+		}
+		return
+	}
+
+	utils.LogError("outboxWorker (error sending message)", err)
+
+	status := dtos.OutboxPending
+	if message.Attempts >= message.MaxAttempts {
+		status = dtos.OutboxDead
+	}
+
+	if err := w.Repo.MarkFailed(ctx, message.ID, status, time.Now().Add(backoff(message.Attempts)), err.Error()); err != nil {
+		utils.LogError("outboxWorker (error marking message as failed)", err)
+	}
+}
+
+// backoff doubles the wait after every attempt (30s, 1m, 2m, ...) up to 6h,
+// with up to 20% jitter so instances do not retry in lockstep.
+func backoff(attempts int) time.Duration {
+	wait := outboxBaseBackoff
+	for i := 1; i < attempts && wait < outboxMaxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > outboxMaxBackoff {
+		wait = outboxMaxBackoff
+	}
+
+	return wait + time.Duration(rand.Int63n(int64(wait)/5+1))
+}
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		base     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, outboxMaxBackoff},
+		{50, outboxMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		// jitter is random, so check the bounds over many draws
+		for i := 0; i < 200; i++ {
+			got := backoff(tt.attempts)
+
+			if got < tt.base || got > tt.base+tt.base/5 {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempts, got, tt.base, tt.base+tt.base/5)
+			}
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// PaymentSyncScheduler polls the gateway for open payments, catching up on
+// webhooks that never arrived.
+type PaymentSyncScheduler struct {
+	Service  *services.PaymentService
+	Interval time.Duration
+}
+
+func (s *PaymentSyncScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := s.Service.SyncOpen(runCtx); err != nil {
+			utils.LogError("paymentSyncScheduler (error syncing payments)", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
\ No newline at end of file
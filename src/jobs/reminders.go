@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// ReminderScheduler periodically queues appointment reminders at the offsets
+// each psychologist configured. All state lives in the database, so it is safe
+// to restart and to run on several instances at once.
+type ReminderScheduler struct {
+	Repo          *repository.ReminderRepository
+	Notifications *services.NotificationService
+	Interval      time.Duration
+}
+
+func (s *ReminderScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ReminderScheduler) RunOnce(ctx context.Context) {
+	queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	now := time.Now().In(utils.AppLocation())
+
+	reminders, err := s.Repo.GetDueReminders(queryCtx, now, 100)
+	if err != nil {
+		utils.LogError("reminderScheduler (error getting due reminders)", err)
+		return
+	}
+
+	// reminders come ordered by offset, so the first one of each appointment is
+	// the closest to the session. After downtime several offsets can be due at
+	// once: only that one is sent and the older ones are marked as covered.
+	sent := make(map[uuid.UUID]bool)
+
+	for _, reminder := range reminders {
+		if ok, seen := sent[reminder.AppointmentID]; seen {
+			if ok {
+				if _, err := s.Repo.ClaimReminder(queryCtx, repository.DB, reminder.AppointmentID, reminder.OffsetMinutes); err != nil {
+					utils.LogError("reminderScheduler (error skipping older reminder)", err)
+				}
+			}
+			continue
+		}
+
+		sent[reminder.AppointmentID] = s.queue(queryCtx, reminder)
+	}
+}
+
+// queue claims the reminder and puts its notifications in the outbox in one
+// transaction: if another instance holds the claim nothing is queued.
+func (s *ReminderScheduler) queue(ctx context.Context, reminder dtos.DueReminder) bool {
+	startsAt := time.Date(
+		reminder.Date.Year(), reminder.Date.Month(), reminder.Date.Day(),
+		reminder.StartTime.Hour(), reminder.StartTime.Minute(), 0, 0, utils.AppLocation(),
+	)
+
+	confirmURL, cancelURL, err := utils.BuildAppointmentActionLinks(reminder.AppointmentID, startsAt)
+	if err != nil {
+		utils.LogError("reminderScheduler (error building action links)", err)
+	}
+
+	contact := dtos.PatientContact{
+		Name: reminder.PatientName,
+		Email: reminder.Email,
+		Phone: reminder.Phone,
+		Channels: reminder.Channels,
+	}
+
+	data := mailer.AppointmentData{
+		PatientName: reminder.PatientName,
+		Date: reminder.Date.Format("2006-01-02"),
+		StartTime: reminder.StartTime.Format("15:04"),
+		EndTime: reminder.EndTime.Format("15:04"),
+		Modality: reminder.Modality,
+		Address: reminder.Address,
+		VideoURL: reminder.VideoURL,
+		ConfirmURL: confirmURL,
+		CancelURL: cancelURL,
+	}
+
+	claimed := false
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		claimed, err = s.Repo.ClaimReminder(ctx, tx, reminder.AppointmentID, reminder.OffsetMinutes)
+		if err != nil || !claimed {
+			return err
+		}
+
+		return s.Notifications.NotifyPatient(ctx, tx, reminder.ClientID, mailer.TemplateAppointmentReminder, contact, data)
+	})
+	if err != nil {
+		utils.LogError("reminderScheduler (error queueing reminder)", err)
+		return false
+	}
+
+	return claimed
+}
\ No newline at end of file
@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// WaitlistScheduler closes waitlist offers nobody claimed in time, handing
+// their slots to the next patients in line.
+type WaitlistScheduler struct {
+	Service  *services.WaitlistService
+	Interval time.Duration
+}
+
+func (s *WaitlistScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := s.Service.ExpireOffers(runCtx); err != nil {
+			utils.LogError("waitlistScheduler (error expiring offers)", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CapturedMessage struct {
+	From string
+	To   []string
+	Raw  []byte
+}
+
+// MemoryTransport keeps every message in memory instead of sending it. Use it
+// in tests to inspect what would have been delivered.
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []CapturedMessage
+}
+
+func (t *MemoryTransport) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = append(t.messages, CapturedMessage{
+		From: from,
+		To:   append([]string(nil), to...),
+		Raw:  append([]byte(nil), msg...),
+	})
+
+	return nil
+}
+
+func (t *MemoryTransport) Messages() []CapturedMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]CapturedMessage(nil), t.messages...)
+}
+
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = nil
+}
+
+// FileTransport writes each message to Dir as an .eml file that any mail
+// client can open. Meant for local development.
+type FileTransport struct {
+	Dir string
+}
+
+func (t *FileTransport) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return fmt.Errorf("error creating capture dir: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), uuid.NewString()[:8])
+
+	if err := os.WriteFile(filepath.Join(t.Dir, name), msg, 0o644); err != nil {
+		return fmt.Errorf("error writing captured email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// NewMailerFromEnv builds the mailer from MAIL_TRANSPORT (smtp, file or
+// memory) and the SMTP_* variables. Defaults keep the previous behaviour:
+// Gmail on port 587 with STARTTLS and PLAIN auth.
+func NewMailerFromEnv() (*Mailer, error) {
+	from := getenv("MAIL_FROM", os.Getenv("SMTP_EMAIL"))
+
+	switch kind := getenv("MAIL_TRANSPORT", "smtp"); kind {
+	case "smtp":
+		transport := &SMTPTransport{
+			Host:     getenv("SMTP_HOST", "smtp.gmail.com"),
+			Port:     getenv("SMTP_PORT", "587"),
+			TLSMode:  getenv("SMTP_TLS", TLSModeStartTLS),
+			Auth:     getenv("SMTP_AUTH", AuthPlain),
+			Username: getenv("SMTP_USERNAME", os.Getenv("SMTP_EMAIL")),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			Timeout:  10 * time.Second,
+		}
+
+		switch transport.TLSMode {
+		case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+		default:
+			return nil, fmt.Errorf("invalid SMTP_TLS %q, expected starttls, tls or none", transport.TLSMode)
+		}
+
+		switch transport.Auth {
+		case AuthPlain, AuthLogin, AuthCRAMMD5, AuthNone:
+		default:
+			return nil, fmt.Errorf("invalid SMTP_AUTH %q, expected plain, login, cram-md5 or none", transport.Auth)
+		}
+
+		return NewMailer(from, transport), nil
+
+	case "file":
+		return NewMailer(from, &FileTransport{Dir: getenv("MAIL_CAPTURE_DIR", "tmp/mail")}), nil
+
+	case "memory":
+		return NewMailer(from, &MemoryTransport{}), nil
+
+	default:
+		return nil, fmt.Errorf("invalid MAIL_TRANSPORT %q, expected smtp, file or memory", kind)
+	}
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
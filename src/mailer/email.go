@@ -1,123 +1,39 @@
 package mailer
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
-	"net"
-	"net/smtp"
-	"time"
+	"net/mail"
 )
 
 type Mailer struct {
-	From     string
-	Password string
+	From      string
+	Transport Transport
 }
 
-func NewMailer(from, password string) *Mailer {
+func NewMailer(from string, transport Transport) *Mailer {
 	return &Mailer{
 		From: from,
-		Password: password,
+		Transport: transport,
 	}
 }
 
-func buildMessage(from, to, subject, body string) string {
-	return "From: " + from + "\n" +
-		"To: " + to + "\n" +
-		"Subject: " + subject + "\n" +
-		"MIME-version: 1.0;\n" +
-		"Content-Type: text/html; charset=\"UTF-8\"\n\n" +
-		body
-}
-
-func smtpConnect(host, port string) (*smtp.Client, net.Conn, error) {
-	dialer := net.Dialer{
-		Timeout: 5 * time.Second,
-	}
-
-	conn, err := dialer.Dial("tcp", host+":"+port)
+func (m *Mailer) SendMessage(ctx context.Context, msg Message) error {
+	raw, err := buildMessage(m.From, msg)
 	if err != nil {
-		return nil, nil, fmt.Errorf("smtp dial timeout error: %w", err)
-	}
-
-	client, err := smtp.NewClient(conn, host)
-	if err != nil {
-		return nil, nil, fmt.Errorf("smtp client error: %w", err)
-	}
-
-	return client, conn, nil
-}
-
-func smtpStartTLS(client *smtp.Client, host string) error {
-	if ok, _ := client.Extension("STARTTLS"); ok {
-		tlsConfig := &tls.Config{ServerName: host}
-
-		if err := client.StartTLS(tlsConfig); err != nil {
-			return fmt.Errorf("smtp starttls error: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func smtpAuthenticate(client *smtp.Client, from, password, host string) error {
-	auth := smtp.PlainAuth("", from, password, host)
-
-	if err := client.Auth(auth); err != nil {
-		return fmt.Errorf("smtp auth error: %w", err)
-	}
-
-	return nil
-}
-
-func smtpSendMessage(client *smtp.Client, from, to, msg string) error {
-	if err := client.Mail(from); err != nil {
-		return fmt.Errorf("smtp MAIL FROM error: %w", err)
-	}
-
-	if err := client.Rcpt(to); err != nil {
-		return fmt.Errorf("smtp RCPT TO error: %w", err)
+		return err
 	}
 
-	writer, err := client.Data()
+	// envelope addresses must be bare, without display names
+	from, err := mail.ParseAddress(m.From)
 	if err != nil {
-		return fmt.Errorf("smtp DATA error: %w", err)
+		return fmt.Errorf("invalid from address: %w", err)
 	}
 
-	if _, err := writer.Write([]byte(msg)); err != nil {
-		return fmt.Errorf("smtp write error: %w", err)
-	}
-
-	if err := writer.Close(); err != nil {
-		return fmt.Errorf("smtp close error: %w", err)
-	}
-
-	return nil
-}
-
-func (m *Mailer) Send(to, subject, body string) error {
-	smtpHost := "smtp.gmail.com"
-	smtpPort := "587"
-
-	msg := buildMessage(m.From, to, subject, body)
-
-	client, conn, err := smtpConnect(smtpHost, smtpPort)
+	to, err := mail.ParseAddress(msg.To)
 	if err != nil {
-		return err
-	}
-	defer conn.Close()
-	defer client.Close()
-
-	if err := smtpStartTLS(client, smtpHost); err != nil {
-		return err
-	}
-
-	if err := smtpAuthenticate(client, m.From, m.Password, smtpHost); err != nil {
-		return err
-	}
-
-	if err := smtpSendMessage(client, m.From, to, msg); err != nil {
-		return err
+		return fmt.Errorf("invalid recipient address: %w", err)
 	}
 
-	return nil
+	return m.Transport.Send(ctx, from.Address, []string{to.Address}, raw)
 }
\ No newline at end of file
@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"net/mail"
+	"testing"
+)
+
+func TestMailerSendMessage(t *testing.T) {
+	transport := &MemoryTransport{}
+	m := NewMailer("Clínica Clinify <agenda@clinify.test>", transport)
+
+	err := m.SendMessage(context.Background(), Message{
+		To: "Bruno Lima <bruno@example.com>",
+		Subject: "Sessão confirmada",
+		Text: "Até amanhã",
+		HTML: "<p>Até amanhã</p>",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	messages := transport.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(messages))
+	}
+
+	sent := messages[0]
+
+	// envelope addresses go without display names
+	if sent.From != "agenda@clinify.test" {
+		t.Errorf("envelope from = %q, want %q", sent.From, "agenda@clinify.test")
+	}
+
+	if len(sent.To) != 1 || sent.To[0] != "bruno@example.com" {
+		t.Errorf("envelope to = %v, want [bruno@example.com]", sent.To)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(sent.Raw))
+	if err != nil {
+		t.Fatalf("sent message does not parse: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "Sessão confirmada" {
+		t.Errorf("subject = %q (%v), want %q", subject, err, "Sessão confirmada")
+	}
+
+	transport.Reset()
+
+	if got := transport.Messages(); len(got) != 0 {
+		t.Errorf("Reset() left %d messages", len(got))
+	}
+}
+
+func TestMailerSendMessageInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		msg  Message
+	}{
+		{"recipient", "agenda@clinify.test", Message{To: "not an address", Subject: "Oi", HTML: "<p>Oi</p>"}},
+		{"sender", "clinify", Message{To: "bruno@example.com", Subject: "Oi", HTML: "<p>Oi</p>"}},
+		{"subject with a line break", "agenda@clinify.test", Message{To: "bruno@example.com", Subject: "Oi\r\nBcc: x@example.com", HTML: "<p>Oi</p>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &MemoryTransport{}
+
+			if err := NewMailer(tt.from, transport).SendMessage(context.Background(), tt.msg); err == nil {
+				t.Error("SendMessage() succeeded")
+			}
+
+			if got := transport.Messages(); len(got) != 0 {
+				t.Errorf("sent %d messages, want none", len(got))
+			}
+		})
+	}
+}
\ No newline at end of file
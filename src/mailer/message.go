@@ -0,0 +1,21 @@
+package mailer
+
+// Message is an email ready to be sent. It is stored as JSON in the outbox,
+// so fields must stay serializable.
+type Message struct {
+	To          string       `json:"to"`
+	Subject     string       `json:"subject"`
+	Text        string       `json:"text,omitempty"`
+	HTML        string       `json:"html"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a file sent along with the message. ContentType may carry
+// parameters, e.g. "text/calendar; method=REQUEST". Attachments with a
+// ContentID are sent inline, so the HTML body can show them with "cid:".
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	ContentID   string `json:"content_id,omitempty"`
+	Data        []byte `json:"data"`
+}
@@ -0,0 +1,218 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// buildMessage encodes msg as an RFC 5322 message. Attachments wrap the body
+// in a multipart/mixed.
+func buildMessage(from string, msg Message) ([]byte, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+
+	toAddr, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, fmt.Errorf("invalid subject")
+	}
+
+	var buf bytes.Buffer
+
+	writeHeader(&buf, "From", fromAddr.String())
+	writeHeader(&buf, "To", toAddr.String())
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", fmt.Sprintf("<%s@%s>", uuid.NewString(), domainOf(fromAddr.Address)))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	body, err := buildBody(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg.Attachments) == 0 {
+		writePartHeader(&buf, body.header)
+		buf.WriteString("\r\n")
+		buf.Write(body.content)
+
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+
+	writeHeader(&buf, "Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": writer.Boundary()}))
+	buf.WriteString("\r\n")
+
+	part, err := writer.CreatePart(body.header)
+	if err != nil {
+		return nil, fmt.Errorf("error creating body part: %w", err)
+	}
+
+	if _, err := part.Write(body.content); err != nil {
+		return nil, fmt.Errorf("error writing body part: %w", err)
+	}
+
+	for _, attachment := range msg.Attachments {
+		if err := writeAttachment(writer, attachment); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error closing multipart message: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+type mimePart struct {
+	header  textproto.MIMEHeader
+	content []byte
+}
+
+// buildBody encodes the text and HTML bodies. When both are set it produces
+// a multipart/alternative with the plain-text part first, as clients pick the
+// last alternative they can display.
+func buildBody(msg Message) (mimePart, error) {
+	if msg.Text == "" || msg.HTML == "" {
+		contentType, body := "text/plain", msg.Text
+		if msg.HTML != "" {
+			contentType, body = "text/html", msg.HTML
+		}
+
+		return quotedPrintablePart(contentType, body)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, alternative := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		part, err := quotedPrintablePart(alternative.contentType, alternative.body)
+		if err != nil {
+			return mimePart{}, err
+		}
+
+		w, err := writer.CreatePart(part.header)
+		if err != nil {
+			return mimePart{}, fmt.Errorf("error creating %s part: %w", alternative.contentType, err)
+		}
+
+		if _, err := w.Write(part.content); err != nil {
+			return mimePart{}, fmt.Errorf("error writing %s part: %w", alternative.contentType, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return mimePart{}, fmt.Errorf("error closing multipart body: %w", err)
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": writer.Boundary()}))
+
+	return mimePart{header: header, content: buf.Bytes()}, nil
+}
+
+func quotedPrintablePart(contentType, body string) (mimePart, error) {
+	var buf bytes.Buffer
+	qp := quotedprintable.NewWriter(&buf)
+
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return mimePart{}, fmt.Errorf("error encoding body: %w", err)
+	}
+
+	if err := qp.Close(); err != nil {
+		return mimePart{}, fmt.Errorf("error encoding body: %w", err)
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=UTF-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	return mimePart{header: header, content: buf.Bytes()}, nil
+}
+
+func writeAttachment(writer *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if strings.ContainsAny(contentType, "\r\n") || strings.ContainsAny(attachment.Filename, "\r\n") || strings.ContainsAny(attachment.ContentID, "\r\n<>") {
+		return fmt.Errorf("invalid attachment %q", attachment.Filename)
+	}
+
+	disposition := "attachment"
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+
+	if attachment.ContentID != "" {
+		disposition = "inline"
+		header.Set("Content-ID", "<"+attachment.ContentID+">")
+	}
+
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("error creating attachment part: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+
+	// RFC 2045 limits encoded lines to 76 characters
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(part, encoded[:76]+"\r\n"); err != nil {
+			return fmt.Errorf("error writing attachment: %w", err)
+		}
+
+		encoded = encoded[76:]
+	}
+
+	if _, err := io.WriteString(part, encoded+"\r\n"); err != nil {
+		return fmt.Errorf("error writing attachment: %w", err)
+	}
+
+	return nil
+}
+
+func writePartHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			writeHeader(buf, key, value)
+		}
+	}
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+func domainOf(address string) string {
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return address[at+1:]
+	}
+
+	return "localhost"
+}
\ No newline at end of file
@@ -0,0 +1,204 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+const (
+	TLSModeStartTLS = "starttls"
+	TLSModeImplicit = "tls"
+	TLSModeNone     = "none"
+
+	AuthPlain   = "plain"
+	AuthLogin   = "login"
+	AuthCRAMMD5 = "cram-md5"
+	AuthNone    = "none"
+)
+
+// SMTPTransport sends mail through an SMTP relay. TLSMode selects STARTTLS on
+// a plain connection (usually port 587), implicit TLS (port 465) or no TLS at
+// all, for a local MTA. Timeout bounds the whole exchange with the relay,
+// not just the dial.
+type SMTPTransport struct {
+	Host     string
+	Port     string
+	TLSMode  string
+	Auth     string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	client, stop, err := t.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	defer client.Close()
+
+	if t.TLSMode == TLSModeStartTLS {
+		if err := smtpStartTLS(client, t.Host); err != nil {
+			return err
+		}
+	}
+
+	if err := smtpAuthenticate(client, t.auth()); err != nil {
+		return err
+	}
+
+	if err := smtpSendMessage(client, from, to, msg); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// connect dials the relay. The connection gets a deadline, the earlier of
+// Timeout and the deadline of ctx, so a relay that stops answering fails the
+// send instead of blocking it forever, and is closed if ctx is cancelled.
+// Call stop once done with the client.
+func (t *SMTPTransport) connect(ctx context.Context) (client *smtp.Client, stop func() bool, err error) {
+	var deadline time.Time
+
+	if t.Timeout > 0 {
+		deadline = time.Now().Add(t.Timeout)
+	}
+
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	addr := net.JoinHostPort(t.Host, t.Port)
+
+	var conn net.Conn
+
+	if t.TLSMode == TLSModeImplicit {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: t.Host}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("smtp dial error: %w", err)
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("smtp deadline error: %w", err)
+	}
+
+	stop = context.AfterFunc(ctx, func() { conn.Close() })
+
+	client, err = smtp.NewClient(conn, t.Host)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, nil, fmt.Errorf("smtp client error: %w", err)
+	}
+
+	return client, stop, nil
+}
+
+func (t *SMTPTransport) auth() smtp.Auth {
+	switch t.Auth {
+	case AuthPlain:
+		return smtp.PlainAuth("", t.Username, t.Password, t.Host)
+	case AuthLogin:
+		return &loginAuth{username: t.Username, password: t.Password}
+	case AuthCRAMMD5:
+		return smtp.CRAMMD5Auth(t.Username, t.Password)
+	default:
+		return nil
+	}
+}
+
+func smtpStartTLS(client *smtp.Client, host string) error {
+	if ok, _ := client.Extension("STARTTLS"); !ok {
+		return errors.New("smtp server does not support STARTTLS")
+	}
+
+	tlsConfig := &tls.Config{ServerName: host}
+
+	if err := client.StartTLS(tlsConfig); err != nil {
+		return fmt.Errorf("smtp starttls error: %w", err)
+	}
+
+	return nil
+}
+
+func smtpAuthenticate(client *smtp.Client, auth smtp.Auth) error {
+	if auth == nil {
+		return nil
+	}
+
+	if err := client.Auth(auth); err != nil {
+		return fmt.Errorf("smtp auth error: %w", err)
+	}
+
+	return nil
+}
+
+func smtpSendMessage(client *smtp.Client, from string, to []string, msg []byte) error {
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM error: %w", err)
+	}
+
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp RCPT TO error: %w", err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA error: %w", err)
+	}
+
+	if _, err := writer.Write(msg); err != nil {
+		return fmt.Errorf("smtp write error: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("smtp close error: %w", err)
+	}
+
+	return nil
+}
+
+// loginAuth implements the non-standard but widely deployed AUTH LOGIN
+// mechanism (Office 365, many hosting relays), which net/smtp lacks.
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" {
+		return "", nil, errors.New("refusing AUTH LOGIN over an unencrypted connection")
+	}
+
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch string(fromServer) {
+	case "Username:", "username:":
+		return []byte(a.username), nil
+	case "Password:", "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected AUTH LOGIN challenge: %q", fromServer)
+	}
+}
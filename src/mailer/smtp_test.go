@@ -0,0 +1,78 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// stuckRelay accepts connections and never answers, like a relay that hangs
+// before its greeting.
+func stuckRelay(t *testing.T) (string, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port
+}
+
+func TestSMTPTransportStuckRelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		ctx     func() (context.Context, context.CancelFunc)
+	}{
+		{"transport timeout", 200 * time.Millisecond, func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}},
+		{"context deadline", time.Minute, func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 200*time.Millisecond)
+		}},
+		{"context cancelled", 0, func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(200*time.Millisecond, cancel)
+			return ctx, cancel
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := stuckRelay(t)
+
+			transport := &SMTPTransport{Host: host, Port: port, TLSMode: TLSModeNone, Auth: AuthNone, Timeout: tt.timeout}
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- transport.Send(ctx, "from@clinify.test", []string{"to@clinify.test"}, []byte("Subject: test\r\n\r\nbody"))
+			}()
+
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Error("Send() to a stuck relay succeeded")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Send() is still blocked on a stuck relay")
+			}
+		})
+	}
+}
\ No newline at end of file
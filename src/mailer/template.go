@@ -0,0 +1,252 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*
+var defaultTemplates embed.FS
+
+const (
+	TemplateAppointmentConfirmation = "appointment_confirmation"
+	TemplateAppointmentReminder     = "appointment_reminder"
+	TemplatePatientCancelled        = "patient_cancelled"
+	TemplateAppointmentRescheduled  = "appointment_rescheduled"
+	TemplateAppointmentCancelled    = "appointment_cancelled"
+	TemplateWaitlistOffer           = "waitlist_offer"
+	TemplateBookingRequested        = "booking_requested"
+	TemplateBookingDeclined         = "booking_declined"
+	TemplatePixPayment              = "pix_payment"
+	TemplateReceipt                 = "receipt"
+)
+
+// Branding is what a clinic can customize on every email without touching
+// the templates themselves.
+type Branding struct {
+	ClinicName   string
+	LogoURL      string
+	PrimaryColor string
+	Footer       string
+}
+
+// TemplateOverride replaces the built-in subject, HTML content and/or text
+// body of one template for a single clinic. Empty fields keep the default.
+// HTML overrides only replace the "content" block; the branded layout stays.
+type TemplateOverride struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type AppointmentData struct {
+	PatientName string
+	Date        string
+	StartTime   string
+	EndTime     string
+	Modality    string
+	Address     string
+	VideoURL    string
+	ConfirmURL  string
+	CancelURL   string
+	ClaimURL    string
+	DeclineURL  string
+	ExpiresAt   string
+	Reason      string
+	CancellationPolicy string
+}
+
+// PaymentData fills payment request emails. QRCodeCID names the inline
+// attachment holding the QR code image.
+type PaymentData struct {
+	PatientName string
+	Amount      string
+	Description string
+	PixCode     string
+	QRCodeCID   string
+}
+
+// ReceiptData fills the email a receipt is attached to.
+type ReceiptData struct {
+	PatientName string
+	Number      string
+	Amount      string
+	Reference   string
+}
+
+type templateSpec struct {
+	Subject string
+	Sample  any
+}
+
+var sampleAppointment = AppointmentData{
+	PatientName: "Maria Silva",
+	Date:        "2025-01-10",
+	StartTime:   "14:00",
+	EndTime:     "15:00",
+	Modality:    "in_person",
+	Address:     "Rua Exemplo, 123",
+	ConfirmURL:  "https://example.com/confirm",
+	CancelURL:   "https://example.com/cancel",
+	CancellationPolicy: "Cancelamentos feitos com menos de 24 horas de antecedência têm cobrança do valor da sessão (R$ 150,00).",
+}
+
+var sampleWaitlistOffer = AppointmentData{
+	PatientName: "Maria Silva",
+	Date:        "2025-01-10",
+	StartTime:   "14:00",
+	EndTime:     "15:00",
+	Modality:    "in_person",
+	Address:     "Rua Exemplo, 123",
+	ClaimURL:    "https://example.com/claim",
+	DeclineURL:  "https://example.com/decline",
+	ExpiresAt:   "2025-01-08 18:00",
+}
+
+var sampleBookingRequest = AppointmentData{
+	PatientName: "Maria Silva",
+	Date:        "2025-01-10",
+	StartTime:   "14:00",
+	EndTime:     "15:00",
+	Modality:    "in_person",
+	Address:     "Rua Exemplo, 123",
+	ExpiresAt:   "2025-01-08 18:00",
+	Reason:      "Agenda indisponível nesta semana",
+}
+
+var samplePayment = PaymentData{
+	PatientName: "Maria Silva",
+	Amount:      "R$ 150,00",
+	Description: "Sessões de janeiro",
+	PixCode:     "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D",
+	QRCodeCID:   "pix-qrcode",
+}
+
+var sampleReceipt = ReceiptData{
+	PatientName: "Maria Silva",
+	Number:      "0001",
+	Amount:      "R$ 600,00",
+	Reference:   "atendimento psicológico em janeiro de 2025",
+}
+
+var templateSpecs = map[string]templateSpec{
+	TemplateAppointmentConfirmation: {Subject: "Confirmação de Agendamento", Sample: sampleAppointment},
+	TemplateAppointmentReminder:     {Subject: "Lembrete de Atendimento", Sample: sampleAppointment},
+	TemplatePatientCancelled:        {Subject: "Atendimento cancelado pelo paciente", Sample: sampleAppointment},
+	TemplateAppointmentRescheduled:  {Subject: "Atendimento Remarcado", Sample: sampleAppointment},
+	TemplateAppointmentCancelled:    {Subject: "Atendimento Cancelado", Sample: sampleAppointment},
+	TemplateWaitlistOffer:           {Subject: "Horário Disponível", Sample: sampleWaitlistOffer},
+	TemplateBookingRequested:        {Subject: "Nova Solicitação de Agendamento", Sample: sampleBookingRequest},
+	TemplateBookingDeclined:         {Subject: "Solicitação Não Aceita", Sample: sampleBookingRequest},
+	TemplatePixPayment:              {Subject: "Pagamento via Pix", Sample: samplePayment},
+	TemplateReceipt:                 {Subject: "Recibo de Atendimento", Sample: sampleReceipt},
+}
+
+type templateData struct {
+	Brand Branding
+	Data  any
+}
+
+func TemplateNames() []string {
+	names := make([]string, 0, len(templateSpecs))
+	for name := range templateSpecs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Render builds the message for template name, addressed to to.
+func Render(name, to string, brand Branding, data any, override TemplateOverride) (Message, error) {
+	spec, ok := templateSpecs[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	if brand.PrimaryColor == "" {
+		brand.PrimaryColor = "#4f46e5"
+	}
+
+	root := templateData{Brand: brand, Data: data}
+
+	html, err := renderHTML(name, override.HTML, root)
+	if err != nil {
+		return Message{}, err
+	}
+
+	text, err := renderText(name, override.Text, root)
+	if err != nil {
+		return Message{}, err
+	}
+
+	subject := spec.Subject
+	if override.Subject != "" {
+		subject = override.Subject
+	}
+
+	return Message{To: to, Subject: subject, Text: text, HTML: html}, nil
+}
+
+// ValidateOverride renders the override against sample data so broken
+// templates are rejected when saved instead of when an email is due.
+func ValidateOverride(name string, override TemplateOverride) error {
+	spec, ok := templateSpecs[name]
+	if !ok {
+		return fmt.Errorf("unknown email template %q", name)
+	}
+
+	_, err := Render(name, "paciente@example.com", Branding{ClinicName: "Clínica"}, spec.Sample, override)
+	return err
+}
+
+func renderHTML(name, override string, root templateData) (string, error) {
+	tmpl, err := htmltemplate.ParseFS(defaultTemplates, "templates/layout.html")
+	if err != nil {
+		return "", fmt.Errorf("error parsing email layout: %w", err)
+	}
+
+	if override != "" {
+		_, err = tmpl.New(name).Parse(`{{define "content"}}` + override + `{{end}}`)
+	} else {
+		_, err = tmpl.ParseFS(defaultTemplates, "templates/"+name+".html")
+	}
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s html template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout.html", root); err != nil {
+		return "", fmt.Errorf("error rendering %s html template: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+func renderText(name, override string, root templateData) (string, error) {
+	var (
+		tmpl *texttemplate.Template
+		err  error
+	)
+
+	if override != "" {
+		tmpl, err = texttemplate.New(name).Parse(override)
+	} else {
+		tmpl, err = texttemplate.ParseFS(defaultTemplates, "templates/"+name+".txt")
+	}
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s text template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, root); err != nil {
+		return "", fmt.Errorf("error rendering %s text template: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,9 @@
+package mailer
+
+import "context"
+
+// Transport delivers an already built RFC 5322 message. It gives up once ctx
+// is done.
+type Transport interface {
+	Send(ctx context.Context, from string, to []string, msg []byte) error
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+)
+
+// appointmentTexts holds the SMS text of each appointment template. The verbs
+// receive the clinic name, the date and the start time.
+var appointmentTexts = map[string]string{
+	mailer.TemplateAppointmentConfirmation: "%s: seu atendimento foi agendado para %s às %s.",
+	mailer.TemplateAppointmentReminder:     "%s: lembrete do seu atendimento em %s às %s.",
+	mailer.TemplateAppointmentRescheduled:  "%s: seu atendimento foi remarcado para %s às %s.",
+	mailer.TemplateAppointmentCancelled:    "%s: seu atendimento de %s às %s foi cancelado.",
+	mailer.TemplateWaitlistOffer:           "%s: abriu um horário em %s às %s para você, da lista de espera.",
+	mailer.TemplateBookingDeclined:         "%s: sua solicitação de atendimento em %s às %s não pôde ser aceita.",
+}
+
+// AppointmentMessage builds the SMS or WhatsApp version of an appointment
+// email template. It returns false for templates without a phone version.
+//
+// WhatsApp templates must be registered with the provider under the same name
+// as the email template, with the parameters patient name, clinic name, date,
+// start time and link, in that order.
+func AppointmentMessage(channel, template, to, clinicName string, data mailer.AppointmentData) (Message, bool) {
+	text, ok := appointmentTexts[template]
+	if !ok {
+		return Message{}, false
+	}
+
+	date := data.Date
+	if parsed, err := time.Parse("2006-01-02", data.Date); err == nil {
+		date = parsed.Format("02/01/2006")
+	}
+
+	link := data.ConfirmURL
+	switch {
+	case data.ClaimURL != "":
+		link = data.ClaimURL
+	case data.VideoURL != "" && template != mailer.TemplateAppointmentCancelled:
+		link = data.VideoURL
+	}
+
+	if channel == ChannelWhatsApp {
+		language := os.Getenv("WHATSAPP_LANGUAGE")
+		if language == "" {
+			language = "pt_BR"
+		}
+
+		return Message{
+			Channel:  channel,
+			To:       to,
+			Template: template,
+			Language: language,
+			Params:   []string{data.PatientName, clinicName, date, data.StartTime, link},
+		}, true
+	}
+
+	body := fmt.Sprintf(text, clinicName, date, data.StartTime)
+
+	switch {
+	case template == mailer.TemplateAppointmentCancelled, template == mailer.TemplateBookingDeclined:
+	case data.ClaimURL != "":
+		body += " Reserve até " + data.ExpiresAt + ": " + data.ClaimURL
+	case data.VideoURL != "":
+		body += " Link da sessão: " + data.VideoURL
+	case data.ConfirmURL != "":
+		body += " Confirme: " + data.ConfirmURL
+	}
+
+	return Message{Channel: channel, To: to, Text: body}, true
+}
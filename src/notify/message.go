@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ChannelEmail    = "email"
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+var Channels = []string{ChannelEmail, ChannelSMS, ChannelWhatsApp}
+
+// Message is a text notification for a phone channel. SMS providers send Text
+// as is; WhatsApp providers send the pre-approved Template filled with Params,
+// as business-initiated WhatsApp messages must use a template. It is stored as
+// JSON in the outbox, so fields must stay serializable.
+type Message struct {
+	Channel  string   `json:"channel"`
+	To       string   `json:"to"`
+	Text     string   `json:"text,omitempty"`
+	Template string   `json:"template,omitempty"`
+	Language string   `json:"language,omitempty"`
+	Params   []string `json:"params,omitempty"`
+}
+
+func IsChannel(channel string) bool {
+	for _, known := range Channels {
+		if channel == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NormalizePhone returns phone in E.164 format. Numbers typed without the
+// country code are taken as Brazilian: two digit area code followed by an
+// 8 or 9 digit number.
+func NormalizePhone(phone string) (string, error) {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	number := digits.String()
+
+	if !strings.HasPrefix(strings.TrimSpace(phone), "+") && (len(number) == 10 || len(number) == 11) {
+		number = "55" + number
+	}
+
+	if len(number) < 11 || len(number) > 15 {
+		return "", fmt.Errorf("invalid phone number %q", phone)
+	}
+
+	return "+" + number, nil
+}
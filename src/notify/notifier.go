@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+)
+
+// Notifier routes outbox messages to the mailer or to the provider of their
+// channel.
+type Notifier struct {
+	Mailer   *mailer.Mailer
+	SMS      Provider
+	WhatsApp Provider
+}
+
+func NewNotifierFromEnv(m *mailer.Mailer) (*Notifier, error) {
+	sms, err := NewProviderFromEnv(ChannelSMS)
+	if err != nil {
+		return nil, err
+	}
+
+	whatsapp, err := NewProviderFromEnv(ChannelWhatsApp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifier{Mailer: m, SMS: sms, WhatsApp: whatsapp}, nil
+}
+
+// Deliver sends payload, a mailer.Message for email and a Message for the
+// other channels.
+func (n *Notifier) Deliver(ctx context.Context, channel string, payload []byte) error {
+	if channel == ChannelEmail {
+		var msg mailer.Message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return err
+		}
+
+		return n.Mailer.SendMessage(ctx, msg)
+	}
+
+	var provider Provider
+
+	switch channel {
+	case ChannelSMS:
+		provider = n.SMS
+	case ChannelWhatsApp:
+		provider = n.WhatsApp
+	}
+
+	if provider == nil {
+		return fmt.Errorf("no provider configured for channel %q", channel)
+	}
+
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return err
+	}
+
+	return provider.Send(ctx, msg)
+}
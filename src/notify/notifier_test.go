@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+)
+
+func TestNotifierDeliver(t *testing.T) {
+	transport := &mailer.MemoryTransport{}
+	sms := &MemoryProvider{}
+	whatsapp := &MemoryProvider{}
+
+	n := &Notifier{
+		Mailer: mailer.NewMailer("agenda@clinify.test", transport),
+		SMS: sms,
+		WhatsApp: whatsapp,
+	}
+
+	data := mailer.AppointmentData{PatientName: "Bruno", Date: "2025-03-12", StartTime: "14:00", ConfirmURL: "https://clinify.test/c/1"}
+
+	tests := []struct {
+		channel string
+		payload any
+	}{
+		{ChannelEmail, mailer.Message{To: "bruno@example.com", Subject: "Sessão confirmada", Text: "Até amanhã"}},
+		{ChannelSMS, mustMessage(t, ChannelSMS, data)},
+		{ChannelWhatsApp, mustMessage(t, ChannelWhatsApp, data)},
+	}
+
+	for _, tt := range tests {
+		payload, err := json.Marshal(tt.payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := n.Deliver(context.Background(), tt.channel, payload); err != nil {
+			t.Fatalf("Deliver(%s) error = %v", tt.channel, err)
+		}
+	}
+
+	if got := transport.Messages(); len(got) != 1 || got[0].To[0] != "bruno@example.com" {
+		t.Errorf("emails = %+v, want one to bruno@example.com", got)
+	}
+
+	if got := sms.Messages(); len(got) != 1 || got[0].Text != "Clinify: seu atendimento foi agendado para 12/03/2025 às 14:00. Confirme: https://clinify.test/c/1" {
+		t.Errorf("sms messages = %+v", got)
+	}
+
+	got := whatsapp.Messages()
+	if len(got) != 1 || got[0].Template != mailer.TemplateAppointmentConfirmation || got[0].Text != "" {
+		t.Fatalf("whatsapp messages = %+v", got)
+	}
+
+	want := []string{"Bruno", "Clinify", "12/03/2025", "14:00", "https://clinify.test/c/1"}
+	for i := range want {
+		if i >= len(got[0].Params) || got[0].Params[i] != want[i] {
+			t.Errorf("whatsapp params = %v, want %v", got[0].Params, want)
+			break
+		}
+	}
+}
+
+func TestNotifierDeliverErrors(t *testing.T) {
+	n := &Notifier{SMS: &MemoryProvider{}}
+
+	tests := []struct {
+		name    string
+		channel string
+		payload string
+	}{
+		{"channel without provider", ChannelWhatsApp, `{"to":"+5511999990000"}`},
+		{"unknown channel", "pager", `{"to":"+5511999990000"}`},
+		{"invalid payload", ChannelSMS, `{"to":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := n.Deliver(context.Background(), tt.channel, []byte(tt.payload)); err == nil {
+				t.Error("Deliver() succeeded")
+			}
+		})
+	}
+}
+
+func mustMessage(t *testing.T, channel string, data mailer.AppointmentData) Message {
+	t.Helper()
+
+	msg, ok := AppointmentMessage(channel, mailer.TemplateAppointmentConfirmation, "+5511999990000", "Clinify", data)
+	if !ok {
+		t.Fatalf("no %s message for the confirmation template", channel)
+	}
+
+	return msg
+}
\ No newline at end of file
@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Provider delivers a text notification through an SMS or WhatsApp gateway.
+type Provider interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// HTTPProvider posts the message as JSON to URL, with Token as a bearer token
+// when set. Any 2xx response counts as delivered. Point URL at a local stub
+// during development, or at a small adapter in front of the real gateway.
+type HTTPProvider struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+func (p *HTTPProvider) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s provider returned %s: %s", msg.Channel, resp.Status, bytes.TrimSpace(detail))
+	}
+
+	return nil
+}
+
+// MemoryProvider keeps every message in memory instead of sending it. Use it
+// in tests to inspect what would have been delivered.
+type MemoryProvider struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (p *MemoryProvider) Send(ctx context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = append(p.messages, msg)
+
+	return nil
+}
+
+func (p *MemoryProvider) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Message(nil), p.messages...)
+}
+
+// NewProviderFromEnv builds the provider for channel from <PREFIX>_PROVIDER
+// (http or memory), <PREFIX>_PROVIDER_URL and <PREFIX>_PROVIDER_TOKEN, where
+// PREFIX is SMS or WHATSAPP. It returns nil when the channel is disabled.
+func NewProviderFromEnv(channel string) (Provider, error) {
+	prefix := envPrefix(channel)
+
+	switch kind := os.Getenv(prefix + "_PROVIDER"); kind {
+	case "":
+		return nil, nil
+
+	case "http":
+		url := os.Getenv(prefix + "_PROVIDER_URL")
+		if url == "" {
+			return nil, fmt.Errorf("%s_PROVIDER_URL is required for the http provider", prefix)
+		}
+
+		return &HTTPProvider{
+			URL:    url,
+			Token:  os.Getenv(prefix + "_PROVIDER_TOKEN"),
+			Client: &http.Client{Timeout: 10 * time.Second},
+		}, nil
+
+	case "memory":
+		return &MemoryProvider{}, nil
+
+	default:
+		return nil, fmt.Errorf("invalid %s_PROVIDER %q, expected http or memory", prefix, kind)
+	}
+}
+
+// Enabled reports whether a provider is configured for channel. Email is
+// always enabled.
+func Enabled(channel string) bool {
+	if channel == ChannelEmail {
+		return true
+	}
+
+	return IsChannel(channel) && os.Getenv(envPrefix(channel)+"_PROVIDER") != ""
+}
+
+func envPrefix(channel string) string {
+	if channel == ChannelWhatsApp {
+		return "WHATSAPP"
+	}
+
+	return "SMS"
+}
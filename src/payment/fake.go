@@ -0,0 +1,224 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeclinedCardToken makes FakeGateway refuse a card charge.
+const DeclinedCardToken = "tok_declined"
+
+// FakeGateway is an in-process gateway for development and tests. Card
+// charges are approved unless the token is DeclinedCardToken. Boletos stay
+// pending until PayBoleto is called, or until BoletoPayAfter has passed when
+// the charge is polled. Charges live in memory and are lost on restart.
+type FakeGateway struct {
+	Secret         string
+	BoletoPayAfter time.Duration
+
+	mu      sync.Mutex
+	charges map[string]*fakeCharge
+}
+
+type fakeCharge struct {
+	Charge
+	createdAt time.Time
+}
+
+// wireEvent is the JSON body of FakeGateway webhooks.
+type wireEvent struct {
+	ID     string     `json:"id"`
+	Type   string     `json:"type"`
+	Charge wireCharge `json:"charge"`
+}
+
+type wireCharge struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	AmountCents   int64  `json:"amount_cents"`
+	CapturedCents int64  `json:"captured_cents"`
+	RefundedCents int64  `json:"refunded_cents"`
+	FailureReason string `json:"failure_reason,omitempty"`
+	BoletoURL     string `json:"boleto_url,omitempty"`
+	BoletoLine    string `json:"boleto_line,omitempty"`
+}
+
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{
+		Secret:         secret,
+		BoletoPayAfter: time.Minute,
+		charges:        make(map[string]*fakeCharge),
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreateCharge(ctx context.Context, req ChargeRequest) (Charge, error) {
+	if req.AmountCents <= 0 {
+		return Charge{}, fmt.Errorf("amount must be positive")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// ids must not repeat across restarts, as payments keep the charge id
+	charge := Charge{ID: "ch_fake_" + uuid.NewString(), AmountCents: req.AmountCents}
+
+	switch req.Method {
+	case MethodCard:
+		switch {
+		case req.CardToken == "":
+			return Charge{}, fmt.Errorf("card token is required")
+		case req.CardToken == DeclinedCardToken:
+			charge.Status = StatusFailed
+			charge.FailureReason = "card declined"
+		case req.Capture:
+			charge.Status = StatusPaid
+			charge.CapturedCents = req.AmountCents
+		default:
+			charge.Status = StatusAuthorized
+		}
+
+	case MethodBoleto:
+		charge.Status = StatusPending
+		charge.BoletoURL = "https://boleto.fake.local/" + charge.ID
+		charge.BoletoLine = fmt.Sprintf("00190.00009 01234.567890 12345.678901 1 %014d", req.AmountCents)
+
+	default:
+		return Charge{}, fmt.Errorf("unsupported payment method %q", req.Method)
+	}
+
+	g.charges[charge.ID] = &fakeCharge{Charge: charge, createdAt: time.Now()}
+
+	return charge, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, chargeID string, amountCents int64) (Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[chargeID]
+	if !ok {
+		return Charge{}, fmt.Errorf("charge %s not found", chargeID)
+	}
+
+	if charge.Status != StatusAuthorized {
+		return Charge{}, fmt.Errorf("charge %s is %s, not authorized", chargeID, charge.Status)
+	}
+
+	if amountCents == 0 {
+		amountCents = charge.AmountCents
+	}
+
+	if amountCents > charge.AmountCents {
+		return Charge{}, fmt.Errorf("capture exceeds the authorized amount")
+	}
+
+	charge.Status = StatusPaid
+	charge.CapturedCents = amountCents
+
+	return charge.Charge, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, chargeID string, amountCents int64) (Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[chargeID]
+	if !ok {
+		return Charge{}, fmt.Errorf("charge %s not found", chargeID)
+	}
+
+	if charge.Status == StatusAuthorized {
+		// refunding an authorization just releases it
+		charge.Status = StatusCancelled
+		return charge.Charge, nil
+	}
+
+	remaining := charge.CapturedCents - charge.RefundedCents
+	if charge.Status != StatusPaid && charge.Status != StatusRefunded || remaining == 0 {
+		return Charge{}, fmt.Errorf("charge %s has nothing to refund", chargeID)
+	}
+
+	if amountCents == 0 {
+		amountCents = remaining
+	}
+
+	if amountCents > remaining {
+		return Charge{}, fmt.Errorf("refund exceeds the captured amount")
+	}
+
+	charge.RefundedCents += amountCents
+	if charge.RefundedCents == charge.CapturedCents {
+		charge.Status = StatusRefunded
+	}
+
+	return charge.Charge, nil
+}
+
+func (g *FakeGateway) GetCharge(ctx context.Context, chargeID string) (Charge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[chargeID]
+	if !ok {
+		return Charge{}, fmt.Errorf("charge %s not found", chargeID)
+	}
+
+	if charge.Status == StatusPending && g.BoletoPayAfter > 0 && time.Since(charge.createdAt) >= g.BoletoPayAfter {
+		charge.Status = StatusPaid
+		charge.CapturedCents = charge.AmountCents
+	}
+
+	return charge.Charge, nil
+}
+
+// PayBoleto marks a boleto as paid and returns the webhook the gateway would
+// send about it, with its signature header value.
+func (g *FakeGateway) PayBoleto(chargeID string) ([]byte, string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, ok := g.charges[chargeID]
+	if !ok || charge.Status != StatusPending {
+		return nil, "", fmt.Errorf("charge %s is not a pending boleto", chargeID)
+	}
+
+	charge.Status = StatusPaid
+	charge.CapturedCents = charge.AmountCents
+
+	payload, err := json.Marshal(wireEvent{
+		ID:     "evt_fake_" + uuid.NewString(),
+		Type:   "charge.paid",
+		Charge: wireCharge(charge.Charge),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, Sign(g.Secret, payload, time.Now()), nil
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, signature string) (Event, error) {
+	if err := VerifySignature(g.Secret, payload, signature, time.Now()); err != nil {
+		return Event{}, err
+	}
+
+	var event wireEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, fmt.Errorf("invalid webhook body: %w", err)
+	}
+
+	if event.ID == "" || event.Charge.ID == "" {
+		return Event{}, fmt.Errorf("webhook without event or charge id")
+	}
+
+	return Event{ID: event.ID, Type: event.Type, Charge: Charge(event.Charge)}, nil
+}
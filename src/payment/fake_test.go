@@ -0,0 +1,116 @@
+package payment
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFakeGatewayCard(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway("whsec_test")
+
+	declined, err := g.CreateCharge(ctx, ChargeRequest{Method: MethodCard, AmountCents: 15000, CardToken: DeclinedCardToken, Capture: true})
+	if err != nil {
+		t.Fatalf("CreateCharge() error = %v", err)
+	}
+
+	if declined.Status != StatusFailed || declined.FailureReason == "" {
+		t.Errorf("declined card charge = %+v", declined)
+	}
+
+	charge, err := g.CreateCharge(ctx, ChargeRequest{Method: MethodCard, AmountCents: 15000, CardToken: "tok_ok"})
+	if err != nil {
+		t.Fatalf("CreateCharge() error = %v", err)
+	}
+
+	if charge.Status != StatusAuthorized {
+		t.Fatalf("status = %s, want %s", charge.Status, StatusAuthorized)
+	}
+
+	if charge.ID == declined.ID {
+		t.Errorf("charges share the id %s", charge.ID)
+	}
+
+	if _, err := g.Capture(ctx, charge.ID, 20000); err == nil {
+		t.Error("captured more than authorized")
+	}
+
+	charge, err = g.Capture(ctx, charge.ID, 0)
+	if err != nil || charge.Status != StatusPaid || charge.CapturedCents != 15000 {
+		t.Fatalf("Capture() = %+v, %v", charge, err)
+	}
+
+	charge, err = g.Refund(ctx, charge.ID, 5000)
+	if err != nil || charge.Status != StatusPaid || charge.RefundedCents != 5000 {
+		t.Fatalf("partial Refund() = %+v, %v", charge, err)
+	}
+
+	charge, err = g.Refund(ctx, charge.ID, 0)
+	if err != nil || charge.Status != StatusRefunded || charge.RefundedCents != 15000 {
+		t.Fatalf("Refund() = %+v, %v", charge, err)
+	}
+
+	if _, err := g.Refund(ctx, charge.ID, 0); err == nil {
+		t.Error("refunded a charge twice")
+	}
+}
+
+func TestFakeGatewayBoletoWebhook(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway("whsec_test")
+	g.BoletoPayAfter = 0
+
+	charge, err := g.CreateCharge(ctx, ChargeRequest{Method: MethodBoleto, AmountCents: 20000})
+	if err != nil {
+		t.Fatalf("CreateCharge() error = %v", err)
+	}
+
+	if charge.Status != StatusPending || charge.BoletoURL == "" || charge.BoletoLine == "" {
+		t.Fatalf("boleto charge = %+v", charge)
+	}
+
+	payload, signature, err := g.PayBoleto(charge.ID)
+	if err != nil {
+		t.Fatalf("PayBoleto() error = %v", err)
+	}
+
+	event, err := g.ParseWebhook(payload, signature)
+	if err != nil {
+		t.Fatalf("ParseWebhook() error = %v", err)
+	}
+
+	if event.Type != "charge.paid" || event.Charge.ID != charge.ID || event.Charge.Status != StatusPaid || event.Charge.CapturedCents != 20000 {
+		t.Errorf("event = %+v", event)
+	}
+
+	if _, err := g.ParseWebhook(payload, "t=0,v1=00"); err == nil {
+		t.Error("ParseWebhook() accepted a bad signature")
+	}
+
+	if _, _, err := g.PayBoleto(charge.ID); err == nil {
+		t.Error("paid the same boleto twice")
+	}
+}
+
+func TestFakeGatewayUniqueIDs(t *testing.T) {
+	ctx := context.Background()
+	seen := make(map[string]bool)
+
+	// a new gateway stands for a restart, which must not reuse ids
+	for range 2 {
+		g := NewFakeGateway("whsec_test")
+
+		for range 3 {
+			charge, err := g.CreateCharge(ctx, ChargeRequest{Method: MethodBoleto, AmountCents: 100})
+			if err != nil {
+				t.Fatalf("CreateCharge() error = %v", err)
+			}
+
+			if seen[charge.ID] {
+				t.Fatalf("id %s was reused", charge.ID)
+			}
+
+			seen[charge.ID] = true
+		}
+	}
+}
\ No newline at end of file
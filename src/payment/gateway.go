@@ -0,0 +1,96 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	MethodCard   = "card"
+	MethodBoleto = "boleto"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusAuthorized = "authorized"
+	StatusPaid       = "paid"
+	StatusFailed     = "failed"
+	StatusCancelled  = "cancelled"
+	StatusRefunded   = "refunded"
+)
+
+// Customer is who pays. Document is the payer's CPF, which boleto issuers
+// usually require.
+type Customer struct {
+	Name     string
+	Email    string
+	Document string
+}
+
+// ChargeRequest asks the gateway for a new charge. Reference is our id for
+// the payment, so the charge can be traced back. CardToken is the token the
+// gateway's client-side library returned for the card; raw card numbers
+// never reach this service. Card charges with Capture false are only
+// authorized and must be captured later.
+type ChargeRequest struct {
+	Reference   string
+	Method      string
+	AmountCents int64
+	Description string
+	Customer    Customer
+	CardToken   string
+	Capture     bool
+	DueDate     time.Time
+}
+
+// Charge is the gateway's view of a charge. CapturedCents and RefundedCents
+// are totals, not deltas.
+type Charge struct {
+	ID            string
+	Status        string
+	AmountCents   int64
+	CapturedCents int64
+	RefundedCents int64
+	FailureReason string
+	BoletoURL     string
+	BoletoLine    string
+}
+
+// Event is a verified webhook notification about a charge.
+type Event struct {
+	ID     string
+	Type   string
+	Charge Charge
+}
+
+// Gateway is a card and boleto payment provider. Capture and Refund take an
+// amount in cents; 0 means the whole remaining amount.
+type Gateway interface {
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (Charge, error)
+	Capture(ctx context.Context, chargeID string, amountCents int64) (Charge, error)
+	Refund(ctx context.Context, chargeID string, amountCents int64) (Charge, error)
+	GetCharge(ctx context.Context, chargeID string) (Charge, error)
+	// ParseWebhook checks the signature of a webhook call and decodes it.
+	ParseWebhook(payload []byte, signature string) (Event, error)
+}
+
+// NewGatewayFromEnv returns the gateway named by PAYMENT_GATEWAY, or nil when
+// it is empty and card and boleto payments are disabled.
+func NewGatewayFromEnv() (Gateway, error) {
+	switch os.Getenv("PAYMENT_GATEWAY") {
+	case "":
+		return nil, nil
+	case "fake":
+		secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+		if secret == "" {
+			return nil, fmt.Errorf("PAYMENT_WEBHOOK_SECRET is required")
+		}
+
+		return NewFakeGateway(secret), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_GATEWAY %q", os.Getenv("PAYMENT_GATEWAY"))
+	}
+}
@@ -0,0 +1,72 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries webhook signatures, in the form "t=<unix>,v1=<hex>".
+const SignatureHeader = "X-Payment-Signature"
+
+// signatureTolerance bounds how old a signed webhook may be, so captured
+// calls cannot be replayed later.
+const signatureTolerance = 5 * time.Minute
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the signature header value for payload at t: an HMAC-SHA256
+// of "<unix>.<payload>" keyed with secret.
+func Sign(secret string, payload []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, mac(secret, timestamp, payload))
+}
+
+// VerifySignature checks a signature made by Sign within the tolerance
+// around now.
+func VerifySignature(secret string, payload []byte, header string, now time.Time) error {
+	var timestamp, signature string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidSignature
+	}
+
+	age := now.Sub(time.Unix(unix, 0))
+	if age > signatureTolerance || age < -signatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	expected := mac(secret, timestamp, payload)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func mac(secret, timestamp string, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(payload)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
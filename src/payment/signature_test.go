@@ -0,0 +1,47 @@
+package payment
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := "whsec_test"
+	payload := []byte(`{"id":"evt_1","type":"charge.paid"}`)
+	signedAt := time.Unix(1741770000, 0)
+	header := Sign(secret, payload, signedAt)
+
+	_, signature, _ := strings.Cut(header, ",")
+
+	tests := []struct {
+		name    string
+		secret  string
+		payload []byte
+		header  string
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", secret, payload, header, signedAt.Add(time.Minute), false},
+		{"valid with clock skew", secret, payload, header, signedAt.Add(-time.Minute), false},
+		{"wrong secret", "other", payload, header, signedAt, true},
+		{"tampered payload", secret, []byte(`{"id":"evt_1","type":"charge.refunded"}`), header, signedAt, true},
+		{"bad signature", secret, payload, "t=1741770000,v1=00ff", signedAt, true},
+		{"old timestamp", secret, payload, header, signedAt.Add(signatureTolerance + time.Second), true},
+		{"timestamp in the future", secret, payload, header, signedAt.Add(-signatureTolerance - time.Second), true},
+		// a captured call replayed with a fresh timestamp keeps the old mac
+		{"replay with a new timestamp", secret, payload, "t=1741770600," + signature, signedAt.Add(10 * time.Minute), true},
+		{"missing signature", secret, payload, "t=1741770000", signedAt, true},
+		{"missing timestamp", secret, payload, signature, signedAt, true},
+		{"empty header", secret, payload, "", signedAt, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.payload, tt.header, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
\ No newline at end of file
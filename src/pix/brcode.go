@@ -0,0 +1,198 @@
+package pix
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Payload is a Pix BR Code, the EMV QR Code payload defined by the Banco
+// Central do Brasil. A static payload carries the receiver's Pix key and is
+// read straight from the QR code; a dynamic one carries the Location of a
+// charge hosted by a payment provider.
+type Payload struct {
+	Key          string
+	Location     string
+	Description  string
+	MerchantName string
+	MerchantCity string
+	AmountCents  int64
+	TxID         string
+}
+
+const pixGUI = "br.gov.bcb.pix"
+
+// Encode returns the "copia e cola" string, checksum included.
+func (p Payload) Encode() (string, error) {
+	if (p.Key == "") == (p.Location == "") {
+		return "", fmt.Errorf("a pix payload needs either a key or a location")
+	}
+
+	name := sanitize(p.MerchantName, 25)
+	city := sanitize(p.MerchantCity, 15)
+
+	if name == "" || city == "" {
+		return "", fmt.Errorf("merchant name and city are required")
+	}
+
+	txid := p.TxID
+	if txid == "" {
+		txid = "***"
+	}
+
+	if !validTxID(txid) {
+		return "", fmt.Errorf("invalid txid %q", txid)
+	}
+
+	var account emv
+
+	account.field("00", pixGUI)
+	if p.Location != "" {
+		account.field("25", strings.TrimPrefix(p.Location, "https://"))
+	} else {
+		account.field("01", p.Key)
+
+		// the description gets whatever room the key leaves in the template,
+		// if any
+		room := maxFieldLen - account.b.Len() - 4
+		if description := sanitize(p.Description, min(40, room)); description != "" {
+			account.field("02", description)
+		}
+	}
+
+	if account.err != nil {
+		return "", account.err
+	}
+
+	var code emv
+
+	code.field("00", "01")
+
+	// 12 tells the payer app the code is meant to be paid once
+	if p.Location != "" {
+		code.field("01", "12")
+	}
+
+	code.field("26", account.b.String())
+	code.field("52", "0000")
+	code.field("53", "986")
+
+	if p.AmountCents > 0 {
+		code.field("54", fmt.Sprintf("%d.%02d", p.AmountCents/100, p.AmountCents%100))
+	}
+
+	code.field("58", "BR")
+	code.field("59", name)
+	code.field("60", city)
+
+	var additional emv
+
+	additional.field("05", txid)
+	code.field("62", additional.b.String())
+
+	if code.err != nil {
+		return "", code.err
+	}
+
+	// the checksum adds 8 characters and the whole payload is capped at 512
+	if code.b.Len()+8 > 512 {
+		return "", fmt.Errorf("pix payload too long")
+	}
+
+	code.b.WriteString("6304")
+
+	payload := code.b.String()
+
+	return payload + fmt.Sprintf("%04X", CRC16(payload)), nil
+}
+
+// CRC16 is the CRC-16/CCITT-FALSE checksum closing every BR Code.
+func CRC16(data string) uint16 {
+	crc := uint16(0xFFFF)
+
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+// maxFieldLen is the most an EMV value can hold, as its length is written
+// with two digits.
+const maxFieldLen = 99
+
+// emv writes EMV fields one after the other, keeping the first error.
+type emv struct {
+	b   strings.Builder
+	err error
+}
+
+func (e *emv) field(id, value string) {
+	if e.err != nil {
+		return
+	}
+
+	if len(value) > maxFieldLen {
+		e.err = fmt.Errorf("pix field %s is %d bytes long, at most %d fit", id, len(value), maxFieldLen)
+		return
+	}
+
+	fmt.Fprintf(&e.b, "%s%02d%s", id, len(value), value)
+}
+
+// sanitize keeps the ASCII subset payer apps display reliably, dropping
+// accents, and cuts the text to max characters.
+func sanitize(s string, max int) string {
+	var b strings.Builder
+
+	for _, r := range norm.NFD.String(strings.TrimSpace(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		if r < 0x20 || r > 0x7E {
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	out := strings.TrimSpace(b.String())
+	if max <= 0 {
+		return ""
+	}
+
+	if len(out) > max {
+		out = strings.TrimSpace(out[:max])
+	}
+
+	return out
+}
+
+func validTxID(txid string) bool {
+	if txid == "***" {
+		return true
+	}
+
+	if len(txid) > 35 {
+		return false
+	}
+
+	for _, r := range txid {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,108 @@
+package pix
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEncodeReferencePayload(t *testing.T) {
+	// example published in the BR Code manual of the Banco Central do Brasil
+	want := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+
+	got, err := Payload{
+		Key: "123e4567-e12b-12d1-a456-426655440000",
+		MerchantName: "Fulano de Tal",
+		MerchantCity: "BRASILIA",
+	}.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	if got != want {
+		t.Errorf("Encode() = %q, want %q", got, want)
+	}
+}
+
+func TestEncodeFitsMerchantAccount(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		description string
+		wantDesc    string
+	}{
+		{"short key keeps description", "12345678909", "Sessao de psicoterapia", "Sessao de psicoterapia"},
+		{"email key cuts description", strings.Repeat("a", 50) + "@clinica.com.br", strings.Repeat("d", 38), strings.Repeat("d", 8)},
+		{"longest email key drops description", strings.Repeat("a", 62) + "@clinica.com.br", strings.Repeat("d", 38), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Payload{Key: tt.key, Description: tt.description, MerchantName: "Clinica", MerchantCity: "SAO PAULO"}.Encode()
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			account := merchantAccount(t, got)
+
+			if size := len(account); size > maxFieldLen {
+				t.Fatalf("merchant account is %d bytes long", size)
+			}
+
+			wantAccount := "0014" + pixGUI + "01" + twoDigits(len(tt.key)) + tt.key
+			if tt.wantDesc != "" {
+				wantAccount += "02" + twoDigits(len(tt.wantDesc)) + tt.wantDesc
+			}
+
+			if account != wantAccount {
+				t.Errorf("merchant account = %q, want %q", account, wantAccount)
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsOverflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload Payload
+	}{
+		{"key over the template", Payload{Key: strings.Repeat("k", 78), MerchantName: "Clinica", MerchantCity: "SAO PAULO"}},
+		{"location over the template", Payload{Location: "https://pix.example.com/qr/v2/" + strings.Repeat("l", 70), MerchantName: "Clinica", MerchantCity: "SAO PAULO"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.payload.Encode(); err == nil {
+				t.Errorf("Encode() = %q, want an error", got)
+			}
+		})
+	}
+}
+
+// merchantAccount returns the value of field 26, which follows the format
+// indicator and, for dynamic codes, the point of initiation.
+func merchantAccount(t *testing.T, code string) string {
+	t.Helper()
+
+	rest := strings.TrimPrefix(code, "000201")
+	rest = strings.TrimPrefix(rest, "010212")
+
+	if !strings.HasPrefix(rest, "26") {
+		t.Fatalf("field 26 not found in %q", code)
+	}
+
+	size, err := strconv.Atoi(rest[2:4])
+	if err != nil {
+		t.Fatalf("invalid length in %q", code)
+	}
+
+	return rest[4 : 4+size]
+}
+
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+
+	return strconv.Itoa(n)
+}
\ No newline at end of file
@@ -0,0 +1,115 @@
+package pix
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	KeyCPF    = "cpf"
+	KeyCNPJ   = "cnpj"
+	KeyEmail  = "email"
+	KeyPhone  = "phone"
+	KeyRandom = "random"
+)
+
+// NormalizeKey checks a Pix key and returns it in the format the DICT stores
+// it, along with its type: digits only for CPF and CNPJ, +55 for phones and
+// lower case for e-mails and random keys.
+func NormalizeKey(key string) (string, string, error) {
+	key = strings.TrimSpace(key)
+
+	if _, err := uuid.Parse(key); err == nil && len(key) == 36 {
+		return strings.ToLower(key), KeyRandom, nil
+	}
+
+	if strings.Contains(key, "@") {
+		addr, err := mail.ParseAddress(key)
+		if err != nil || addr.Address != key || len(key) > 77 {
+			return "", "", fmt.Errorf("invalid pix key")
+		}
+
+		return strings.ToLower(key), KeyEmail, nil
+	}
+
+	if strings.HasPrefix(key, "+") {
+		digits := onlyDigits(key)
+		if !strings.HasPrefix(digits, "55") || len(digits) < 12 || len(digits) > 13 {
+			return "", "", fmt.Errorf("invalid pix key")
+		}
+
+		return "+" + digits, KeyPhone, nil
+	}
+
+	digits := onlyDigits(key)
+
+	switch {
+	case len(digits) == 11 && validCPF(digits):
+		return digits, KeyCPF, nil
+	case len(digits) == 14 && validCNPJ(digits):
+		return digits, KeyCNPJ, nil
+	}
+
+	return "", "", fmt.Errorf("invalid pix key")
+}
+
+func onlyDigits(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func validCPF(cpf string) bool {
+	if strings.Count(cpf, cpf[:1]) == len(cpf) {
+		return false
+	}
+
+	return checkDigit(cpf[:9], 10) == cpf[9] && checkDigit(cpf[:10], 11) == cpf[10]
+}
+
+func validCNPJ(cnpj string) bool {
+	if strings.Count(cnpj, cnpj[:1]) == len(cnpj) {
+		return false
+	}
+
+	weights := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+
+	return cnpjDigit(cnpj[:12], weights[1:]) == cnpj[12] && cnpjDigit(cnpj[:13], weights) == cnpj[13]
+}
+
+func checkDigit(digits string, weight int) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[i]-'0') * (weight - i)
+	}
+
+	rest := sum * 10 % 11
+	if rest == 10 {
+		rest = 0
+	}
+
+	return byte('0' + rest)
+}
+
+func cnpjDigit(digits string, weights []int) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[i]-'0') * weights[i]
+	}
+
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+
+	return byte('0' + 11 - rest)
+}
@@ -0,0 +1,136 @@
+package pix
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Charge is a payment request registered with a provider.
+type Charge struct {
+	TxID        string
+	Key         string
+	AmountCents int64
+	Description string
+}
+
+// Payment is a received Pix, as reported by a provider webhook.
+type Payment struct {
+	EndToEndID  string
+	TxID        string
+	AmountCents int64
+	PaidAt      time.Time
+}
+
+// Provider is a PSP hosting dynamic charges and reporting payments back
+// through a webhook. Without a provider the clinic uses static codes and
+// marks payments by hand.
+type Provider interface {
+	// CreateCharge registers the charge and returns the location of its
+	// dynamic payload.
+	CreateCharge(ctx context.Context, charge Charge) (string, error)
+	// ParseWebhook authenticates a webhook call and returns the payments it
+	// reports.
+	ParseWebhook(r *http.Request) ([]Payment, error)
+}
+
+// ErrUnauthorized is returned by ParseWebhook for calls that fail
+// authentication.
+var ErrUnauthorized = fmt.Errorf("unauthorized webhook call")
+
+// StubProvider stands in for a PSP during development. Charges get a fake
+// location and payments are reported by posting the Banco Central webhook
+// body to the webhook endpoint with Token as a bearer token or ?token=.
+type StubProvider struct {
+	LocationURL string
+	Token       string
+}
+
+func (p *StubProvider) CreateCharge(ctx context.Context, charge Charge) (string, error) {
+	return strings.TrimRight(p.LocationURL, "/") + "/" + charge.TxID, nil
+}
+
+func (p *StubProvider) ParseWebhook(r *http.Request) ([]Payment, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+
+	if p.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.Token)) != 1 {
+		return nil, ErrUnauthorized
+	}
+
+	return ParseWebhookBody(io.LimitReader(r.Body, 1<<20))
+}
+
+// ParseWebhookBody reads the payload of the Pix API webhook defined by the
+// Banco Central, which PSPs post for every received payment:
+//
+//	{"pix": [{"endToEndId": "E...", "txid": "...", "valor": "150.00", "horario": "2025-01-10T14:00:00Z"}]}
+func ParseWebhookBody(body io.Reader) ([]Payment, error) {
+	var notification struct {
+		Pix []struct {
+			EndToEndID string `json:"endToEndId"`
+			TxID       string `json:"txid"`
+			Valor      string `json:"valor"`
+			Horario    string `json:"horario"`
+		} `json:"pix"`
+	}
+
+	if err := json.NewDecoder(body).Decode(&notification); err != nil {
+		return nil, fmt.Errorf("invalid webhook body: %w", err)
+	}
+
+	payments := make([]Payment, 0, len(notification.Pix))
+
+	for _, pix := range notification.Pix {
+		if pix.EndToEndID == "" || pix.TxID == "" {
+			return nil, fmt.Errorf("webhook payment without endToEndId or txid")
+		}
+
+		amount, err := strconv.ParseFloat(pix.Valor, 64)
+		if err != nil || amount <= 0 {
+			return nil, fmt.Errorf("invalid webhook amount %q", pix.Valor)
+		}
+
+		paidAt, err := time.Parse(time.RFC3339, pix.Horario)
+		if err != nil {
+			paidAt = time.Now()
+		}
+
+		payments = append(payments, Payment{
+			EndToEndID:  pix.EndToEndID,
+			TxID:        pix.TxID,
+			AmountCents: int64(math.Round(amount * 100)),
+			PaidAt:      paidAt,
+		})
+	}
+
+	return payments, nil
+}
+
+// NewProviderFromEnv returns the provider named by PIX_PROVIDER, or nil when
+// it is empty and only static codes are used.
+func NewProviderFromEnv() (Provider, error) {
+	switch os.Getenv("PIX_PROVIDER") {
+	case "":
+		return nil, nil
+	case "stub":
+		location := os.Getenv("PIX_STUB_LOCATION_URL")
+		if location == "" {
+			location = "pix.clinify.local/cob"
+		}
+
+		return &StubProvider{LocationURL: location, Token: os.Getenv("PIX_WEBHOOK_TOKEN")}, nil
+	default:
+		return nil, fmt.Errorf("unknown PIX_PROVIDER %q", os.Getenv("PIX_PROVIDER"))
+	}
+}
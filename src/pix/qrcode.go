@@ -0,0 +1,11 @@
+package pix
+
+import (
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCodePNG renders payload as a size x size PNG. Medium error correction
+// keeps the code small enough to scan from a phone screen.
+func QRCodePNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
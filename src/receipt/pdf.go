@@ -0,0 +1,120 @@
+// Package receipt renders receipts (recibos) for therapy sessions as PDF.
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// Issuer is the psychologist signing the receipt.
+type Issuer struct {
+	Name    string
+	CRP     string
+	CPF     string
+	Address string
+}
+
+type Payer struct {
+	Name string
+	CPF  string
+}
+
+type Item struct {
+	Date        time.Time
+	Description string
+	AmountCents int64
+}
+
+// Receipt holds everything printed on a receipt. Reference completes the
+// sentence "referente a ...".
+type Receipt struct {
+	Number     int
+	IssuedAt   time.Time
+	Issuer     Issuer
+	Payer      Payer
+	Reference  string
+	Items      []Item
+	TotalCents int64
+}
+
+// Filename is the name the receipt is downloaded or attached as.
+func (r Receipt) Filename() string {
+	return fmt.Sprintf("recibo-%04d.pdf", r.Number)
+}
+
+// PDF renders the receipt on an A4 page, with the items on following pages
+// if they do not fit.
+func (r Receipt) PDF() ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetTitle(fmt.Sprintf("Recibo %04d", r.Number), true)
+	pdf.SetAuthor(r.Issuer.Name, true)
+	pdf.AddPage()
+
+	// the core fonts use cp1252, which covers Portuguese
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(120, 10, tr("RECIBO"), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 10, tr(fmt.Sprintf("Nº %04d", r.Number)), "", 1, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, tr("Valor: "+utils.FormatBRL(r.TotalCents)), "", 1, "R", false, 0, "")
+	pdf.Ln(6)
+
+	payer := r.Payer.Name
+	if r.Payer.CPF != "" {
+		payer += ", CPF " + utils.FormatCPF(r.Payer.CPF)
+	}
+
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.MultiCell(0, 6, tr(fmt.Sprintf("Recebi de %s, a quantia de %s, referente a %s, conforme discriminado abaixo.",
+		payer, utils.FormatBRL(r.TotalCents), r.Reference)), "", "J", false)
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.CellFormat(30, 8, tr("Data"), "B", 0, "L", true, 0, "")
+	pdf.CellFormat(110, 8, tr("Descrição"), "B", 0, "L", true, 0, "")
+	pdf.CellFormat(0, 8, tr("Valor"), "B", 1, "R", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, item := range r.Items {
+		pdf.CellFormat(30, 7, item.Date.Format("02/01/2006"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(110, 7, tr(item.Description), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, tr(utils.FormatBRL(item.AmountCents)), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(140, 8, tr("Total"), "T", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 8, tr(utils.FormatBRL(r.TotalCents)), "T", 1, "R", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, tr("Emitido em "+r.IssuedAt.Format("02/01/2006")), "", 1, "L", false, 0, "")
+	pdf.Ln(20)
+
+	pdf.CellFormat(90, 0, "", "T", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 6, tr(r.Issuer.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 5, tr("Psicólogo(a) - CRP "+r.Issuer.CRP), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr("CPF "+utils.FormatCPF(r.Issuer.CPF)), "", 1, "L", false, 0, "")
+	if r.Issuer.Address != "" {
+		pdf.MultiCell(0, 5, tr(r.Issuer.Address), "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
\ No newline at end of file
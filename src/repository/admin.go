@@ -54,14 +54,27 @@ func (r *AdminRepository) FindAdminIDBySlug(ctx context.Context, slug string) (u
 	return id, nil
 }
 
-func (r *AdminRepository) CreateAppointment(ctx context.Context, input dtos.AppointmentInput, parsedDate, start, end time.Time, clientID uuid.UUID) (uuid.UUID, error) {
-	query := `INSERT INTO appointments (client_id, patient_id, date, start_time, end_time, status)
-	VALUES ($1, $2, $3, $4, $5, 'scheduled')
+// LockDay makes the other bookings of the psychologist on date wait until tx
+// ends, so a slot checked as free is still free when it is taken.
+func (r *AdminRepository) LockDay(ctx context.Context, db DBTX, clientID uuid.UUID, date time.Time) error {
+	query := `SELECT pg_advisory_xact_lock(hashtext($1), hashtext($2))`
+
+	if _, err := db.ExecContext(ctx, query, clientID.String(), date.Format("2006-01-02")); err != nil {
+		utils.LogError("lockDay admin repository (SELECT error)", err)
+		return utils.InternalServerError("error booking appointment")
+	}
+
+	return nil
+}
+
+func (r *AdminRepository) CreateAppointment(ctx context.Context, db DBTX, input dtos.AppointmentInput, parsedDate, start, end time.Time, locationID uuid.NullUUID, clientID uuid.UUID) (uuid.UUID, error) {
+	query := `INSERT INTO appointments (client_id, patient_id, date, start_time, end_time, status, location_id, modality, video_url)
+	VALUES ($1, $2, $3, $4, $5, 'scheduled', $6, $7, $8)
 	RETURNING id;`
 
 	var id uuid.UUID
 
-	err := DB.QueryRowContext(
+	err := db.QueryRowContext(
 		ctx,
 		query,
 		clientID,
@@ -69,6 +82,9 @@ func (r *AdminRepository) CreateAppointment(ctx context.Context, input dtos.Appo
 		parsedDate,
 		start,
 		end,
+		locationID,
+		input.Modality,
+		input.VideoURL,
 	).Scan(&id)
 	if err != nil {
 		utils.LogError("createAppointment repository (error in INSERT)", err)
@@ -78,8 +94,21 @@ func (r *AdminRepository) CreateAppointment(ctx context.Context, input dtos.Appo
 	return id, nil
 }
 
+func (r *AdminRepository) UpdateAppointmentVideoURL(ctx context.Context, db DBTX, appointmentID uuid.UUID, videoURL string) error {
+	query := `UPDATE appointments SET video_url = $1 WHERE id = $2`
+
+	_, err := db.ExecContext(ctx, query, videoURL, appointmentID)
+	if err != nil {
+		utils.LogError("updateAppointmentVideoURL repository (error in UPDATE)", err)
+		return utils.InternalServerError("error saving video link")
+	}
+
+	return nil
+}
+
 func (r *AdminRepository) GetAllAppointments(ctx context.Context, adminID uuid.UUID, page, limit int) ([]dtos.AppointmentOutput, int, error) {
-	query := `SELECT a.id, a.patient_id, p.full_name, a.date, a.start_time, a.end_time, a.status
+	query := `SELECT a.id, a.patient_id, p.full_name, a.date, a.start_time, a.end_time, a.status, a.location_id, a.modality, a.video_url,
+	a.late_cancelled, a.fee_cents, a.fee_waived_at IS NOT NULL, a.authorization_id
 	FROM appointments a
 	JOIN patients p ON p.id = a.patient_id
 	WHERE a.client_id = $1
@@ -114,9 +143,16 @@ func (r *AdminRepository) GetAllAppointments(ctx context.Context, adminID uuid.U
 			startTime time.Time
 			endTime time.Time
 			status string
+			locationID uuid.NullUUID
+			modality string
+			videoURL string
+			lateCancelled bool
+			feeCents int64
+			feeWaived bool
+			authorizationID uuid.NullUUID
 		)
 
-		err := rows.Scan(&id, &patientID, &fullName, &date, &startTime, &endTime, &status)
+		err := rows.Scan(&id, &patientID, &fullName, &date, &startTime, &endTime, &status, &locationID, &modality, &videoURL, &lateCancelled, &feeCents, &feeWaived, &authorizationID)
 		if err != nil {
 			utils.LogError("getAppointments repository (scan error)", err)
 			return nil, 0, utils.InternalServerError("error fetching appointments")
@@ -130,6 +166,13 @@ func (r *AdminRepository) GetAllAppointments(ctx context.Context, adminID uuid.U
 			StartTime: startTime.Format("15:04"),
 			EndTime: endTime.Format("15:04"),
 			Status: status,
+			LocationID: utils.NullUUIDPtr(locationID),
+			Modality: modality,
+			VideoURL: videoURL,
+			LateCancelled: lateCancelled,
+			FeeCents: feeCents,
+			FeeWaived: feeWaived,
+			AuthorizationID: utils.NullUUIDPtr(authorizationID),
 		})
 	}
 
@@ -137,7 +180,8 @@ func (r *AdminRepository) GetAllAppointments(ctx context.Context, adminID uuid.U
 }
 
 func (r *AdminRepository) GetAppointmentsByDate(ctx context.Context, adminID uuid.UUID, date string) ([]dtos.AppointmentOutput, error) {
-	query := `SELECT a.id, a.patient_id, p.full_name, a.date, a.start_time, a.end_time, a.status
+	query := `SELECT a.id, a.patient_id, p.full_name, a.date, a.start_time, a.end_time, a.status, a.location_id, a.modality, a.video_url,
+	a.late_cancelled, a.fee_cents, a.fee_waived_at IS NOT NULL, a.authorization_id
 	FROM appointments a
 	JOIN patients p ON p.id = a.patient_id
 	WHERE a.client_id = $1 AND a.date = $2 AND a.status != 'cancelled'
@@ -161,6 +205,13 @@ func (r *AdminRepository) GetAppointmentsByDate(ctx context.Context, adminID uui
 			startTime time.Time
 			endTime time.Time
 			status string
+			locationID uuid.NullUUID
+			modality string
+			videoURL string
+			lateCancelled bool
+			feeCents int64
+			feeWaived bool
+			authorizationID uuid.NullUUID
 		)
 
 		err := rows.Scan(
@@ -171,6 +222,13 @@ func (r *AdminRepository) GetAppointmentsByDate(ctx context.Context, adminID uui
 			&startTime,
 			&endTime,
 			&status,
+			&locationID,
+			&modality,
+			&videoURL,
+			&lateCancelled,
+			&feeCents,
+			&feeWaived,
+			&authorizationID,
 		)
 		if err != nil {
 			utils.LogError("getAppointmentsByDate repository (scan error)", err)
@@ -185,6 +243,13 @@ func (r *AdminRepository) GetAppointmentsByDate(ctx context.Context, adminID uui
 			StartTime: startTime.Format("15:04"),
 			EndTime: endTime.Format("15:04"),
 			Status: status,
+			LocationID: utils.NullUUIDPtr(locationID),
+			Modality: modality,
+			VideoURL: videoURL,
+			LateCancelled: lateCancelled,
+			FeeCents: feeCents,
+			FeeWaived: feeWaived,
+			AuthorizationID: utils.NullUUIDPtr(authorizationID),
 		})
 	}
 
@@ -196,6 +261,82 @@ func (r *AdminRepository) GetAppointmentsByDate(ctx context.Context, adminID uui
 	return appointments, nil
 }
 
+// GetBookingHolds returns the start times of date held by booking requests
+// still waiting for the psychologist's answer.
+func (r *AdminRepository) GetBookingHolds(ctx context.Context, adminID uuid.UUID, date string) ([]string, error) {
+	query := `SELECT start_time FROM booking_requests
+	WHERE client_id = $1 AND date = $2 AND status = 'pending' AND expires_at > NOW()`
+
+	rows, err := DB.QueryContext(ctx, query, adminID, date)
+	if err != nil {
+		utils.LogError("getBookingHolds repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting booking requests")
+	}
+	defer rows.Close()
+
+	holds := make([]string, 0)
+
+	for rows.Next() {
+		var startTime time.Time
+
+		if err := rows.Scan(&startTime); err != nil {
+			utils.LogError("getBookingHolds repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching booking requests")
+		}
+
+		holds = append(holds, startTime.Format("15:04"))
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getBookingHolds repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating booking requests")
+	}
+
+	return holds, nil
+}
+
+func (r *AdminRepository) GetBookingRules(ctx context.Context, adminID uuid.UUID) (dtos.BookingRulesOutput, error) {
+	query := `SELECT booking_min_notice_hours, booking_max_horizon_days, booking_max_per_day, booking_max_per_week, booking_max_open_per_patient
+	FROM clients WHERE id = $1`
+
+	var rules dtos.BookingRulesOutput
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(
+		&rules.MinNoticeHours,
+		&rules.MaxHorizonDays,
+		&rules.MaxPerDay,
+		&rules.MaxPerWeek,
+		&rules.MaxOpenPerPatient,
+	)
+	if err != nil {
+		utils.LogError("getBookingRules repository (SELECT error)", err)
+		return dtos.BookingRulesOutput{}, utils.InternalServerError("error getting booking rules")
+	}
+
+	return rules, nil
+}
+
+// CountBookings returns how many sessions are booked or held by a pending
+// request between from and to, both inclusive.
+func (r *AdminRepository) CountBookings(ctx context.Context, adminID uuid.UUID, from, to string) (int, error) {
+	query := `SELECT
+		(SELECT COUNT(*) FROM appointments
+		WHERE client_id = $1 AND date BETWEEN $2 AND $3 AND status != 'cancelled')
+		+
+		(SELECT COUNT(*) FROM booking_requests
+		WHERE client_id = $1 AND date BETWEEN $2 AND $3 AND status = 'pending' AND expires_at > NOW())`
+
+	var total int
+
+	err := DB.QueryRowContext(ctx, query, adminID, from, to).Scan(&total)
+	if err != nil {
+		utils.LogError("countBookings repository (SELECT error)", err)
+		return 0, utils.InternalServerError("error counting bookings")
+	}
+
+	return total, nil
+}
+
 func (r *AdminRepository) GetPatients(ctx context.Context, adminID uuid.UUID, page, limit int) ([]dtos.PatientOutput, int, error) {
 	query := `SELECT id, full_name, email, phone, birth_date FROM patients
 	WHERE client_id = $1
@@ -270,28 +411,14 @@ func (r *AdminRepository) DeletePatient(ctx context.Context, patientID uuid.UUID
 	return nil
 }
 
-func (r *AdminRepository) GetPatientEmailByID(ctx context.Context, patientID uuid.UUID) (string, error) {
-	query := `SELECT email FROM patients WHERE id = $1`
-
-	var email string
-
-	err := DB.QueryRowContext(ctx, query, patientID).Scan(&email)
-	if err != nil {
-		utils.LogError("getPatientsByEmail repository (error SELECT)", err)
-		return "", utils.InternalServerError("error getting email")
-	}
-
-	return email, nil
-}
-
-func (r *AdminRepository) CreateCalendarSlot(ctx context.Context, input dtos.CalendarSlotsInput, start, end time.Time, adminID uuid.UUID) (uuid.UUID, error) {
-	query := `INSERT INTO calendar_slots (client_id, weekday, start_time, end_time)
-	VALUES ($1, $2, $3, $4)
+func (r *AdminRepository) CreateCalendarSlot(ctx context.Context, input dtos.CalendarSlotsInput, start, end time.Time, locationID uuid.NullUUID, adminID uuid.UUID) (uuid.UUID, error) {
+	query := `INSERT INTO calendar_slots (client_id, weekday, start_time, end_time, location_id, modality)
+	VALUES ($1, $2, $3, $4, $5, $6)
 	RETURNING id`
 
 	var id uuid.UUID
 
-	err := DB.QueryRowContext(ctx, query, adminID, input.Weekday, start, end).Scan(&id)
+	err := DB.QueryRowContext(ctx, query, adminID, input.Weekday, start, end, locationID, input.Modality).Scan(&id)
 	if err != nil {
 		utils.LogError("creatCalendarSlot repository (error in INSERT)", err)
 		return uuid.UUID{}, utils.InternalServerError("error creating calendar slot")
@@ -301,7 +428,7 @@ func (r *AdminRepository) CreateCalendarSlot(ctx context.Context, input dtos.Cal
 }
 
 func (r *AdminRepository) GetCalendarSlots(ctx context.Context, adminID uuid.UUID) ([]dtos.CalendarSlotsOutput, error) {
-	query := `SELECT id, weekday, start_time, end_time FROM calendar_slots WHERE client_id = $1`
+	query := `SELECT id, weekday, start_time, end_time, location_id, modality FROM calendar_slots WHERE client_id = $1`
 
 	var slotsOutput []dtos.CalendarSlotsOutput
 
@@ -318,9 +445,11 @@ func (r *AdminRepository) GetCalendarSlots(ctx context.Context, adminID uuid.UUI
 			weekday string
 			startTime time.Time
 			endTime time.Time
+			locationID uuid.NullUUID
+			modality string
 		)
 
-		err := rows.Scan(&id, &weekday, &startTime, &endTime)
+		err := rows.Scan(&id, &weekday, &startTime, &endTime, &locationID, &modality)
 		if err != nil {
 			utils.LogError("GetCalendarSlots repository (scan error)", err)
 			return nil, utils.InternalServerError("error fetching slots")
@@ -331,6 +460,8 @@ func (r *AdminRepository) GetCalendarSlots(ctx context.Context, adminID uuid.UUI
 			Weekday: weekday,
 			StartTime: startTime.Format("15:04"),
 			EndTime: endTime.Format("15:04"),
+			LocationID: utils.NullUUIDPtr(locationID),
+			Modality: modality,
 		})
 	}
 
@@ -360,7 +491,7 @@ func (r *AdminRepository) DeleteCalendarSlot(ctx context.Context, slotID uuid.UU
 }
 
 func (r *AdminRepository) GetCalendarSlotsByWeekday(ctx context.Context, adminID uuid.UUID, weekday int) ([]dtos.CalendarSlotDB, error) {
-	query := `SELECT id, client_id, weekday, start_time, end_time FROM calendar_slots
+	query := `SELECT id, client_id, weekday, start_time, end_time, location_id, modality FROM calendar_slots
 	WHERE client_id = $1 AND weekday = $2 ORDER BY start_time`
 
 	rows, err := DB.QueryContext(ctx, query, adminID, weekday)
@@ -381,6 +512,8 @@ func (r *AdminRepository) GetCalendarSlotsByWeekday(ctx context.Context, adminID
 			&slot.Weekday,
 			&slot.StartTime,
 			&slot.EndTime,
+			&slot.LocationID,
+			&slot.Modality,
 		)
 		if err != nil {
 			utils.LogError("getCalendarSlotsByWeekday repository (scan error)", err)
@@ -395,4 +528,4 @@ func (r *AdminRepository) GetCalendarSlotsByWeekday(ctx context.Context, adminID
 	}
 
 	return slots, nil
-}
+}
\ No newline at end of file
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type AnalyticsRepository struct{}
+
+// GetPeriods counts, for each month between from and to ("2006-01-02", both
+// inclusive), the agenda and patient figures of the dashboard. Rates are left
+// for the caller. Patients whose last session was before churnedBefore and
+// who have nothing booked after it count as churned.
+func (r *AnalyticsRepository) GetPeriods(ctx context.Context, clientID uuid.UUID, from, to, churnedBefore string) ([]dtos.AnalyticsPeriodOutput, error) {
+	query := `WITH months AS (
+		SELECT generate_series(date_trunc('month', $2::date::timestamp), $3::date::timestamp, interval '1 month')::date AS month
+	),
+	available AS (
+		SELECT date_trunc('month', d)::date AS month, SUM(EXTRACT(EPOCH FROM s.end_time - s.start_time) / 60) AS minutes
+		FROM generate_series($2::date::timestamp, $3::date::timestamp, interval '1 day') d
+		JOIN calendar_slots s ON s.client_id = $1 AND s.weekday = EXTRACT(DOW FROM d)
+		GROUP BY 1
+	),
+	agenda AS (
+		SELECT date_trunc('month', a.date::timestamp)::date AS month,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE a.status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE a.status = 'cancelled') AS cancelled,
+			COUNT(*) FILTER (WHERE a.status = 'no_show') AS no_shows,
+			SUM(EXTRACT(EPOCH FROM a.end_time - a.start_time) / 60) FILTER (WHERE a.status != 'cancelled') AS booked
+		FROM appointments a
+		WHERE a.client_id = $1 AND a.date >= $2 AND a.date <= $3
+		GROUP BY 1
+	),
+	treatments AS (
+		SELECT patient_id, MIN(date) AS first_date, MAX(date) AS last_date
+		FROM appointments
+		WHERE client_id = $1 AND status = 'completed'
+		GROUP BY patient_id
+	),
+	seen AS (
+		SELECT DISTINCT date_trunc('month', date::timestamp)::date AS month, patient_id
+		FROM appointments
+		WHERE client_id = $1 AND status = 'completed' AND date >= $2 AND date <= $3
+	),
+	patients AS (
+		SELECT s.month,
+			COUNT(*) AS active,
+			COUNT(*) FILTER (WHERE date_trunc('month', t.first_date::timestamp)::date = s.month) AS new,
+			COUNT(*) FILTER (WHERE date_trunc('month', t.last_date::timestamp)::date = s.month
+				AND t.last_date < $4::date
+				AND NOT EXISTS (SELECT 1 FROM appointments u
+					WHERE u.patient_id = s.patient_id AND u.status IN ('scheduled', 'confirmed') AND u.date > t.last_date)) AS churned
+		FROM seen s
+		JOIN treatments t ON t.patient_id = s.patient_id
+		GROUP BY s.month
+	)
+	SELECT m.month, COALESCE(v.minutes, 0)::bigint, COALESCE(g.booked, 0)::bigint,
+		COALESCE(g.total, 0), COALESCE(g.completed, 0), COALESCE(g.cancelled, 0), COALESCE(g.no_shows, 0),
+		COALESCE(p.active, 0), COALESCE(p.new, 0), COALESCE(p.churned, 0)
+	FROM months m
+	LEFT JOIN available v ON v.month = m.month
+	LEFT JOIN agenda g ON g.month = m.month
+	LEFT JOIN patients p ON p.month = m.month
+	ORDER BY m.month`
+
+	rows, err := DB.QueryContext(ctx, query, clientID, from, to, churnedBefore)
+	if err != nil {
+		utils.LogError("getPeriods analytics repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting analytics")
+	}
+	defer rows.Close()
+
+	periods := make([]dtos.AnalyticsPeriodOutput, 0)
+
+	for rows.Next() {
+		var (
+			period dtos.AnalyticsPeriodOutput
+			month  time.Time
+		)
+
+		err := rows.Scan(
+			&month,
+			&period.AvailableMinutes,
+			&period.BookedMinutes,
+			&period.Appointments,
+			&period.Completed,
+			&period.Cancelled,
+			&period.NoShows,
+			&period.ActivePatients,
+			&period.NewPatients,
+			&period.ChurnedPatients,
+		)
+		if err != nil {
+			utils.LogError("getPeriods analytics repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching analytics")
+		}
+
+		period.Month = month.Format("2006-01")
+
+		periods = append(periods, period)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getPeriods analytics repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating analytics")
+	}
+
+	return periods, nil
+}
+
+// GetTreatmentLength returns how many patients had a completed session
+// between from and to, and on average how long (in days, first to last
+// completed session up to to) and how many sessions their treatment took.
+func (r *AnalyticsRepository) GetTreatmentLength(ctx context.Context, clientID uuid.UUID, from, to string) (int, float64, float64, error) {
+	query := `SELECT COUNT(*), COALESCE(AVG(last_date - first_date), 0), COALESCE(AVG(sessions), 0)
+	FROM (
+		SELECT MIN(date) AS first_date, MAX(date) AS last_date, COUNT(*) AS sessions
+		FROM appointments
+		WHERE client_id = $1 AND status = 'completed' AND date <= $3
+		GROUP BY patient_id
+		HAVING MAX(date) >= $2
+	) t`
+
+	var (
+		patients int
+		days     float64
+		sessions float64
+	)
+
+	err := DB.QueryRowContext(ctx, query, clientID, from, to).Scan(&patients, &days, &sessions)
+	if err != nil {
+		utils.LogError("getTreatmentLength analytics repository (SELECT error)", err)
+		return 0, 0, 0, utils.InternalServerError("error getting analytics")
+	}
+
+	return patients, days, sessions, nil
+}
\ No newline at end of file
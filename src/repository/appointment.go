@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/lib/pq"
+)
+
+type AppointmentRepository struct{}
+
+func (r *AppointmentRepository) GetAppointmentDetails(ctx context.Context, db DBTX, appointmentID uuid.UUID) (dtos.AppointmentDetails, error) {
+	query := `SELECT a.id, a.client_id, a.patient_id, p.full_name, p.email, p.phone, p.notification_channels,
+	c.full_name, c.email, a.date, a.start_time, a.end_time, a.status, a.modality, a.video_url,
+	COALESCE(l.address, c.office_address, ''), c.min_cancel_notice_hours, a.ics_sequence,
+	c.late_cancel_fee_cents, c.no_show_fee_cents, COALESCE(p.session_price_cents, c.session_price_cents),
+	c.cancellation_policy, a.late_cancelled, a.fee_cents, a.fee_waived_at IS NOT NULL, a.authorization_id
+	FROM appointments a
+	JOIN patients p ON p.id = a.patient_id
+	JOIN clients c ON c.id = a.client_id
+	LEFT JOIN locations l ON l.id = a.location_id
+	WHERE a.id = $1`
+
+	var (
+		details       dtos.AppointmentDetails
+		lateCancelFee sql.NullInt64
+		noShowFee     sql.NullInt64
+		authorization uuid.NullUUID
+	)
+
+	err := db.QueryRowContext(ctx, query, appointmentID).Scan(
+		&details.ID,
+		&details.ClientID,
+		&details.PatientID,
+		&details.PatientName,
+		&details.PatientEmail,
+		&details.PatientPhone,
+		pq.Array(&details.PatientChannels),
+		&details.AdminName,
+		&details.AdminEmail,
+		&details.Date,
+		&details.StartTime,
+		&details.EndTime,
+		&details.Status,
+		&details.Modality,
+		&details.VideoURL,
+		&details.Address,
+		&details.MinNoticeHours,
+		&details.Sequence,
+		&lateCancelFee,
+		&noShowFee,
+		&details.SessionPriceCents,
+		&details.Policy,
+		&details.LateCancelled,
+		&details.FeeCents,
+		&details.FeeWaived,
+		&authorization,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.AppointmentDetails{}, utils.NotFoundError("appointment not found")
+	}
+	if err != nil {
+		utils.LogError("getAppointmentDetails repository (SELECT error)", err)
+		return dtos.AppointmentDetails{}, utils.InternalServerError("error getting appointment")
+	}
+
+	details.LateCancelFeeCents = nullInt64Ptr(lateCancelFee)
+	details.NoShowFeeCents = nullInt64Ptr(noShowFee)
+	details.AuthorizationID = utils.NullUUIDPtr(authorization)
+
+	return details, nil
+}
+
+// UpdateStatus moves the appointment from one status to another. The current
+// status is part of the WHERE clause so two concurrent transitions cannot both win.
+// Cancelling also bumps the calendar sequence, as the event is removed.
+func (r *AppointmentRepository) UpdateStatus(ctx context.Context, db DBTX, appointmentID uuid.UUID, from, to, cancelledBy string) error {
+	query := `UPDATE appointments SET status = $1, cancelled_by = NULLIF($2, ''),
+	ics_sequence = ics_sequence + CASE WHEN $1 = 'cancelled' THEN 1 ELSE 0 END
+	WHERE id = $3 AND status = $4`
+
+	res, err := db.ExecContext(ctx, query, to, cancelledBy, appointmentID, from)
+	if err != nil {
+		utils.LogError("updateStatus appointment repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating appointment status")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("updateStatus appointment repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating appointment status")
+	}
+
+	if rows == 0 {
+		return utils.ConflictError("appointment status changed, try again")
+	}
+
+	return nil
+}
+
+// Reschedule moves the appointment to a new date and time and sends it back to
+// scheduled, so the patient confirms the new time. The status guard works as
+// in UpdateStatus.
+func (r *AppointmentRepository) Reschedule(ctx context.Context, db DBTX, appointmentID uuid.UUID, from string, date, start, end time.Time) error {
+	query := `UPDATE appointments SET date = $1, start_time = $2, end_time = $3,
+	status = 'scheduled', ics_sequence = ics_sequence + 1
+	WHERE id = $4 AND status = $5`
+
+	res, err := db.ExecContext(ctx, query, date, start, end, appointmentID, from)
+	if err != nil {
+		utils.LogError("reschedule appointment repository (UPDATE error)", err)
+		return utils.InternalServerError("error rescheduling appointment")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("reschedule appointment repository (error reading rows affected)", err)
+		return utils.InternalServerError("error rescheduling appointment")
+	}
+
+	if rows == 0 {
+		return utils.ConflictError("appointment status changed, try again")
+	}
+
+	return nil
+}
+
+func (r *AppointmentRepository) GetCancellationSettings(ctx context.Context, adminID uuid.UUID) (dtos.CancellationSettingsOutput, error) {
+	query := `SELECT min_cancel_notice_hours, late_cancel_fee_cents, no_show_fee_cents, cancellation_policy, session_price_cents
+	FROM clients WHERE id = $1`
+
+	var (
+		settings      dtos.CancellationSettingsOutput
+		lateCancelFee sql.NullInt64
+		noShowFee     sql.NullInt64
+	)
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(
+		&settings.MinNoticeHours,
+		&lateCancelFee,
+		&noShowFee,
+		&settings.Policy,
+		&settings.SessionPriceCents,
+	)
+	if err != nil {
+		utils.LogError("getCancellationSettings repository (SELECT error)", err)
+		return dtos.CancellationSettingsOutput{}, utils.InternalServerError("error getting cancellation settings")
+	}
+
+	settings.LateCancelFeeCents = nullInt64Ptr(lateCancelFee)
+	settings.NoShowFeeCents = nullInt64Ptr(noShowFee)
+
+	return settings, nil
+}
+
+func (r *AppointmentRepository) UpdateCancellationSettings(ctx context.Context, adminID uuid.UUID, input dtos.CancellationSettingsInput) error {
+	query := `UPDATE clients SET min_cancel_notice_hours = $1, late_cancel_fee_cents = $2, no_show_fee_cents = $3,
+	cancellation_policy = $4
+	WHERE id = $5`
+
+	_, err := DB.ExecContext(ctx, query, *input.MinNoticeHours, input.LateCancelFeeCents, input.NoShowFeeCents, input.Policy, adminID)
+	if err != nil {
+		utils.LogError("updateCancellationSettings repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating cancellation settings")
+	}
+
+	return nil
+}
+
+// RecordFee notes on the appointment the fee charged for a late cancellation
+// or no-show.
+func (r *AppointmentRepository) RecordFee(ctx context.Context, db DBTX, appointmentID uuid.UUID, lateCancelled bool, feeCents int64, waived bool) error {
+	query := `UPDATE appointments SET late_cancelled = $1, fee_cents = $2,
+	fee_waived_at = CASE WHEN $3 THEN NOW() END
+	WHERE id = $4`
+
+	_, err := db.ExecContext(ctx, query, lateCancelled, feeCents, waived, appointmentID)
+	if err != nil {
+		utils.LogError("recordFee appointment repository (UPDATE error)", err)
+		return utils.InternalServerError("error recording appointment fee")
+	}
+
+	return nil
+}
+
+// WaiveFee marks the appointment's fee as waived. It fails with a conflict if
+// the fee was already waived.
+func (r *AppointmentRepository) WaiveFee(ctx context.Context, db DBTX, appointmentID uuid.UUID) error {
+	query := `UPDATE appointments SET fee_waived_at = NOW() WHERE id = $1 AND fee_waived_at IS NULL`
+
+	res, err := db.ExecContext(ctx, query, appointmentID)
+	if err != nil {
+		utils.LogError("waiveFee appointment repository (UPDATE error)", err)
+		return utils.InternalServerError("error waiving appointment fee")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("waiveFee appointment repository (error reading rows affected)", err)
+		return utils.InternalServerError("error waiving appointment fee")
+	}
+
+	if rows == 0 {
+		return utils.ConflictError("the fee was already waived")
+	}
+
+	return nil
+}
+
+func nullInt64Ptr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+
+	return &n.Int64
+}
\ No newline at end of file
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/lib/pq"
+)
+
+type AvailabilityExceptionRepository struct{}
+
+const availabilityExceptionColumns = `id, name, uid, summary, starts_at, ends_at, recurring, busy, data, etag, updated_at`
+
+func scanAvailabilityException(row interface{ Scan(...any) error }) (dtos.AvailabilityException, error) {
+	var exception dtos.AvailabilityException
+
+	err := row.Scan(
+		&exception.ID,
+		&exception.Name,
+		&exception.UID,
+		&exception.Summary,
+		&exception.StartsAt,
+		&exception.EndsAt,
+		&exception.Recurring,
+		&exception.Busy,
+		&exception.Data,
+		&exception.ETag,
+		&exception.UpdatedAt,
+	)
+
+	return exception, err
+}
+
+func (r *AvailabilityExceptionRepository) GetExceptions(ctx context.Context, adminID uuid.UUID) ([]dtos.AvailabilityException, error) {
+	query := `SELECT ` + availabilityExceptionColumns + ` FROM availability_exceptions
+	WHERE client_id = $1
+	ORDER BY starts_at`
+
+	return r.query(ctx, "getExceptions", query, adminID)
+}
+
+// GetBusy returns the busy exceptions that may overlap [from, to). Recurring
+// ones are returned whenever their series started before to, the caller
+// expands them.
+func (r *AvailabilityExceptionRepository) GetBusy(ctx context.Context, adminID uuid.UUID, from, to time.Time) ([]dtos.AvailabilityException, error) {
+	query := `SELECT ` + availabilityExceptionColumns + ` FROM availability_exceptions
+	WHERE client_id = $1 AND busy AND starts_at < $3
+	AND (ends_at > $2 OR recurring)`
+
+	return r.query(ctx, "getBusy", query, adminID, from, to)
+}
+
+func (r *AvailabilityExceptionRepository) query(ctx context.Context, fnName, query string, args ...any) ([]dtos.AvailabilityException, error) {
+	rows, err := DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		utils.LogError(fnName+" availability exception repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting availability exceptions")
+	}
+	defer rows.Close()
+
+	var exceptions []dtos.AvailabilityException
+
+	for rows.Next() {
+		exception, err := scanAvailabilityException(rows)
+		if err != nil {
+			utils.LogError(fnName+" availability exception repository (error scanning rows)", err)
+			return nil, utils.InternalServerError("error getting availability exceptions")
+		}
+
+		exceptions = append(exceptions, exception)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError(fnName+" availability exception repository (rows error)", err)
+		return nil, utils.InternalServerError("error getting availability exceptions")
+	}
+
+	return exceptions, nil
+}
+
+func (r *AvailabilityExceptionRepository) GetExceptionByName(ctx context.Context, adminID uuid.UUID, name string) (dtos.AvailabilityException, error) {
+	query := `SELECT ` + availabilityExceptionColumns + ` FROM availability_exceptions
+	WHERE client_id = $1 AND name = $2`
+
+	exception, err := scanAvailabilityException(DB.QueryRowContext(ctx, query, adminID, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.AvailabilityException{}, utils.NotFoundError("calendar object not found")
+	}
+	if err != nil {
+		utils.LogError("getExceptionByName repository (SELECT error)", err)
+		return dtos.AvailabilityException{}, utils.InternalServerError("error getting availability exception")
+	}
+
+	return exception, nil
+}
+
+// SaveException creates or replaces the exception stored under its name.
+func (r *AvailabilityExceptionRepository) SaveException(ctx context.Context, adminID uuid.UUID, exception dtos.AvailabilityException) (time.Time, error) {
+	query := `INSERT INTO availability_exceptions (client_id, name, uid, summary, starts_at, ends_at, recurring, busy, data, etag)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (client_id, name) DO UPDATE SET
+	uid = EXCLUDED.uid, summary = EXCLUDED.summary, starts_at = EXCLUDED.starts_at, ends_at = EXCLUDED.ends_at,
+	recurring = EXCLUDED.recurring, busy = EXCLUDED.busy, data = EXCLUDED.data, etag = EXCLUDED.etag, updated_at = NOW()
+	RETURNING updated_at`
+
+	var updatedAt time.Time
+
+	err := DB.QueryRowContext(ctx, query,
+		adminID,
+		exception.Name,
+		exception.UID,
+		exception.Summary,
+		exception.StartsAt,
+		exception.EndsAt,
+		exception.Recurring,
+		exception.Busy,
+		exception.Data,
+		exception.ETag,
+	).Scan(&updatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return time.Time{}, utils.ConflictError("an event with this UID already exists")
+	}
+	if err != nil {
+		utils.LogError("saveException repository (INSERT error)", err)
+		return time.Time{}, utils.InternalServerError("error saving availability exception")
+	}
+
+	return updatedAt, nil
+}
+
+func (r *AvailabilityExceptionRepository) DeleteException(ctx context.Context, adminID uuid.UUID, name string) error {
+	query := `DELETE FROM availability_exceptions WHERE client_id = $1 AND name = $2`
+
+	res, err := DB.ExecContext(ctx, query, adminID, name)
+	if err != nil {
+		utils.LogError("deleteException repository (DELETE error)", err)
+		return utils.InternalServerError("error deleting availability exception")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("deleteException repository (error reading rows affected)", err)
+		return utils.InternalServerError("error deleting availability exception")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("calendar object not found")
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type BillingRepository struct{}
+
+// signedAmount turns an entry into its effect on the balance.
+const signedAmount = `CASE WHEN kind IN ('charge', 'refund') THEN amount_cents ELSE -amount_cents END`
+
+func (r *BillingRepository) GetPricing(ctx context.Context, adminID uuid.UUID) (dtos.PricingOutput, error) {
+	query := `SELECT session_price_cents FROM clients WHERE id = $1`
+
+	var pricing dtos.PricingOutput
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(&pricing.SessionPriceCents)
+	if err != nil {
+		utils.LogError("getPricing billing repository (SELECT error)", err)
+		return dtos.PricingOutput{}, utils.InternalServerError("error getting pricing")
+	}
+
+	return pricing, nil
+}
+
+func (r *BillingRepository) UpdatePricing(ctx context.Context, adminID uuid.UUID, priceCents int64) error {
+	query := `UPDATE clients SET session_price_cents = $1 WHERE id = $2`
+
+	_, err := DB.ExecContext(ctx, query, priceCents, adminID)
+	if err != nil {
+		utils.LogError("updatePricing billing repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating pricing")
+	}
+
+	return nil
+}
+
+func (r *BillingRepository) GetPatientPrice(ctx context.Context, patientID, clientID uuid.UUID) (dtos.PatientPriceOutput, error) {
+	query := `SELECT p.session_price_cents, c.session_price_cents
+	FROM patients p
+	JOIN clients c ON c.id = p.client_id
+	WHERE p.id = $1 AND p.client_id = $2`
+
+	var (
+		price  dtos.PatientPriceOutput
+		custom sql.NullInt64
+	)
+
+	err := DB.QueryRowContext(ctx, query, patientID, clientID).Scan(&custom, &price.DefaultPriceCents)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.PatientPriceOutput{}, utils.NotFoundError("patient not found")
+	}
+	if err != nil {
+		utils.LogError("getPatientPrice billing repository (SELECT error)", err)
+		return dtos.PatientPriceOutput{}, utils.InternalServerError("error getting patient price")
+	}
+
+	price.SessionPriceCents = price.DefaultPriceCents
+
+	if custom.Valid {
+		price.CustomPriceCents = &custom.Int64
+		price.SessionPriceCents = custom.Int64
+	}
+
+	return price, nil
+}
+
+func (r *BillingRepository) UpdatePatientPrice(ctx context.Context, patientID, clientID uuid.UUID, priceCents sql.NullInt64) error {
+	query := `UPDATE patients SET session_price_cents = $1 WHERE id = $2 AND client_id = $3`
+
+	res, err := DB.ExecContext(ctx, query, priceCents, patientID, clientID)
+	if err != nil {
+		utils.LogError("updatePatientPrice billing repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating patient price")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("updatePatientPrice billing repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating patient price")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("patient not found")
+	}
+
+	return nil
+}
+
+// ChargeAppointment charges the patient's current price for the appointment.
+// Nothing is charged when no price is set or the appointment was already
+// charged.
+func (r *BillingRepository) ChargeAppointment(ctx context.Context, db DBTX, appointmentID uuid.UUID, description string) error {
+	query := `INSERT INTO ledger_entries (client_id, patient_id, appointment_id, kind, amount_cents, description)
+	SELECT a.client_id, a.patient_id, a.id, 'charge', COALESCE(p.session_price_cents, c.session_price_cents), $2
+	FROM appointments a
+	JOIN patients p ON p.id = a.patient_id
+	JOIN clients c ON c.id = a.client_id
+	WHERE a.id = $1 AND COALESCE(p.session_price_cents, c.session_price_cents) > 0
+	ON CONFLICT DO NOTHING`
+
+	_, err := db.ExecContext(ctx, query, appointmentID, description)
+	if err != nil {
+		utils.LogError("chargeAppointment billing repository (INSERT error)", err)
+		return utils.InternalServerError("error charging appointment")
+	}
+
+	return nil
+}
+
+func (r *BillingRepository) CreateEntry(ctx context.Context, db DBTX, clientID, patientID uuid.UUID, appointmentID uuid.NullUUID, input dtos.LedgerEntryInput) (uuid.UUID, error) {
+	query := `INSERT INTO ledger_entries (client_id, patient_id, appointment_id, kind, amount_cents, description, method)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err := db.QueryRowContext(ctx, query, clientID, patientID, appointmentID, input.Kind, input.AmountCents, input.Description, input.Method).Scan(&id)
+	if err != nil {
+		utils.LogError("createEntry billing repository (INSERT error)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating ledger entry")
+	}
+
+	return id, nil
+}
+
+// CreatePaymentEntry books money received or refunded through a gateway
+// payment.
+func (r *BillingRepository) CreatePaymentEntry(ctx context.Context, db DBTX, payment dtos.Payment, kind string, amountCents int64, description string) error {
+	query := `INSERT INTO ledger_entries (client_id, patient_id, payment_id, kind, amount_cents, description, method)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := db.ExecContext(ctx, query, payment.ClientID, payment.PatientID, payment.ID, kind, amountCents, description, payment.Method)
+	if err != nil {
+		utils.LogError("createPaymentEntry billing repository (INSERT error)", err)
+		return utils.InternalServerError("error creating ledger entry")
+	}
+
+	return nil
+}
+
+// GetLedger returns the patient's entries in order, each with the balance
+// right after it.
+func (r *BillingRepository) GetLedger(ctx context.Context, patientID uuid.UUID) ([]dtos.LedgerEntryOutput, error) {
+	query := `SELECT id, appointment_id, payment_id, kind, amount_cents, description, method,
+		SUM(` + signedAmount + `) OVER (ORDER BY created_at, id),
+		created_at
+	FROM ledger_entries
+	WHERE patient_id = $1
+	ORDER BY created_at, id`
+
+	rows, err := DB.QueryContext(ctx, query, patientID)
+	if err != nil {
+		utils.LogError("getLedger billing repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting ledger")
+	}
+	defer rows.Close()
+
+	entries := make([]dtos.LedgerEntryOutput, 0)
+
+	for rows.Next() {
+		var (
+			entry         dtos.LedgerEntryOutput
+			appointmentID uuid.NullUUID
+			paymentID     uuid.NullUUID
+		)
+
+		err := rows.Scan(
+			&entry.ID,
+			&appointmentID,
+			&paymentID,
+			&entry.Kind,
+			&entry.AmountCents,
+			&entry.Description,
+			&entry.Method,
+			&entry.BalanceCents,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			utils.LogError("getLedger billing repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching ledger")
+		}
+
+		entry.AppointmentID = utils.NullUUIDPtr(appointmentID)
+		entry.PaymentID = utils.NullUUIDPtr(paymentID)
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getLedger billing repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating ledger")
+	}
+
+	return entries, nil
+}
+
+// GetBalances returns the balance of every patient of the psychologist with
+// ledger entries, largest debts first.
+func (r *BillingRepository) GetBalances(ctx context.Context, adminID uuid.UUID) ([]dtos.PatientBalanceOutput, error) {
+	query := `SELECT p.id, p.full_name, SUM(` + signedAmount + `), MAX(l.created_at)
+	FROM ledger_entries l
+	JOIN patients p ON p.id = l.patient_id
+	WHERE l.client_id = $1
+	GROUP BY p.id, p.full_name
+	ORDER BY 3 DESC, p.full_name`
+
+	rows, err := DB.QueryContext(ctx, query, adminID)
+	if err != nil {
+		utils.LogError("getBalances billing repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting balances")
+	}
+	defer rows.Close()
+
+	balances := make([]dtos.PatientBalanceOutput, 0)
+
+	for rows.Next() {
+		var balance dtos.PatientBalanceOutput
+
+		if err := rows.Scan(&balance.PatientID, &balance.PatientName, &balance.BalanceCents, &balance.LastEntryAt); err != nil {
+			utils.LogError("getBalances billing repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching balances")
+		}
+
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getBalances billing repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating balances")
+	}
+
+	return balances, nil
+}
+
+// GetEntry returns one entry of the patient's ledger, without its running
+// balance.
+func (r *BillingRepository) GetEntry(ctx context.Context, entryID, patientID uuid.UUID) (dtos.LedgerEntryOutput, error) {
+	query := `SELECT id, appointment_id, payment_id, kind, amount_cents, description, method, created_at
+	FROM ledger_entries WHERE id = $1 AND patient_id = $2`
+
+	var (
+		entry         dtos.LedgerEntryOutput
+		appointmentID uuid.NullUUID
+		paymentID     uuid.NullUUID
+	)
+
+	err := DB.QueryRowContext(ctx, query, entryID, patientID).Scan(
+		&entry.ID,
+		&appointmentID,
+		&paymentID,
+		&entry.Kind,
+		&entry.AmountCents,
+		&entry.Description,
+		&entry.Method,
+		&entry.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.LedgerEntryOutput{}, utils.NotFoundError("ledger entry not found")
+	}
+	if err != nil {
+		utils.LogError("getEntry billing repository (SELECT error)", err)
+		return dtos.LedgerEntryOutput{}, utils.InternalServerError("error getting ledger entry")
+	}
+
+	entry.AppointmentID = utils.NullUUIDPtr(appointmentID)
+	entry.PaymentID = utils.NullUUIDPtr(paymentID)
+
+	return entry, nil
+}
+
+func (r *BillingRepository) GetBalance(ctx context.Context, patientID uuid.UUID) (int64, error) {
+	query := `SELECT COALESCE(SUM(` + signedAmount + `), 0) FROM ledger_entries WHERE patient_id = $1`
+
+	var balance int64
+
+	err := DB.QueryRowContext(ctx, query, patientID).Scan(&balance)
+	if err != nil {
+		utils.LogError("getBalance billing repository (SELECT error)", err)
+		return 0, utils.InternalServerError("error getting balance")
+	}
+
+	return balance, nil
+}
\ No newline at end of file
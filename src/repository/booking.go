@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/lib/pq"
+)
+
+type BookingRepository struct{}
+
+func (r *BookingRepository) GetSettings(ctx context.Context, adminID uuid.UUID) (dtos.BookingSettingsOutput, error) {
+	query := `SELECT booking_mode, booking_hold_hours FROM clients WHERE id = $1`
+
+	var settings dtos.BookingSettingsOutput
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(&settings.Mode, &settings.HoldHours)
+	if err != nil {
+		utils.LogError("getSettings booking repository (SELECT error)", err)
+		return dtos.BookingSettingsOutput{}, utils.InternalServerError("error getting booking settings")
+	}
+
+	return settings, nil
+}
+
+func (r *BookingRepository) UpdateSettings(ctx context.Context, adminID uuid.UUID, mode string, holdHours int) error {
+	query := `UPDATE clients SET booking_mode = $1, booking_hold_hours = $2 WHERE id = $3`
+
+	_, err := DB.ExecContext(ctx, query, mode, holdHours, adminID)
+	if err != nil {
+		utils.LogError("updateSettings booking repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating booking settings")
+	}
+
+	return nil
+}
+
+func (r *BookingRepository) UpdateRules(ctx context.Context, adminID uuid.UUID, rules dtos.BookingRulesOutput) error {
+	query := `UPDATE clients SET
+		booking_min_notice_hours = $1,
+		booking_max_horizon_days = $2,
+		booking_max_per_day = $3,
+		booking_max_per_week = $4,
+		booking_max_open_per_patient = $5
+	WHERE id = $6`
+
+	_, err := DB.ExecContext(ctx, query, rules.MinNoticeHours, rules.MaxHorizonDays, rules.MaxPerDay, rules.MaxPerWeek, rules.MaxOpenPerPatient, adminID)
+	if err != nil {
+		utils.LogError("updateRules booking repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating booking rules")
+	}
+
+	return nil
+}
+
+// LockPatient makes the other bookings of the patient wait until tx ends, so
+// the patient's open sessions are counted one booking at a time.
+func (r *BookingRepository) LockPatient(ctx context.Context, db DBTX, patientID uuid.UUID) error {
+	query := `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`
+
+	if _, err := db.ExecContext(ctx, query, "booking:"+patientID.String()); err != nil {
+		utils.LogError("lockPatient booking repository (SELECT error)", err)
+		return utils.InternalServerError("error booking appointment")
+	}
+
+	return nil
+}
+
+// CountOpenBookings returns the upcoming sessions of the patient, counting
+// requests still waiting for an answer.
+func (r *BookingRepository) CountOpenBookings(ctx context.Context, db DBTX, patientID uuid.UUID, today string) (int, error) {
+	query := `SELECT
+		(SELECT COUNT(*) FROM appointments
+		WHERE patient_id = $1 AND date >= $2 AND status IN ('scheduled', 'confirmed'))
+		+
+		(SELECT COUNT(*) FROM booking_requests
+		WHERE patient_id = $1 AND status = 'pending' AND expires_at > NOW())`
+
+	var total int
+
+	err := db.QueryRowContext(ctx, query, patientID, today).Scan(&total)
+	if err != nil {
+		utils.LogError("countOpenBookings booking repository (SELECT error)", err)
+		return 0, utils.InternalServerError("error counting bookings")
+	}
+
+	return total, nil
+}
+
+// GetPatientSettings returns the psychologist of the patient and how they
+// accept bookings.
+func (r *BookingRepository) GetPatientSettings(ctx context.Context, patientID uuid.UUID) (uuid.UUID, dtos.BookingSettingsOutput, error) {
+	query := `SELECT c.id, c.booking_mode, c.booking_hold_hours
+	FROM patients p
+	JOIN clients c ON c.id = p.client_id
+	WHERE p.id = $1`
+
+	var (
+		clientID uuid.UUID
+		settings dtos.BookingSettingsOutput
+	)
+
+	err := DB.QueryRowContext(ctx, query, patientID).Scan(&clientID, &settings.Mode, &settings.HoldHours)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, dtos.BookingSettingsOutput{}, utils.NotFoundError("patient not found")
+	}
+	if err != nil {
+		utils.LogError("getPatientSettings booking repository (SELECT error)", err)
+		return uuid.UUID{}, dtos.BookingSettingsOutput{}, utils.InternalServerError("error getting booking settings")
+	}
+
+	return clientID, settings, nil
+}
+
+func (r *BookingRepository) CreateRequest(ctx context.Context, db DBTX, clientID, patientID uuid.UUID, date, start, end time.Time, locationID uuid.NullUUID, modality string, expiresAt time.Time) (uuid.UUID, error) {
+	query := `INSERT INTO booking_requests (client_id, patient_id, date, start_time, end_time, location_id, modality, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err := db.QueryRowContext(ctx, query, clientID, patientID, date, start, end, locationID, modality, expiresAt).Scan(&id)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return uuid.UUID{}, utils.ConflictError("this time is no longer available")
+	}
+	if err != nil {
+		utils.LogError("createRequest booking repository (INSERT error)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating booking request")
+	}
+
+	return id, nil
+}
+
+// GetRequest returns the request with the patient who made it. Inside a
+// transaction the request stays locked until it ends.
+func (r *BookingRepository) GetRequest(ctx context.Context, db DBTX, requestID uuid.UUID) (dtos.BookingRequest, error) {
+	query := `SELECT b.id, b.client_id, b.patient_id, p.full_name, p.email, p.phone, p.notification_channels, c.email,
+	b.date, b.start_time, b.end_time, b.location_id, b.modality, COALESCE(l.address, c.office_address, ''),
+	b.status, b.expires_at
+	FROM booking_requests b
+	JOIN patients p ON p.id = b.patient_id
+	JOIN clients c ON c.id = b.client_id
+	LEFT JOIN locations l ON l.id = b.location_id
+	WHERE b.id = $1
+	FOR UPDATE OF b`
+
+	var request dtos.BookingRequest
+
+	err := db.QueryRowContext(ctx, query, requestID).Scan(
+		&request.ID,
+		&request.ClientID,
+		&request.PatientID,
+		&request.Patient.Name,
+		&request.Patient.Email,
+		&request.Patient.Phone,
+		pq.Array(&request.Patient.Channels),
+		&request.AdminEmail,
+		&request.Date,
+		&request.StartTime,
+		&request.EndTime,
+		&request.LocationID,
+		&request.Modality,
+		&request.Address,
+		&request.Status,
+		&request.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.BookingRequest{}, utils.NotFoundError("booking request not found")
+	}
+	if err != nil {
+		utils.LogError("getRequest booking repository (SELECT error)", err)
+		return dtos.BookingRequest{}, utils.InternalServerError("error getting booking request")
+	}
+
+	return request, nil
+}
+
+// GetRequests lists the requests of a psychologist, or of a patient when
+// patientID is set, newest first.
+func (r *BookingRepository) GetRequests(ctx context.Context, adminID, patientID uuid.UUID, status string) ([]dtos.BookingRequestOutput, error) {
+	query := `SELECT b.id, b.patient_id, p.full_name, b.date, b.start_time, b.end_time, b.location_id, b.modality,
+	b.status, b.expires_at, b.decline_reason, b.appointment_id, b.created_at
+	FROM booking_requests b
+	JOIN patients p ON p.id = b.patient_id
+	WHERE ($1::uuid IS NULL OR b.client_id = $1)
+	AND ($2::uuid IS NULL OR b.patient_id = $2)
+	AND ($3 = '' OR b.status = $3)
+	ORDER BY b.created_at DESC
+	LIMIT 200`
+
+	admin := uuid.NullUUID{UUID: adminID, Valid: adminID != uuid.Nil}
+	patient := uuid.NullUUID{UUID: patientID, Valid: patientID != uuid.Nil}
+
+	rows, err := DB.QueryContext(ctx, query, admin, patient, status)
+	if err != nil {
+		utils.LogError("getRequests booking repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting booking requests")
+	}
+	defer rows.Close()
+
+	requests := make([]dtos.BookingRequestOutput, 0)
+
+	for rows.Next() {
+		var (
+			request dtos.BookingRequestOutput
+			date time.Time
+			startTime time.Time
+			endTime time.Time
+			locationID uuid.NullUUID
+			appointmentID uuid.NullUUID
+		)
+
+		err := rows.Scan(
+			&request.ID,
+			&request.PatientID,
+			&request.PatientName,
+			&date,
+			&startTime,
+			&endTime,
+			&locationID,
+			&request.Modality,
+			&request.Status,
+			&request.ExpiresAt,
+			&request.DeclineReason,
+			&appointmentID,
+			&request.CreatedAt,
+		)
+		if err != nil {
+			utils.LogError("getRequests booking repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching booking requests")
+		}
+
+		request.Date = date.Format("2006-01-02")
+		request.StartTime = startTime.Format("15:04")
+		request.EndTime = endTime.Format("15:04")
+		request.LocationID = utils.NullUUIDPtr(locationID)
+		request.AppointmentID = utils.NullUUIDPtr(appointmentID)
+
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getRequests booking repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating booking requests")
+	}
+
+	return requests, nil
+}
+
+// Respond closes a pending request. The status guard keeps a request from
+// being accepted and declined at the same time.
+func (r *BookingRepository) Respond(ctx context.Context, db DBTX, requestID uuid.UUID, status string, appointmentID uuid.NullUUID, reason string) error {
+	query := `UPDATE booking_requests SET status = $1, appointment_id = $2, decline_reason = $3, responded_at = NOW()
+	WHERE id = $4 AND status = 'pending'`
+
+	res, err := db.ExecContext(ctx, query, status, appointmentID, reason, requestID)
+	if err != nil {
+		utils.LogError("respond booking repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating booking request")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("respond booking repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating booking request")
+	}
+
+	if rows == 0 {
+		return utils.ConflictError("this booking request was already answered")
+	}
+
+	return nil
+}
+
+// ExpireRequests closes the pending requests the psychologist did not answer
+// in time, releasing their slots.
+func (r *BookingRepository) ExpireRequests(ctx context.Context, db DBTX, now time.Time) ([]uuid.UUID, error) {
+	query := `UPDATE booking_requests SET status = 'expired', responded_at = NOW()
+	WHERE status = 'pending' AND expires_at <= $1
+	RETURNING id`
+
+	return r.expire(ctx, db, query, now)
+}
+
+// ExpireSlotRequests closes the pending requests for the slot that ran out
+// of time, which would otherwise keep holding it until the next sweep.
+func (r *BookingRepository) ExpireSlotRequests(ctx context.Context, db DBTX, clientID uuid.UUID, date, start, now time.Time) ([]uuid.UUID, error) {
+	query := `UPDATE booking_requests SET status = 'expired', responded_at = NOW()
+	WHERE status = 'pending' AND expires_at <= $1 AND client_id = $2 AND date = $3 AND start_time = $4
+	RETURNING id`
+
+	return r.expire(ctx, db, query, now, clientID, date, start)
+}
+
+func (r *BookingRepository) expire(ctx context.Context, db DBTX, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		utils.LogError("expireRequests booking repository (UPDATE error)", err)
+		return nil, utils.InternalServerError("error expiring booking requests")
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+
+	for rows.Next() {
+		var id uuid.UUID
+
+		if err := rows.Scan(&id); err != nil {
+			utils.LogError("expireRequests booking repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching booking requests")
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("expireRequests booking repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating booking requests")
+	}
+
+	return ids, nil
+}
\ No newline at end of file
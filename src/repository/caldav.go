@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type CalDAVRepository struct{}
+
+func (r *CalDAVRepository) SetPassword(ctx context.Context, adminID uuid.UUID, passwordHash string) (string, error) {
+	query := `UPDATE clients SET caldav_password_hash = $1 WHERE id = $2 RETURNING email`
+
+	var email string
+
+	err := DB.QueryRowContext(ctx, query, passwordHash, adminID).Scan(&email)
+	if err != nil {
+		utils.LogError("setPassword caldav repository (UPDATE error)", err)
+		return "", utils.InternalServerError("error generating caldav password")
+	}
+
+	return email, nil
+}
+
+func (r *CalDAVRepository) ClearPassword(ctx context.Context, adminID uuid.UUID) error {
+	query := `UPDATE clients SET caldav_password_hash = NULL WHERE id = $1`
+
+	_, err := DB.ExecContext(ctx, query, adminID)
+	if err != nil {
+		utils.LogError("clearPassword caldav repository (UPDATE error)", err)
+		return utils.InternalServerError("error revoking caldav password")
+	}
+
+	return nil
+}
+
+// GetAccount returns the admin email, used as CalDAV username, and whether a
+// CalDAV password is set.
+func (r *CalDAVRepository) GetAccount(ctx context.Context, adminID uuid.UUID) (string, bool, error) {
+	query := `SELECT email, caldav_password_hash IS NOT NULL FROM clients WHERE id = $1`
+
+	var email string
+	var enabled bool
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(&email, &enabled)
+	if err != nil {
+		utils.LogError("getAccount caldav repository (SELECT error)", err)
+		return "", false, utils.InternalServerError("error getting caldav account")
+	}
+
+	return email, enabled, nil
+}
+
+func (r *CalDAVRepository) FindAccountByEmail(ctx context.Context, email string) (uuid.UUID, string, error) {
+	query := `SELECT id, caldav_password_hash FROM clients
+	WHERE email = $1 AND caldav_password_hash IS NOT NULL`
+
+	var id uuid.UUID
+	var passwordHash string
+
+	err := DB.QueryRowContext(ctx, query, email).Scan(&id, &passwordHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, "", utils.UnauthorizedError("invalid credentials")
+	}
+	if err != nil {
+		utils.LogError("findAccountByEmail caldav repository (SELECT error)", err)
+		return uuid.UUID{}, "", utils.InternalServerError("error authenticating")
+	}
+
+	return id, passwordHash, nil
+}
\ No newline at end of file
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type CalendarFeedRepository struct{}
+
+func (r *CalendarFeedRepository) SetToken(ctx context.Context, adminID uuid.UUID, tokenHash string) (time.Time, error) {
+	query := `UPDATE clients SET calendar_feed_token_hash = $1, calendar_feed_created_at = NOW()
+	WHERE id = $2
+	RETURNING calendar_feed_created_at`
+
+	var createdAt time.Time
+
+	err := DB.QueryRowContext(ctx, query, tokenHash, adminID).Scan(&createdAt)
+	if err != nil {
+		utils.LogError("setToken calendar feed repository (UPDATE error)", err)
+		return time.Time{}, utils.InternalServerError("error generating calendar feed")
+	}
+
+	return createdAt, nil
+}
+
+func (r *CalendarFeedRepository) ClearToken(ctx context.Context, adminID uuid.UUID) error {
+	query := `UPDATE clients SET calendar_feed_token_hash = NULL, calendar_feed_created_at = NULL WHERE id = $1`
+
+	_, err := DB.ExecContext(ctx, query, adminID)
+	if err != nil {
+		utils.LogError("clearToken calendar feed repository (UPDATE error)", err)
+		return utils.InternalServerError("error revoking calendar feed")
+	}
+
+	return nil
+}
+
+func (r *CalendarFeedRepository) GetStatus(ctx context.Context, adminID uuid.UUID) (dtos.CalendarFeedOutput, error) {
+	query := `SELECT calendar_feed_created_at FROM clients WHERE id = $1`
+
+	var createdAt sql.NullTime
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(&createdAt)
+	if err != nil {
+		utils.LogError("getStatus calendar feed repository (SELECT error)", err)
+		return dtos.CalendarFeedOutput{}, utils.InternalServerError("error getting calendar feed")
+	}
+
+	if !createdAt.Valid {
+		return dtos.CalendarFeedOutput{Enabled: false}, nil
+	}
+
+	return dtos.CalendarFeedOutput{Enabled: true, CreatedAt: &createdAt.Time}, nil
+}
+
+// GetVersion finds the feed owner and summarizes its appointments from the
+// given date on. It is cheap enough to run on every poll, so unchanged feeds
+// can be answered without rendering them.
+func (r *CalendarFeedRepository) GetVersion(ctx context.Context, tokenHash string, from time.Time) (dtos.CalendarFeedVersion, int, error) {
+	query := `SELECT c.id, c.full_name, c.calendar_feed_created_at,
+	MAX(a.updated_at), COUNT(a.id) FILTER (WHERE a.status <> 'cancelled')
+	FROM clients c
+	LEFT JOIN appointments a ON a.client_id = c.id AND a.date >= $2
+	WHERE c.calendar_feed_token_hash = $1
+	GROUP BY c.id`
+
+	var version dtos.CalendarFeedVersion
+	var createdAt time.Time
+	var updatedAt sql.NullTime
+	var count int
+
+	err := DB.QueryRowContext(ctx, query, tokenHash, from).Scan(&version.ClientID, &version.ClientName, &createdAt, &updatedAt, &count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.CalendarFeedVersion{}, 0, utils.NotFoundError("calendar not found")
+	}
+	if err != nil {
+		utils.LogError("getVersion calendar feed repository (SELECT error)", err)
+		return dtos.CalendarFeedVersion{}, 0, utils.InternalServerError("error getting calendar")
+	}
+
+	version.LastModified = createdAt
+	if updatedAt.Valid && updatedAt.Time.After(createdAt) {
+		version.LastModified = updatedAt.Time
+	}
+
+	return version, count, nil
+}
+
+const feedAppointmentQuery = `SELECT a.id, p.full_name, a.date, a.start_time, a.end_time, a.status, a.modality,
+	a.video_url, COALESCE(l.address, c.office_address, ''), a.ics_sequence, a.updated_at
+	FROM appointments a
+	JOIN patients p ON p.id = a.patient_id
+	JOIN clients c ON c.id = a.client_id
+	LEFT JOIN locations l ON l.id = a.location_id`
+
+func scanFeedAppointment(row interface{ Scan(...any) error }) (dtos.FeedAppointment, error) {
+	var appt dtos.FeedAppointment
+
+	err := row.Scan(
+		&appt.ID,
+		&appt.PatientName,
+		&appt.Date,
+		&appt.StartTime,
+		&appt.EndTime,
+		&appt.Status,
+		&appt.Modality,
+		&appt.VideoURL,
+		&appt.Address,
+		&appt.Sequence,
+		&appt.UpdatedAt,
+	)
+
+	return appt, err
+}
+
+func (r *CalendarFeedRepository) GetAppointments(ctx context.Context, clientID uuid.UUID, from time.Time) ([]dtos.FeedAppointment, error) {
+	query := feedAppointmentQuery + `
+	WHERE a.client_id = $1 AND a.date >= $2 AND a.status <> 'cancelled'
+	ORDER BY a.date, a.start_time`
+
+	rows, err := DB.QueryContext(ctx, query, clientID, from)
+	if err != nil {
+		utils.LogError("getAppointments calendar feed repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting calendar")
+	}
+	defer rows.Close()
+
+	var appointments []dtos.FeedAppointment
+
+	for rows.Next() {
+		appt, err := scanFeedAppointment(rows)
+		if err != nil {
+			utils.LogError("getAppointments calendar feed repository (error scanning rows)", err)
+			return nil, utils.InternalServerError("error getting calendar")
+		}
+
+		appointments = append(appointments, appt)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getAppointments calendar feed repository (rows error)", err)
+		return nil, utils.InternalServerError("error getting calendar")
+	}
+
+	return appointments, nil
+}
+
+func (r *CalendarFeedRepository) GetAppointment(ctx context.Context, clientID, appointmentID uuid.UUID) (dtos.FeedAppointment, error) {
+	query := feedAppointmentQuery + `
+	WHERE a.client_id = $1 AND a.id = $2 AND a.status <> 'cancelled'`
+
+	appt, err := scanFeedAppointment(DB.QueryRowContext(ctx, query, clientID, appointmentID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.FeedAppointment{}, utils.NotFoundError("appointment not found")
+	}
+	if err != nil {
+		utils.LogError("getAppointment calendar feed repository (SELECT error)", err)
+		return dtos.FeedAppointment{}, utils.InternalServerError("error getting calendar")
+	}
+
+	return appt, nil
+}
\ No newline at end of file
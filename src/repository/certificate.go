@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type CertificateRepository struct{}
+
+// CreateCertificate stores the certificate unless the appointment already has
+// one, in which case the earlier certificate stays as it was.
+func (r *CertificateRepository) CreateCertificate(ctx context.Context, db DBTX, certificate dtos.Certificate) error {
+	query := `INSERT INTO attendance_certificates (client_id, patient_id, appointment_id, code, issuer_name, issuer_crp,
+		patient_name, date, start_time, end_time, signature, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	ON CONFLICT (appointment_id) DO NOTHING`
+
+	_, err := db.ExecContext(ctx, query,
+		certificate.ClientID,
+		certificate.PatientID,
+		certificate.AppointmentID,
+		certificate.Code,
+		certificate.IssuerName,
+		certificate.IssuerCRP,
+		certificate.PatientName,
+		certificate.Date,
+		certificate.StartTime,
+		certificate.EndTime,
+		certificate.Signature,
+		certificate.CreatedAt,
+	)
+	if err != nil {
+		utils.LogError("createCertificate certificate repository (INSERT error)", err)
+		return utils.InternalServerError("error creating certificate")
+	}
+
+	return nil
+}
+
+const certificateColumns = `id, client_id, patient_id, appointment_id, code, issuer_name, issuer_crp, patient_name,
+	date, start_time, end_time, signature, created_at`
+
+func scanCertificate(row interface{ Scan(...any) error }) (dtos.Certificate, error) {
+	var certificate dtos.Certificate
+
+	err := row.Scan(
+		&certificate.ID,
+		&certificate.ClientID,
+		&certificate.PatientID,
+		&certificate.AppointmentID,
+		&certificate.Code,
+		&certificate.IssuerName,
+		&certificate.IssuerCRP,
+		&certificate.PatientName,
+		&certificate.Date,
+		&certificate.StartTime,
+		&certificate.EndTime,
+		&certificate.Signature,
+		&certificate.CreatedAt,
+	)
+
+	return certificate, err
+}
+
+func (r *CertificateRepository) GetByAppointment(ctx context.Context, appointmentID uuid.UUID) (dtos.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM attendance_certificates WHERE appointment_id = $1`
+
+	certificate, err := scanCertificate(DB.QueryRowContext(ctx, query, appointmentID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.Certificate{}, utils.NotFoundError("certificate not found")
+	}
+	if err != nil {
+		utils.LogError("getByAppointment certificate repository (SELECT error)", err)
+		return dtos.Certificate{}, utils.InternalServerError("error getting certificate")
+	}
+
+	return certificate, nil
+}
+
+func (r *CertificateRepository) GetByCode(ctx context.Context, code string) (dtos.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM attendance_certificates WHERE code = $1`
+
+	certificate, err := scanCertificate(DB.QueryRowContext(ctx, query, code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.Certificate{}, utils.NotFoundError("certificate not found")
+	}
+	if err != nil {
+		utils.LogError("getByCode certificate repository (SELECT error)", err)
+		return dtos.Certificate{}, utils.InternalServerError("error getting certificate")
+	}
+
+	return certificate, nil
+}
\ No newline at end of file
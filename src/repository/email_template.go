@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type EmailTemplateRepository struct{}
+
+func (r *EmailTemplateRepository) GetBranding(ctx context.Context, adminID uuid.UUID) (dtos.EmailBrandingDB, error) {
+	query := `SELECT full_name, COALESCE(profile_image_url, ''), brand_name, brand_color, brand_logo_url, email_footer
+	FROM clients WHERE id = $1`
+
+	var branding dtos.EmailBrandingDB
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(
+		&branding.FullName,
+		&branding.ProfileImageURL,
+		&branding.ClinicName,
+		&branding.PrimaryColor,
+		&branding.LogoURL,
+		&branding.Footer,
+	)
+	if err != nil {
+		utils.LogError("getBranding repository (SELECT error)", err)
+		return dtos.EmailBrandingDB{}, utils.InternalServerError("error getting email branding")
+	}
+
+	return branding, nil
+}
+
+func (r *EmailTemplateRepository) UpdateBranding(ctx context.Context, adminID uuid.UUID, input dtos.EmailBrandingInput) error {
+	query := `UPDATE clients SET brand_name = $1, brand_color = $2, brand_logo_url = $3, email_footer = $4
+	WHERE id = $5`
+
+	_, err := DB.ExecContext(ctx, query, input.ClinicName, input.PrimaryColor, input.LogoURL, input.Footer, adminID)
+	if err != nil {
+		utils.LogError("updateBranding repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating email branding")
+	}
+
+	return nil
+}
+
+// GetOverride returns the clinic's override for template name, or an empty
+// one when the clinic uses the default.
+func (r *EmailTemplateRepository) GetOverride(ctx context.Context, adminID uuid.UUID, name string) (dtos.EmailTemplateInput, error) {
+	query := `SELECT subject, html, text FROM email_template_overrides WHERE client_id = $1 AND name = $2`
+
+	var override dtos.EmailTemplateInput
+
+	err := DB.QueryRowContext(ctx, query, adminID, name).Scan(&override.Subject, &override.HTML, &override.Text)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.EmailTemplateInput{}, nil
+	}
+	if err != nil {
+		utils.LogError("getOverride repository (SELECT error)", err)
+		return dtos.EmailTemplateInput{}, utils.InternalServerError("error getting email template")
+	}
+
+	return override, nil
+}
+
+func (r *EmailTemplateRepository) GetOverrides(ctx context.Context, adminID uuid.UUID) (map[string]dtos.EmailTemplateInput, error) {
+	query := `SELECT name, subject, html, text FROM email_template_overrides WHERE client_id = $1`
+
+	rows, err := DB.QueryContext(ctx, query, adminID)
+	if err != nil {
+		utils.LogError("getOverrides repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting email templates")
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]dtos.EmailTemplateInput)
+
+	for rows.Next() {
+		var (
+			name string
+			override dtos.EmailTemplateInput
+		)
+
+		err := rows.Scan(&name, &override.Subject, &override.HTML, &override.Text)
+		if err != nil {
+			utils.LogError("getOverrides repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching email templates")
+		}
+
+		overrides[name] = override
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getOverrides repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating email templates")
+	}
+
+	return overrides, nil
+}
+
+func (r *EmailTemplateRepository) UpsertOverride(ctx context.Context, adminID uuid.UUID, name string, input dtos.EmailTemplateInput) error {
+	query := `INSERT INTO email_template_overrides (client_id, name, subject, html, text)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (client_id, name) DO UPDATE
+	SET subject = EXCLUDED.subject, html = EXCLUDED.html, text = EXCLUDED.text, updated_at = NOW()`
+
+	_, err := DB.ExecContext(ctx, query, adminID, name, input.Subject, input.HTML, input.Text)
+	if err != nil {
+		utils.LogError("upsertOverride repository (INSERT error)", err)
+		return utils.InternalServerError("error saving email template")
+	}
+
+	return nil
+}
+
+func (r *EmailTemplateRepository) DeleteOverride(ctx context.Context, adminID uuid.UUID, name string) error {
+	query := `DELETE FROM email_template_overrides WHERE client_id = $1 AND name = $2`
+
+	res, err := DB.ExecContext(ctx, query, adminID, name)
+	if err != nil {
+		utils.LogError("deleteOverride repository (DELETE error)", err)
+		return utils.InternalServerError("error deleting email template")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("deleteOverride repository (error reading rows affected)", err)
+		return utils.InternalServerError("error deleting email template")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("email template override not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,586 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/lib/pq"
+)
+
+type InsuranceRepository struct{}
+
+const planColumns = `id, name, ans_code, session_price_cents, active, created_at`
+
+func scanPlan(row interface{ Scan(...any) error }) (dtos.InsurancePlanOutput, error) {
+	var plan dtos.InsurancePlanOutput
+
+	err := row.Scan(&plan.ID, &plan.Name, &plan.ANSCode, &plan.SessionPriceCents, &plan.Active, &plan.CreatedAt)
+
+	return plan, err
+}
+
+func (r *InsuranceRepository) CreatePlan(ctx context.Context, clientID uuid.UUID, input dtos.InsurancePlanInput, active bool) (dtos.InsurancePlanOutput, error) {
+	query := `INSERT INTO insurance_plans (client_id, name, ans_code, session_price_cents, active)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING ` + planColumns
+
+	plan, err := scanPlan(DB.QueryRowContext(ctx, query, clientID, input.Name, input.ANSCode, *input.SessionPriceCents, active))
+	if err != nil {
+		utils.LogError("createPlan insurance repository (INSERT error)", err)
+		return dtos.InsurancePlanOutput{}, utils.InternalServerError("error creating insurance plan")
+	}
+
+	return plan, nil
+}
+
+// UpdatePlan does not change batches already generated, which keep the
+// amounts they were submitted with.
+func (r *InsuranceRepository) UpdatePlan(ctx context.Context, id, clientID uuid.UUID, input dtos.InsurancePlanInput, active bool) (dtos.InsurancePlanOutput, error) {
+	query := `UPDATE insurance_plans
+	SET name = $1, ans_code = $2, session_price_cents = $3, active = $4
+	WHERE id = $5 AND client_id = $6
+	RETURNING ` + planColumns
+
+	plan, err := scanPlan(DB.QueryRowContext(ctx, query, input.Name, input.ANSCode, *input.SessionPriceCents, active, id, clientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.InsurancePlanOutput{}, utils.NotFoundError("insurance plan not found")
+	}
+	if err != nil {
+		utils.LogError("updatePlan insurance repository (UPDATE error)", err)
+		return dtos.InsurancePlanOutput{}, utils.InternalServerError("error updating insurance plan")
+	}
+
+	return plan, nil
+}
+
+// DeactivatePlan stops the plan from taking new patients. Cards, guides and
+// batches already on it are kept.
+func (r *InsuranceRepository) DeactivatePlan(ctx context.Context, id, clientID uuid.UUID) error {
+	query := `UPDATE insurance_plans SET active = FALSE WHERE id = $1 AND client_id = $2`
+
+	res, err := DB.ExecContext(ctx, query, id, clientID)
+	if err != nil {
+		utils.LogError("deactivatePlan insurance repository (UPDATE error)", err)
+		return utils.InternalServerError("error deleting insurance plan")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("deactivatePlan insurance repository (error reading rows affected)", err)
+		return utils.InternalServerError("error deleting insurance plan")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("insurance plan not found")
+	}
+
+	return nil
+}
+
+func (r *InsuranceRepository) GetPlans(ctx context.Context, clientID uuid.UUID) ([]dtos.InsurancePlanOutput, error) {
+	query := `SELECT ` + planColumns + ` FROM insurance_plans WHERE client_id = $1 ORDER BY active DESC, name`
+
+	rows, err := DB.QueryContext(ctx, query, clientID)
+	if err != nil {
+		utils.LogError("getPlans insurance repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting insurance plans")
+	}
+	defer rows.Close()
+
+	plans := make([]dtos.InsurancePlanOutput, 0)
+
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			utils.LogError("getPlans insurance repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching insurance plans")
+		}
+
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getPlans insurance repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating insurance plans")
+	}
+
+	return plans, nil
+}
+
+// GetPlan returns one of the psychologist's plans. With lock, the plan's row
+// stays locked until tx ends, so two batches cannot bill the same sessions.
+func (r *InsuranceRepository) GetPlan(ctx context.Context, db DBTX, id, clientID uuid.UUID, lock bool) (dtos.InsurancePlanOutput, error) {
+	query := `SELECT ` + planColumns + ` FROM insurance_plans WHERE id = $1 AND client_id = $2`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	plan, err := scanPlan(db.QueryRowContext(ctx, query, id, clientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.InsurancePlanOutput{}, utils.NotFoundError("insurance plan not found")
+	}
+	if err != nil {
+		utils.LogError("getPlan insurance repository (SELECT error)", err)
+		return dtos.InsurancePlanOutput{}, utils.InternalServerError("error getting insurance plan")
+	}
+
+	return plan, nil
+}
+
+const coverageQuery = `SELECT pc.id, pc.patient_id, pc.plan_id, ip.name, pc.card_number, pc.valid_until, pc.created_at
+	FROM patient_coverages pc
+	JOIN insurance_plans ip ON ip.id = pc.plan_id`
+
+func scanCoverage(row interface{ Scan(...any) error }) (dtos.CoverageOutput, error) {
+	var (
+		coverage   dtos.CoverageOutput
+		validUntil sql.NullTime
+	)
+
+	err := row.Scan(
+		&coverage.ID,
+		&coverage.PatientID,
+		&coverage.PlanID,
+		&coverage.PlanName,
+		&coverage.CardNumber,
+		&validUntil,
+		&coverage.CreatedAt,
+	)
+	if err != nil {
+		return dtos.CoverageOutput{}, err
+	}
+
+	if validUntil.Valid {
+		date := validUntil.Time.Format("2006-01-02")
+		coverage.ValidUntil = &date
+	}
+
+	return coverage, nil
+}
+
+// CreateCoverage adds the patient's card on an active plan. Both the patient
+// and the plan must belong to clientID.
+func (r *InsuranceRepository) CreateCoverage(ctx context.Context, patientID, clientID, planID uuid.UUID, cardNumber string, validUntil sql.NullTime) (uuid.UUID, error) {
+	query := `INSERT INTO patient_coverages (client_id, patient_id, plan_id, card_number, valid_until)
+	SELECT p.client_id, p.id, ip.id, $4, $5
+	FROM patients p
+	JOIN insurance_plans ip ON ip.client_id = p.client_id
+	WHERE p.id = $1 AND p.client_id = $2 AND ip.id = $3 AND ip.active
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err := DB.QueryRowContext(ctx, query, patientID, clientID, planID, cardNumber, validUntil).Scan(&id)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return uuid.UUID{}, utils.ConflictError("the patient already has a card on this plan")
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, utils.NotFoundError("patient or insurance plan not found")
+	}
+	if err != nil {
+		utils.LogError("createCoverage insurance repository (INSERT error)", err)
+		return uuid.UUID{}, utils.InternalServerError("error adding insurance card")
+	}
+
+	return id, nil
+}
+
+func (r *InsuranceRepository) GetCoverage(ctx context.Context, id, clientID uuid.UUID) (dtos.CoverageOutput, error) {
+	query := coverageQuery + ` WHERE pc.id = $1 AND pc.client_id = $2`
+
+	coverage, err := scanCoverage(DB.QueryRowContext(ctx, query, id, clientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.CoverageOutput{}, utils.NotFoundError("insurance card not found")
+	}
+	if err != nil {
+		utils.LogError("getCoverage insurance repository (SELECT error)", err)
+		return dtos.CoverageOutput{}, utils.InternalServerError("error getting insurance card")
+	}
+
+	return coverage, nil
+}
+
+func (r *InsuranceRepository) GetCoverages(ctx context.Context, patientID, clientID uuid.UUID) ([]dtos.CoverageOutput, error) {
+	query := coverageQuery + ` WHERE pc.patient_id = $1 AND pc.client_id = $2 ORDER BY ip.name`
+
+	rows, err := DB.QueryContext(ctx, query, patientID, clientID)
+	if err != nil {
+		utils.LogError("getCoverages insurance repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting insurance cards")
+	}
+	defer rows.Close()
+
+	coverages := make([]dtos.CoverageOutput, 0)
+
+	for rows.Next() {
+		coverage, err := scanCoverage(rows)
+		if err != nil {
+			utils.LogError("getCoverages insurance repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching insurance cards")
+		}
+
+		coverages = append(coverages, coverage)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getCoverages insurance repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating insurance cards")
+	}
+
+	return coverages, nil
+}
+
+// used counts the appointments that take a session from the guide
+const authorizationQuery = `SELECT ia.id, ia.coverage_id, ia.number, ia.sessions, ia.valid_from, ia.valid_until, ia.created_at,
+	pc.patient_id, pc.valid_until,
+	(SELECT COUNT(*) FROM appointments a
+		WHERE a.authorization_id = ia.id AND a.status IN ('scheduled', 'confirmed', 'completed'))
+	FROM insurance_authorizations ia
+	JOIN patient_coverages pc ON pc.id = ia.coverage_id`
+
+func scanAuthorization(row interface{ Scan(...any) error }) (dtos.Authorization, error) {
+	var (
+		authorization  dtos.Authorization
+		validFrom      time.Time
+		validUntil     time.Time
+		cardValidUntil sql.NullTime
+	)
+
+	err := row.Scan(
+		&authorization.ID,
+		&authorization.CoverageID,
+		&authorization.Number,
+		&authorization.Sessions,
+		&validFrom,
+		&validUntil,
+		&authorization.CreatedAt,
+		&authorization.PatientID,
+		&cardValidUntil,
+		&authorization.Used,
+	)
+	if err != nil {
+		return dtos.Authorization{}, err
+	}
+
+	authorization.ValidFrom = validFrom.Format("2006-01-02")
+	authorization.ValidUntil = validUntil.Format("2006-01-02")
+	authorization.Remaining = max(authorization.Sessions-authorization.Used, 0)
+	authorization.Exceeded = authorization.Used > authorization.Sessions
+
+	if cardValidUntil.Valid {
+		date := cardValidUntil.Time.Format("2006-01-02")
+		authorization.CardValidUntil = &date
+	}
+
+	return authorization, nil
+}
+
+func (r *InsuranceRepository) CreateAuthorization(ctx context.Context, coverageID, clientID uuid.UUID, input dtos.AuthorizationInput, validFrom, validUntil time.Time) (uuid.UUID, error) {
+	query := `INSERT INTO insurance_authorizations (client_id, coverage_id, number, sessions, valid_from, valid_until)
+	SELECT client_id, id, $3, $4, $5, $6 FROM patient_coverages WHERE id = $1 AND client_id = $2
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err := DB.QueryRowContext(ctx, query, coverageID, clientID, input.Number, input.Sessions, validFrom, validUntil).Scan(&id)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return uuid.UUID{}, utils.ConflictError("this authorization number is already registered")
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, utils.NotFoundError("insurance card not found")
+	}
+	if err != nil {
+		utils.LogError("createAuthorization insurance repository (INSERT error)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating authorization")
+	}
+
+	return id, nil
+}
+
+func (r *InsuranceRepository) GetAuthorization(ctx context.Context, id, clientID uuid.UUID) (dtos.Authorization, error) {
+	query := authorizationQuery + ` WHERE ia.id = $1 AND ia.client_id = $2`
+
+	authorization, err := scanAuthorization(DB.QueryRowContext(ctx, query, id, clientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.Authorization{}, utils.NotFoundError("authorization not found")
+	}
+	if err != nil {
+		utils.LogError("getAuthorization insurance repository (SELECT error)", err)
+		return dtos.Authorization{}, utils.InternalServerError("error getting authorization")
+	}
+
+	return authorization, nil
+}
+
+// GetAuthorizations lists the guides issued for a card, newest first.
+func (r *InsuranceRepository) GetAuthorizations(ctx context.Context, coverageID, clientID uuid.UUID) ([]dtos.AuthorizationOutput, error) {
+	query := authorizationQuery + ` WHERE ia.coverage_id = $1 AND ia.client_id = $2 ORDER BY ia.valid_from DESC`
+
+	rows, err := DB.QueryContext(ctx, query, coverageID, clientID)
+	if err != nil {
+		utils.LogError("getAuthorizations insurance repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting authorizations")
+	}
+	defer rows.Close()
+
+	authorizations := make([]dtos.AuthorizationOutput, 0)
+
+	for rows.Next() {
+		authorization, err := scanAuthorization(rows)
+		if err != nil {
+			utils.LogError("getAuthorizations insurance repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching authorizations")
+		}
+
+		authorizations = append(authorizations, authorization.AuthorizationOutput)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getAuthorizations insurance repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating authorizations")
+	}
+
+	return authorizations, nil
+}
+
+// SetAppointmentAuthorization puts the appointment under a guide, or takes it
+// out with a null authorizationID. Appointments already billed in a batch
+// cannot be moved.
+func (r *InsuranceRepository) SetAppointmentAuthorization(ctx context.Context, appointmentID, clientID uuid.UUID, authorizationID uuid.NullUUID) error {
+	query := `UPDATE appointments SET authorization_id = $1
+	WHERE id = $2 AND client_id = $3
+	AND NOT EXISTS (SELECT 1 FROM insurance_batch_items WHERE appointment_id = $2)`
+
+	res, err := DB.ExecContext(ctx, query, authorizationID, appointmentID, clientID)
+	if err != nil {
+		utils.LogError("setAppointmentAuthorization insurance repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating appointment authorization")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("setAppointmentAuthorization insurance repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating appointment authorization")
+	}
+
+	if rows == 0 {
+		return utils.BadRequestError("the appointment was not found or was already billed to the plan")
+	}
+
+	return nil
+}
+
+// GetUnbilledSessions returns the completed sessions in [from, to) performed
+// under guides of the plan that no batch has billed yet, priced at amount.
+func (r *InsuranceRepository) GetUnbilledSessions(ctx context.Context, db DBTX, planID, clientID uuid.UUID, from, to time.Time, amount int64) ([]dtos.InsuranceBatchItem, error) {
+	query := `SELECT a.id, p.full_name, pc.card_number, ia.number, a.date, a.start_time, a.end_time
+	FROM appointments a
+	JOIN insurance_authorizations ia ON ia.id = a.authorization_id
+	JOIN patient_coverages pc ON pc.id = ia.coverage_id
+	JOIN patients p ON p.id = a.patient_id
+	WHERE pc.plan_id = $1 AND a.client_id = $2 AND a.status = 'completed'
+	AND a.date >= $3 AND a.date < $4
+	AND NOT EXISTS (SELECT 1 FROM insurance_batch_items b WHERE b.appointment_id = a.id)
+	ORDER BY a.date, a.start_time`
+
+	rows, err := db.QueryContext(ctx, query, planID, clientID, from, to)
+	if err != nil {
+		utils.LogError("getUnbilledSessions insurance repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting sessions")
+	}
+	defer rows.Close()
+
+	items := make([]dtos.InsuranceBatchItem, 0)
+
+	for rows.Next() {
+		var (
+			item          dtos.InsuranceBatchItem
+			appointmentID uuid.UUID
+			date          time.Time
+			startTime     time.Time
+			endTime       time.Time
+		)
+
+		err := rows.Scan(&appointmentID, &item.PatientName, &item.CardNumber, &item.AuthorizationNumber, &date, &startTime, &endTime)
+		if err != nil {
+			utils.LogError("getUnbilledSessions insurance repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching sessions")
+		}
+
+		item.AppointmentID = &appointmentID
+		item.Date = date.Format("2006-01-02")
+		item.StartTime = startTime.Format("15:04")
+		item.EndTime = endTime.Format("15:04")
+		item.AmountCents = amount
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getUnbilledSessions insurance repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating sessions")
+	}
+
+	return items, nil
+}
+
+// CreateBatch stores the batch and its items. The plan's row must be locked
+// by tx (see GetPlan) so no session ends up in two batches.
+func (r *InsuranceRepository) CreateBatch(ctx context.Context, tx DBTX, clientID uuid.UUID, batch dtos.InsuranceBatchOutput, period time.Time) (uuid.UUID, error) {
+	query := `INSERT INTO insurance_batches (client_id, plan_id, plan_name, period, total_cents)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err := tx.QueryRowContext(ctx, query, clientID, batch.PlanID, batch.PlanName, period, batch.TotalCents).Scan(&id)
+	if err != nil {
+		utils.LogError("createBatch insurance repository (INSERT error)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating billing batch")
+	}
+
+	itemQuery := `INSERT INTO insurance_batch_items (batch_id, appointment_id, patient_name, card_number, authorization_number,
+		date, start_time, end_time, amount_cents)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	for _, item := range batch.Items {
+		_, err := tx.ExecContext(ctx, itemQuery,
+			id,
+			item.AppointmentID,
+			item.PatientName,
+			item.CardNumber,
+			item.AuthorizationNumber,
+			item.Date,
+			item.StartTime,
+			item.EndTime,
+			item.AmountCents,
+		)
+		if err != nil {
+			utils.LogError("createBatch insurance repository (INSERT item error)", err)
+			return uuid.UUID{}, utils.InternalServerError("error creating billing batch")
+		}
+	}
+
+	return id, nil
+}
+
+const batchQuery = `SELECT b.id, b.plan_id, b.plan_name, b.period, b.total_cents, b.created_at,
+	(SELECT COUNT(*) FROM insurance_batch_items i WHERE i.batch_id = b.id)
+	FROM insurance_batches b`
+
+func scanBatch(row interface{ Scan(...any) error }) (dtos.InsuranceBatchOutput, error) {
+	var (
+		batch  dtos.InsuranceBatchOutput
+		period time.Time
+	)
+
+	err := row.Scan(&batch.ID, &batch.PlanID, &batch.PlanName, &period, &batch.TotalCents, &batch.CreatedAt, &batch.Sessions)
+	if err != nil {
+		return dtos.InsuranceBatchOutput{}, err
+	}
+
+	batch.Month = period.Format("2006-01")
+
+	return batch, nil
+}
+
+// GetBatch returns the batch with its items.
+func (r *InsuranceRepository) GetBatch(ctx context.Context, id, clientID uuid.UUID) (dtos.InsuranceBatchOutput, error) {
+	query := batchQuery + ` WHERE b.id = $1 AND b.client_id = $2`
+
+	batch, err := scanBatch(DB.QueryRowContext(ctx, query, id, clientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.InsuranceBatchOutput{}, utils.NotFoundError("billing batch not found")
+	}
+	if err != nil {
+		utils.LogError("getBatch insurance repository (SELECT error)", err)
+		return dtos.InsuranceBatchOutput{}, utils.InternalServerError("error getting billing batch")
+	}
+
+	itemQuery := `SELECT appointment_id, patient_name, card_number, authorization_number, date, start_time, end_time, amount_cents
+	FROM insurance_batch_items WHERE batch_id = $1
+	ORDER BY date, start_time`
+
+	rows, err := DB.QueryContext(ctx, itemQuery, id)
+	if err != nil {
+		utils.LogError("getBatch insurance repository (SELECT items error)", err)
+		return dtos.InsuranceBatchOutput{}, utils.InternalServerError("error getting billing batch")
+	}
+	defer rows.Close()
+
+	batch.Items = make([]dtos.InsuranceBatchItem, 0)
+
+	for rows.Next() {
+		var (
+			item          dtos.InsuranceBatchItem
+			appointmentID uuid.NullUUID
+			date          time.Time
+			startTime     time.Time
+			endTime       time.Time
+		)
+
+		err := rows.Scan(&appointmentID, &item.PatientName, &item.CardNumber, &item.AuthorizationNumber, &date, &startTime, &endTime, &item.AmountCents)
+		if err != nil {
+			utils.LogError("getBatch insurance repository (scan error)", err)
+			return dtos.InsuranceBatchOutput{}, utils.InternalServerError("error fetching billing batch")
+		}
+
+		item.AppointmentID = utils.NullUUIDPtr(appointmentID)
+		item.Date = date.Format("2006-01-02")
+		item.StartTime = startTime.Format("15:04")
+		item.EndTime = endTime.Format("15:04")
+
+		batch.Items = append(batch.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getBatch insurance repository (rows error)", err)
+		return dtos.InsuranceBatchOutput{}, utils.InternalServerError("error iterating billing batch")
+	}
+
+	return batch, nil
+}
+
+// GetBatches lists batches, newest month first. A zero planID lists the
+// batches of every plan.
+func (r *InsuranceRepository) GetBatches(ctx context.Context, clientID, planID uuid.UUID) ([]dtos.InsuranceBatchOutput, error) {
+	query := batchQuery + ` WHERE b.client_id = $1 AND ($2::uuid IS NULL OR b.plan_id = $2)
+	ORDER BY b.period DESC, b.created_at DESC`
+
+	plan := uuid.NullUUID{UUID: planID, Valid: planID != uuid.Nil}
+
+	rows, err := DB.QueryContext(ctx, query, clientID, plan)
+	if err != nil {
+		utils.LogError("getBatches insurance repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting billing batches")
+	}
+	defer rows.Close()
+
+	batches := make([]dtos.InsuranceBatchOutput, 0)
+
+	for rows.Next() {
+		batch, err := scanBatch(rows)
+		if err != nil {
+			utils.LogError("getBatches insurance repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching billing batches")
+		}
+
+		batches = append(batches, batch)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getBatches insurance repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating billing batches")
+	}
+
+	return batches, nil
+}
\ No newline at end of file
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type LocationRepository struct{}
+
+func (r *LocationRepository) CreateLocation(ctx context.Context, input dtos.LocationInput, adminID uuid.UUID) (uuid.UUID, error) {
+	query := `INSERT INTO locations (client_id, name, address)
+	VALUES ($1, $2, $3)
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err := DB.QueryRowContext(ctx, query, adminID, input.Name, input.Address).Scan(&id)
+	if err != nil {
+		utils.LogError("createLocation repository (error in INSERT)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating location")
+	}
+
+	return id, nil
+}
+
+func (r *LocationRepository) GetLocations(ctx context.Context, adminID uuid.UUID) ([]dtos.LocationOutput, error) {
+	query := `SELECT id, name, address FROM locations WHERE client_id = $1 ORDER BY name`
+
+	rows, err := DB.QueryContext(ctx, query, adminID)
+	if err != nil {
+		utils.LogError("getLocations repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting locations")
+	}
+	defer rows.Close()
+
+	locations := make([]dtos.LocationOutput, 0)
+
+	for rows.Next() {
+		var location dtos.LocationOutput
+
+		err := rows.Scan(&location.ID, &location.Name, &location.Address)
+		if err != nil {
+			utils.LogError("getLocations repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching locations")
+		}
+
+		locations = append(locations, location)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getLocations repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating locations")
+	}
+
+	return locations, nil
+}
+
+func (r *LocationRepository) GetLocationByID(ctx context.Context, locationID, adminID uuid.UUID) (dtos.LocationOutput, error) {
+	query := `SELECT id, name, address FROM locations WHERE id = $1 AND client_id = $2`
+
+	var location dtos.LocationOutput
+
+	err := DB.QueryRowContext(ctx, query, locationID, adminID).Scan(&location.ID, &location.Name, &location.Address)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.LocationOutput{}, utils.NotFoundError("location not found")
+	}
+	if err != nil {
+		utils.LogError("getLocationByID repository (SELECT error)", err)
+		return dtos.LocationOutput{}, utils.InternalServerError("error getting location")
+	}
+
+	return location, nil
+}
+
+func (r *LocationRepository) DeleteLocation(ctx context.Context, locationID, adminID uuid.UUID) error {
+	query := `DELETE FROM locations WHERE id = $1 AND client_id = $2`
+
+	res, err := DB.ExecContext(ctx, query, locationID, adminID)
+	if err != nil {
+		utils.LogError("deleteLocation repository (error deleting location)", err)
+		return utils.InternalServerError("error deleting location")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("deleteLocation repository (error reading rows affected)", err)
+		return utils.InternalServerError("error deleting location")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("location not found")
+	}
+
+	return nil
+}
\ No newline at end of file
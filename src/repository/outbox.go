@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/notify"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type OutboxRepository struct{}
+
+// Enqueue stores msg for delivery by the outbox worker. Pass the transaction
+// of the business change so the email is only queued if the change commits.
+func (r *OutboxRepository) Enqueue(ctx context.Context, db DBTX, clientID uuid.UUID, msg mailer.Message) error {
+	query := `INSERT INTO email_outbox (client_id, recipient, subject, payload)
+	VALUES ($1, $2, $3, $4)`
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		utils.LogError("enqueue outbox repository (error encoding message)", err)
+		return utils.InternalServerError("error queueing email")
+	}
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+
+	_, err = db.ExecContext(ctx, query, client, msg.To, msg.Subject, payload)
+	if err != nil {
+		utils.LogError("enqueue outbox repository (error in INSERT)", err)
+		return utils.InternalServerError("error queueing email")
+	}
+
+	return nil
+}
+
+// EnqueueNotification stores an SMS or WhatsApp message for delivery by the
+// outbox worker, in the same way as Enqueue.
+func (r *OutboxRepository) EnqueueNotification(ctx context.Context, db DBTX, clientID uuid.UUID, msg notify.Message) error {
+	query := `INSERT INTO email_outbox (client_id, channel, recipient, subject, payload)
+	VALUES ($1, $2, $3, $4, $5)`
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		utils.LogError("enqueueNotification outbox repository (error encoding message)", err)
+		return utils.InternalServerError("error queueing notification")
+	}
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+
+	// phone messages have no subject, the template name identifies them in the listing
+	subject := msg.Template
+	if subject == "" {
+		subject = msg.Channel
+	}
+
+	_, err = db.ExecContext(ctx, query, client, msg.Channel, msg.To, subject, payload)
+	if err != nil {
+		utils.LogError("enqueueNotification outbox repository (error in INSERT)", err)
+		return utils.InternalServerError("error queueing notification")
+	}
+
+	return nil
+}
+
+// ClaimBatch locks up to limit messages that are due for delivery. Messages
+// stuck in "sending" after their lock expired (e.g. the instance crashed
+// mid-send) are claimed again.
+func (r *OutboxRepository) ClaimBatch(ctx context.Context, limit int, lockFor time.Duration) ([]dtos.OutboxMessage, error) {
+	query := `UPDATE email_outbox SET status = 'sending', attempts = attempts + 1,
+	locked_until = NOW() + make_interval(secs => $2)
+	WHERE id IN (
+		SELECT id FROM email_outbox
+		WHERE (status = 'pending' AND next_attempt_at <= NOW())
+		OR (status = 'sending' AND locked_until < NOW())
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, channel, payload, attempts, max_attempts`
+
+	rows, err := DB.QueryContext(ctx, query, limit, lockFor.Seconds())
+	if err != nil {
+		utils.LogError("claimBatch outbox repository (UPDATE error)", err)
+		return nil, utils.InternalServerError("error claiming outbox messages")
+	}
+	defer rows.Close()
+
+	messages := make([]dtos.OutboxMessage, 0)
+
+	for rows.Next() {
+		var message dtos.OutboxMessage
+
+		err := rows.Scan(&message.ID, &message.Channel, &message.Payload, &message.Attempts, &message.MaxAttempts)
+		if err != nil {
+			utils.LogError("claimBatch outbox repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching outbox messages")
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("claimBatch outbox repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating outbox messages")
+	}
+
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE email_outbox SET status = 'sent', sent_at = NOW(), locked_until = NULL, last_error = NULL
+	WHERE id = $1`
+
+	_, err := DB.ExecContext(ctx, query, id)
+	if err != nil {
+		utils.LogError("markSent outbox repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating outbox message")
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, status string, nextAttemptAt time.Time, lastError string) error {
+	query := `UPDATE email_outbox SET status = $1, next_attempt_at = $2, last_error = $3, locked_until = NULL
+	WHERE id = $4`
+
+	_, err := DB.ExecContext(ctx, query, status, nextAttemptAt, lastError, id)
+	if err != nil {
+		utils.LogError("markFailed outbox repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating outbox message")
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) GetMessages(ctx context.Context, adminID uuid.UUID, status string, page, limit int) ([]dtos.OutboxOutput, int, error) {
+	query := `SELECT id, channel, recipient, subject, status, attempts, COALESCE(last_error, ''), next_attempt_at, created_at, sent_at
+	FROM email_outbox
+	WHERE client_id = $1 AND ($2 = '' OR status = $2)
+	ORDER BY created_at DESC LIMIT $3 OFFSET $4`
+
+	queryCount := `SELECT COUNT(*) FROM email_outbox WHERE client_id = $1 AND ($2 = '' OR status = $2)`
+
+	offset := (page - 1) * limit
+
+	var total int
+
+	err := DB.QueryRowContext(ctx, queryCount, adminID, status).Scan(&total)
+	if err != nil {
+		return nil, 0, utils.InternalServerError("error getting total outbox messages")
+	}
+
+	rows, err := DB.QueryContext(ctx, query, adminID, status, limit, offset)
+	if err != nil {
+		utils.LogError("getMessages outbox repository (SELECT error)", err)
+		return nil, 0, utils.InternalServerError("error getting outbox messages")
+	}
+	defer rows.Close()
+
+	messages := make([]dtos.OutboxOutput, 0)
+
+	for rows.Next() {
+		var (
+			message dtos.OutboxOutput
+			sentAt sql.NullTime
+		)
+
+		err := rows.Scan(
+			&message.ID,
+			&message.Channel,
+			&message.Recipient,
+			&message.Subject,
+			&message.Status,
+			&message.Attempts,
+			&message.LastError,
+			&message.NextAttemptAt,
+			&message.CreatedAt,
+			&sentAt,
+		)
+		if err != nil {
+			utils.LogError("getMessages outbox repository (scan error)", err)
+			return nil, 0, utils.InternalServerError("error fetching outbox messages")
+		}
+
+		if sentAt.Valid {
+			message.SentAt = &sentAt.Time
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getMessages outbox repository (rows error)", err)
+		return nil, 0, utils.InternalServerError("error iterating outbox messages")
+	}
+
+	return messages, total, nil
+}
+
+// Resend puts a failed message back in the queue with a fresh attempt budget.
+func (r *OutboxRepository) Resend(ctx context.Context, id, adminID uuid.UUID) error {
+	query := `UPDATE email_outbox SET status = 'pending', attempts = 0, next_attempt_at = NOW(), locked_until = NULL
+	WHERE id = $1 AND client_id = $2 AND status IN ('dead', 'pending')`
+
+	res, err := DB.ExecContext(ctx, query, id, adminID)
+	if err != nil {
+		utils.LogError("resend outbox repository (UPDATE error)", err)
+		return utils.InternalServerError("error resending message")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("resend outbox repository (error reading rows affected)", err)
+		return utils.InternalServerError("error resending message")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("failed message not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type PackageRepository struct{}
+
+const packageColumns = `id, name, sessions, price_cents, validity_days, active, created_at`
+
+func scanPackage(row interface{ Scan(...any) error }) (dtos.PackageOutput, error) {
+	var (
+		pkg      dtos.PackageOutput
+		validity sql.NullInt64
+	)
+
+	err := row.Scan(
+		&pkg.ID,
+		&pkg.Name,
+		&pkg.Sessions,
+		&pkg.PriceCents,
+		&validity,
+		&pkg.Active,
+		&pkg.CreatedAt,
+	)
+	if err != nil {
+		return dtos.PackageOutput{}, err
+	}
+
+	if validity.Valid {
+		days := int(validity.Int64)
+		pkg.ValidityDays = &days
+	}
+
+	pkg.SessionPriceCents = pkg.PriceCents / int64(pkg.Sessions)
+
+	return pkg, nil
+}
+
+func (r *PackageRepository) CreatePackage(ctx context.Context, clientID uuid.UUID, input dtos.PackageInput, active bool) (dtos.PackageOutput, error) {
+	query := `INSERT INTO packages (client_id, name, sessions, price_cents, validity_days, active)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING ` + packageColumns
+
+	pkg, err := scanPackage(DB.QueryRowContext(ctx, query, clientID, input.Name, input.Sessions, *input.PriceCents, input.ValidityDays, active))
+	if err != nil {
+		utils.LogError("createPackage package repository (INSERT error)", err)
+		return dtos.PackageOutput{}, utils.InternalServerError("error creating package")
+	}
+
+	return pkg, nil
+}
+
+// UpdatePackage only affects future purchases, as each purchase keeps a copy
+// of the package's terms.
+func (r *PackageRepository) UpdatePackage(ctx context.Context, id, clientID uuid.UUID, input dtos.PackageInput, active bool) (dtos.PackageOutput, error) {
+	query := `UPDATE packages
+	SET name = $1, sessions = $2, price_cents = $3, validity_days = $4, active = $5
+	WHERE id = $6 AND client_id = $7
+	RETURNING ` + packageColumns
+
+	pkg, err := scanPackage(DB.QueryRowContext(ctx, query, input.Name, input.Sessions, *input.PriceCents, input.ValidityDays, active, id, clientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.PackageOutput{}, utils.NotFoundError("package not found")
+	}
+	if err != nil {
+		utils.LogError("updatePackage package repository (UPDATE error)", err)
+		return dtos.PackageOutput{}, utils.InternalServerError("error updating package")
+	}
+
+	return pkg, nil
+}
+
+// DeactivatePackage takes the package off sale. Purchases already made are
+// kept.
+func (r *PackageRepository) DeactivatePackage(ctx context.Context, id, clientID uuid.UUID) error {
+	query := `UPDATE packages SET active = FALSE WHERE id = $1 AND client_id = $2`
+
+	res, err := DB.ExecContext(ctx, query, id, clientID)
+	if err != nil {
+		utils.LogError("deactivatePackage package repository (UPDATE error)", err)
+		return utils.InternalServerError("error deleting package")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("deactivatePackage package repository (error reading rows affected)", err)
+		return utils.InternalServerError("error deleting package")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("package not found")
+	}
+
+	return nil
+}
+
+// GetPackages lists the psychologist's packages. Patients pass their own id
+// instead of a clientID and only see packages on sale.
+func (r *PackageRepository) GetPackages(ctx context.Context, clientID, patientID uuid.UUID) ([]dtos.PackageOutput, error) {
+	query := `SELECT ` + packageColumns + `
+	FROM packages
+	WHERE ($1::uuid IS NOT NULL AND client_id = $1)
+	OR ($2::uuid IS NOT NULL AND active AND client_id = (SELECT client_id FROM patients WHERE id = $2))
+	ORDER BY active DESC, sessions, name`
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+	patient := uuid.NullUUID{UUID: patientID, Valid: patientID != uuid.Nil}
+
+	rows, err := DB.QueryContext(ctx, query, client, patient)
+	if err != nil {
+		utils.LogError("getPackages package repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting packages")
+	}
+	defer rows.Close()
+
+	packages := make([]dtos.PackageOutput, 0)
+
+	for rows.Next() {
+		pkg, err := scanPackage(rows)
+		if err != nil {
+			utils.LogError("getPackages package repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching packages")
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getPackages package repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating packages")
+	}
+
+	return packages, nil
+}
+
+// CreatePurchase sells an active package to the patient, copying its terms.
+// today is the purchase date, from which the validity is counted. A zero
+// clientID skips the ownership check, for patients buying from their own
+// psychologist.
+func (r *PackageRepository) CreatePurchase(ctx context.Context, db DBTX, packageID, patientID, clientID uuid.UUID, today string) (dtos.PatientPackage, error) {
+	query := `INSERT INTO patient_packages (client_id, patient_id, package_id, name, sessions, price_cents, expires_on)
+	SELECT k.client_id, p.id, k.id, k.name, k.sessions, k.price_cents, $4::date + k.validity_days
+	FROM packages k
+	JOIN patients p ON p.client_id = k.client_id
+	WHERE k.id = $1 AND p.id = $2 AND k.active AND ($3::uuid IS NULL OR k.client_id = $3)
+	RETURNING id, client_id, patient_id, package_id, name, sessions, price_cents, expires_on, created_at`
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+
+	purchase, err := scanPurchase(db.QueryRowContext(ctx, query, packageID, patientID, client, today))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.PatientPackage{}, utils.NotFoundError("package not found")
+	}
+	if err != nil {
+		utils.LogError("createPurchase package repository (INSERT error)", err)
+		return dtos.PatientPackage{}, utils.InternalServerError("error buying package")
+	}
+
+	purchase.Remaining = purchase.Sessions
+
+	return purchase, nil
+}
+
+func scanPurchase(row interface{ Scan(...any) error }, extra ...any) (dtos.PatientPackage, error) {
+	var (
+		purchase  dtos.PatientPackage
+		packageID uuid.NullUUID
+		expiresOn sql.NullTime
+	)
+
+	dest := []any{
+		&purchase.ID,
+		&purchase.ClientID,
+		&purchase.PatientID,
+		&packageID,
+		&purchase.Name,
+		&purchase.Sessions,
+		&purchase.PriceCents,
+		&expiresOn,
+		&purchase.CreatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return dtos.PatientPackage{}, err
+	}
+
+	purchase.PackageID = utils.NullUUIDPtr(packageID)
+
+	if expiresOn.Valid {
+		date := expiresOn.Time.Format("2006-01-02")
+		purchase.ExpiresOn = &date
+	}
+
+	return purchase, nil
+}
+
+// GetPurchases returns the patient's packages, newest first, with their
+// credits counted. Packages that expired before today are flagged.
+func (r *PackageRepository) GetPurchases(ctx context.Context, patientID uuid.UUID, today string) ([]dtos.PatientPackageOutput, error) {
+	query := `SELECT pp.id, pp.client_id, pp.patient_id, pp.package_id, pp.name, pp.sessions, pp.price_cents, pp.expires_on, pp.created_at,
+		COUNT(c.appointment_id) FILTER (WHERE c.used_at IS NOT NULL),
+		COUNT(c.appointment_id) FILTER (WHERE c.used_at IS NULL),
+		COALESCE(pp.expires_on < $2::date, FALSE)
+	FROM patient_packages pp
+	LEFT JOIN package_credits c ON c.patient_package_id = pp.id
+	WHERE pp.patient_id = $1
+	GROUP BY pp.id
+	ORDER BY pp.created_at DESC`
+
+	rows, err := DB.QueryContext(ctx, query, patientID, today)
+	if err != nil {
+		utils.LogError("getPurchases package repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting credits")
+	}
+	defer rows.Close()
+
+	purchases := make([]dtos.PatientPackageOutput, 0)
+
+	for rows.Next() {
+		var used, reserved int
+		var expired bool
+
+		purchase, err := scanPurchase(rows, &used, &reserved, &expired)
+		if err != nil {
+			utils.LogError("getPurchases package repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching credits")
+		}
+
+		purchase.Used = used
+		purchase.Reserved = reserved
+		purchase.Expired = expired
+
+		if !expired {
+			purchase.Remaining = purchase.Sessions - used - reserved
+		}
+
+		purchases = append(purchases, purchase.PatientPackageOutput)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getPurchases package repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating credits")
+	}
+
+	return purchases, nil
+}
+
+// ReserveCredit holds a credit for the appointment from the patient's package
+// that expires first and is still valid on the appointment's date. Nothing
+// happens when the patient has no such package or a credit is already held.
+// db must be a transaction: the patient's packages stay locked until it ends,
+// so concurrent bookings cannot both take the last credit.
+func (r *PackageRepository) ReserveCredit(ctx context.Context, db DBTX, appointmentID uuid.UUID) error {
+	queryLock := `SELECT pp.id
+	FROM appointments a
+	JOIN patient_packages pp ON pp.patient_id = a.patient_id AND pp.client_id = a.client_id
+	WHERE a.id = $1
+	ORDER BY pp.id
+	FOR UPDATE OF pp`
+
+	if _, err := db.ExecContext(ctx, queryLock, appointmentID); err != nil {
+		utils.LogError("reserveCredit package repository (SELECT error)", err)
+		return utils.InternalServerError("error reserving package credit")
+	}
+
+	// a separate statement, so the credits are counted after the lock is held
+	query := `INSERT INTO package_credits (appointment_id, patient_package_id)
+	SELECT a.id, pp.id
+	FROM appointments a
+	JOIN patient_packages pp ON pp.patient_id = a.patient_id AND pp.client_id = a.client_id
+	WHERE a.id = $1
+	AND (pp.expires_on IS NULL OR pp.expires_on >= a.date)
+	AND pp.sessions > (SELECT COUNT(*) FROM package_credits c WHERE c.patient_package_id = pp.id)
+	ORDER BY pp.expires_on NULLS LAST, pp.created_at
+	LIMIT 1
+	ON CONFLICT DO NOTHING`
+
+	_, err := db.ExecContext(ctx, query, appointmentID)
+	if err != nil {
+		utils.LogError("reserveCredit package repository (INSERT error)", err)
+		return utils.InternalServerError("error reserving package credit")
+	}
+
+	return nil
+}
+
+// UseCredit spends the appointment's credit, reserving one first if none is
+// held (e.g. the package was bought after booking). A reservation on a
+// package that expires before the appointment's date, after a reschedule,
+// is swapped for a valid one. It reports whether a credit covered the
+// appointment.
+func (r *PackageRepository) UseCredit(ctx context.Context, db DBTX, appointmentID uuid.UUID) (bool, error) {
+	queryExpired := `DELETE FROM package_credits c
+	USING patient_packages pp, appointments a
+	WHERE c.appointment_id = $1 AND c.used_at IS NULL
+	AND pp.id = c.patient_package_id AND a.id = c.appointment_id
+	AND pp.expires_on < a.date`
+
+	if _, err := db.ExecContext(ctx, queryExpired, appointmentID); err != nil {
+		utils.LogError("useCredit package repository (DELETE error)", err)
+		return false, utils.InternalServerError("error using package credit")
+	}
+
+	if err := r.ReserveCredit(ctx, db, appointmentID); err != nil {
+		return false, err
+	}
+
+	query := `UPDATE package_credits SET used_at = COALESCE(used_at, NOW()) WHERE appointment_id = $1`
+
+	res, err := db.ExecContext(ctx, query, appointmentID)
+	if err != nil {
+		utils.LogError("useCredit package repository (UPDATE error)", err)
+		return false, utils.InternalServerError("error using package credit")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("useCredit package repository (error reading rows affected)", err)
+		return false, utils.InternalServerError("error using package credit")
+	}
+
+	return rows > 0, nil
+}
+
+// ReleaseCredit returns the credit held by the appointment to its package.
+// Credits already used are kept.
+func (r *PackageRepository) ReleaseCredit(ctx context.Context, db DBTX, appointmentID uuid.UUID) error {
+	query := `DELETE FROM package_credits WHERE appointment_id = $1 AND used_at IS NULL`
+
+	_, err := db.ExecContext(ctx, query, appointmentID)
+	if err != nil {
+		utils.LogError("releaseCredit package repository (DELETE error)", err)
+		return utils.InternalServerError("error returning package credit")
+	}
+
+	return nil
+}
+
+// ReturnCredit gives the appointment's credit back to its package, whether it
+// was used or only reserved. It reports whether there was a credit.
+func (r *PackageRepository) ReturnCredit(ctx context.Context, db DBTX, appointmentID uuid.UUID) (bool, error) {
+	query := `DELETE FROM package_credits WHERE appointment_id = $1`
+
+	res, err := db.ExecContext(ctx, query, appointmentID)
+	if err != nil {
+		utils.LogError("returnCredit package repository (DELETE error)", err)
+		return false, utils.InternalServerError("error returning package credit")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("returnCredit package repository (error reading rows affected)", err)
+		return false, utils.InternalServerError("error returning package credit")
+	}
+
+	return rows > 0, nil
+}
\ No newline at end of file
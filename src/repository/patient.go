@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jhonnydsl/clinify-backend/src/dtos"
 	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/lib/pq"
 )
 
 type PatientRepository struct{}
@@ -34,4 +37,132 @@ func (r *PatientRepository) CreatePatient(ctx context.Context, patient dtos.Pati
 	}
 
 	return id, nil
+}
+
+func (r *PatientRepository) GetAppointments(ctx context.Context, patientID uuid.UUID, page, limit int) ([]dtos.PatientAppointmentOutput, int, error) {
+	query := `SELECT a.id, a.date, a.start_time, a.end_time, a.status, a.modality,
+	COALESCE(l.name, ''), COALESCE(l.address, c.office_address, ''), a.video_url
+	FROM appointments a
+	JOIN clients c ON c.id = a.client_id
+	LEFT JOIN locations l ON l.id = a.location_id
+	WHERE a.patient_id = $1
+	ORDER BY a.date DESC, a.start_time DESC LIMIT $2 OFFSET $3`
+
+	queryCount := `SELECT COUNT(*) FROM appointments WHERE patient_id = $1`
+
+	offset := (page - 1) * limit
+
+	var total int
+
+	err := DB.QueryRowContext(ctx, queryCount, patientID).Scan(&total)
+	if err != nil {
+		return nil, 0, utils.InternalServerError("error getting total appointments")
+	}
+
+	rows, err := DB.QueryContext(ctx, query, patientID, limit, offset)
+	if err != nil {
+		utils.LogError("getAppointments patient repository (error in SELECT)", err)
+		return nil, 0, utils.InternalServerError("error getting appointments")
+	}
+	defer rows.Close()
+
+	appointments := make([]dtos.PatientAppointmentOutput, 0)
+
+	for rows.Next() {
+		var (
+			appointment dtos.PatientAppointmentOutput
+			date time.Time
+			startTime time.Time
+			endTime time.Time
+		)
+
+		err := rows.Scan(
+			&appointment.ID,
+			&date,
+			&startTime,
+			&endTime,
+			&appointment.Status,
+			&appointment.Modality,
+			&appointment.LocationName,
+			&appointment.Address,
+			&appointment.VideoURL,
+		)
+		if err != nil {
+			utils.LogError("getAppointments patient repository (scan error)", err)
+			return nil, 0, utils.InternalServerError("error fetching appointments")
+		}
+
+		appointment.Date = date.Format("2006-01-02")
+		appointment.StartTime = startTime.Format("15:04")
+		appointment.EndTime = endTime.Format("15:04")
+
+		if appointment.Modality == dtos.ModalityOnline {
+			appointment.LocationName = ""
+			appointment.Address = ""
+		}
+
+		appointments = append(appointments, appointment)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getAppointments patient repository (rows error)", err)
+		return nil, 0, utils.InternalServerError("error iterating appointments")
+	}
+
+	return appointments, total, nil
+}
+
+// GetContact returns the patient's contact details, cpfs and notification
+// channels. A non nil clientID restricts the lookup to that psychologist's
+// patients.
+func (r *PatientRepository) GetContact(ctx context.Context, patientID, clientID uuid.UUID) (dtos.PatientContact, error) {
+	query := `SELECT full_name, email, phone, cpf, payer_cpf, notification_channels FROM patients
+	WHERE id = $1 AND ($2::uuid IS NULL OR client_id = $2)`
+
+	var contact dtos.PatientContact
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+
+	err := DB.QueryRowContext(ctx, query, patientID, client).Scan(
+		&contact.Name,
+		&contact.Email,
+		&contact.Phone,
+		&contact.CPF,
+		&contact.PayerCPF,
+		pq.Array(&contact.Channels),
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.PatientContact{}, utils.NotFoundError("patient not found")
+	}
+	if err != nil {
+		utils.LogError("getContact patient repository (SELECT error)", err)
+		return dtos.PatientContact{}, utils.InternalServerError("error getting notification preferences")
+	}
+
+	return contact, nil
+}
+
+func (r *PatientRepository) UpdateNotificationChannels(ctx context.Context, patientID, clientID uuid.UUID, channels []string) error {
+	query := `UPDATE patients SET notification_channels = $1
+	WHERE id = $2 AND ($3::uuid IS NULL OR client_id = $3)`
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+
+	res, err := DB.ExecContext(ctx, query, pq.Array(channels), patientID, client)
+	if err != nil {
+		utils.LogError("updateNotificationChannels patient repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating notification preferences")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("updateNotificationChannels patient repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating notification preferences")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("patient not found")
+	}
+
+	return nil
 }
\ No newline at end of file
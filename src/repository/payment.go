@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type PaymentRepository struct{}
+
+// CreatePayment stores a charge just created at the gateway, under the id
+// the gateway knows as its reference. Amounts are booked on the ledger
+// afterwards, with UpdateFromGateway.
+func (r *PaymentRepository) CreatePayment(ctx context.Context, db DBTX, payment dtos.Payment, entryID uuid.NullUUID, dueDate sql.NullTime) error {
+	query := `INSERT INTO payments (id, client_id, patient_id, charge_entry_id, gateway, gateway_charge_id, method, status,
+		amount_cents, description, failure_reason, boleto_url, boleto_line, due_date)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	_, err := db.ExecContext(ctx, query,
+		payment.ID,
+		payment.ClientID,
+		payment.PatientID,
+		entryID,
+		payment.Gateway,
+		payment.GatewayChargeID,
+		payment.Method,
+		payment.Status,
+		payment.AmountCents,
+		payment.Description,
+		payment.FailureReason,
+		payment.BoletoURL,
+		payment.BoletoLine,
+		dueDate,
+	)
+	if err != nil {
+		utils.LogError("createPayment payment repository (INSERT error)", err)
+		return utils.InternalServerError("error creating payment")
+	}
+
+	return nil
+}
+
+const paymentColumns = `y.id, y.client_id, y.patient_id, p.full_name, y.charge_entry_id, y.gateway, y.gateway_charge_id,
+	y.method, y.status, y.amount_cents, y.captured_cents, y.refunded_cents, y.description, y.failure_reason,
+	y.boleto_url, y.boleto_line, y.due_date, y.created_at, y.updated_at`
+
+func scanPayment(row interface{ Scan(...any) error }) (dtos.Payment, error) {
+	var (
+		payment dtos.Payment
+		entryID uuid.NullUUID
+		dueDate sql.NullTime
+	)
+
+	err := row.Scan(
+		&payment.ID,
+		&payment.ClientID,
+		&payment.PatientID,
+		&payment.PatientName,
+		&entryID,
+		&payment.Gateway,
+		&payment.GatewayChargeID,
+		&payment.Method,
+		&payment.Status,
+		&payment.AmountCents,
+		&payment.CapturedCents,
+		&payment.RefundedCents,
+		&payment.Description,
+		&payment.FailureReason,
+		&payment.BoletoURL,
+		&payment.BoletoLine,
+		&dueDate,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+	if err != nil {
+		return dtos.Payment{}, err
+	}
+
+	payment.ChargeEntryID = utils.NullUUIDPtr(entryID)
+
+	if dueDate.Valid {
+		date := dueDate.Time.Format("2006-01-02")
+		payment.DueDate = &date
+	}
+
+	return payment, nil
+}
+
+// GetPayment returns the payment by id, locked until tx ends.
+func (r *PaymentRepository) GetPayment(ctx context.Context, db DBTX, id uuid.UUID) (dtos.Payment, error) {
+	query := `SELECT ` + paymentColumns + `
+	FROM payments y
+	JOIN patients p ON p.id = y.patient_id
+	WHERE y.id = $1
+	FOR UPDATE OF y`
+
+	payment, err := scanPayment(db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.Payment{}, utils.NotFoundError("payment not found")
+	}
+	if err != nil {
+		utils.LogError("getPayment payment repository (SELECT error)", err)
+		return dtos.Payment{}, utils.InternalServerError("error getting payment")
+	}
+
+	return payment, nil
+}
+
+// GetPaymentByCharge returns the payment of a gateway charge, locked until tx
+// ends.
+func (r *PaymentRepository) GetPaymentByCharge(ctx context.Context, db DBTX, gateway, chargeID string) (dtos.Payment, error) {
+	query := `SELECT ` + paymentColumns + `
+	FROM payments y
+	JOIN patients p ON p.id = y.patient_id
+	WHERE y.gateway = $1 AND y.gateway_charge_id = $2
+	FOR UPDATE OF y`
+
+	payment, err := scanPayment(db.QueryRowContext(ctx, query, gateway, chargeID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.Payment{}, utils.NotFoundError("payment not found")
+	}
+	if err != nil {
+		utils.LogError("getPaymentByCharge payment repository (SELECT error)", err)
+		return dtos.Payment{}, utils.InternalServerError("error getting payment")
+	}
+
+	return payment, nil
+}
+
+// GetPayments lists payments, newest first. Nil ids and an empty status are
+// not used as filters.
+func (r *PaymentRepository) GetPayments(ctx context.Context, clientID, patientID uuid.UUID, status string) ([]dtos.PaymentOutput, error) {
+	query := `SELECT ` + paymentColumns + `
+	FROM payments y
+	JOIN patients p ON p.id = y.patient_id
+	WHERE ($1::uuid IS NULL OR y.client_id = $1)
+	AND ($2::uuid IS NULL OR y.patient_id = $2)
+	AND ($3 = '' OR y.status = $3)
+	ORDER BY y.created_at DESC
+	LIMIT 200`
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+	patient := uuid.NullUUID{UUID: patientID, Valid: patientID != uuid.Nil}
+
+	rows, err := DB.QueryContext(ctx, query, client, patient, status)
+	if err != nil {
+		utils.LogError("getPayments payment repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting payments")
+	}
+	defer rows.Close()
+
+	payments := make([]dtos.PaymentOutput, 0)
+
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			utils.LogError("getPayments payment repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching payments")
+		}
+
+		payments = append(payments, payment.PaymentOutput)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getPayments payment repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating payments")
+	}
+
+	return payments, nil
+}
+
+// GetOpenPayments returns the gateway's payments still waiting to be paid or
+// captured, created in the last 60 days.
+func (r *PaymentRepository) GetOpenPayments(ctx context.Context, gateway string) ([]uuid.UUID, error) {
+	query := `SELECT id FROM payments
+	WHERE gateway = $1 AND status IN ('pending', 'authorized') AND created_at > NOW() - INTERVAL '60 days'
+	ORDER BY created_at
+	LIMIT 500`
+
+	rows, err := DB.QueryContext(ctx, query, gateway)
+	if err != nil {
+		utils.LogError("getOpenPayments payment repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting open payments")
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+
+	for rows.Next() {
+		var id uuid.UUID
+
+		if err := rows.Scan(&id); err != nil {
+			utils.LogError("getOpenPayments payment repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching open payments")
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getOpenPayments payment repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating open payments")
+	}
+
+	return ids, nil
+}
+
+func (r *PaymentRepository) UpdateFromGateway(ctx context.Context, db DBTX, id uuid.UUID, status string, capturedCents, refundedCents int64, failureReason string) error {
+	query := `UPDATE payments
+	SET status = $1, captured_cents = $2, refunded_cents = $3, failure_reason = $4, updated_at = NOW()
+	WHERE id = $5`
+
+	_, err := db.ExecContext(ctx, query, status, capturedCents, refundedCents, failureReason, id)
+	if err != nil {
+		utils.LogError("updateFromGateway payment repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating payment")
+	}
+
+	return nil
+}
+
+// RecordEvent stores a webhook event id and reports whether it is new.
+func (r *PaymentRepository) RecordEvent(ctx context.Context, db DBTX, gateway, eventID string) (bool, error) {
+	query := `INSERT INTO payment_events (gateway, event_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+	res, err := db.ExecContext(ctx, query, gateway, eventID)
+	if err != nil {
+		utils.LogError("recordEvent payment repository (INSERT error)", err)
+		return false, utils.InternalServerError("error recording payment event")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("recordEvent payment repository (error reading rows affected)", err)
+		return false, utils.InternalServerError("error recording payment event")
+	}
+
+	return rows > 0, nil
+}
\ No newline at end of file
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type PixRepository struct{}
+
+func (r *PixRepository) GetSettings(ctx context.Context, adminID uuid.UUID) (dtos.PixSettingsOutput, error) {
+	query := `SELECT pix_key, pix_merchant_name, pix_merchant_city FROM clients WHERE id = $1`
+
+	var settings dtos.PixSettingsOutput
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(&settings.Key, &settings.MerchantName, &settings.MerchantCity)
+	if err != nil {
+		utils.LogError("getSettings pix repository (SELECT error)", err)
+		return dtos.PixSettingsOutput{}, utils.InternalServerError("error getting pix settings")
+	}
+
+	return settings, nil
+}
+
+func (r *PixRepository) UpdateSettings(ctx context.Context, adminID uuid.UUID, settings dtos.PixSettingsOutput) error {
+	query := `UPDATE clients SET pix_key = $1, pix_merchant_name = $2, pix_merchant_city = $3 WHERE id = $4`
+
+	_, err := DB.ExecContext(ctx, query, settings.Key, settings.MerchantName, settings.MerchantCity, adminID)
+	if err != nil {
+		utils.LogError("updateSettings pix repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating pix settings")
+	}
+
+	return nil
+}
+
+func (r *PixRepository) CreateCharge(ctx context.Context, db DBTX, clientID, patientID uuid.UUID, entryID uuid.NullUUID, txid string, amountCents int64, description, brCode string) (uuid.UUID, error) {
+	query := `INSERT INTO pix_charges (client_id, patient_id, charge_entry_id, txid, amount_cents, description, br_code)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err := db.QueryRowContext(ctx, query, clientID, patientID, entryID, txid, amountCents, description, brCode).Scan(&id)
+	if err != nil {
+		utils.LogError("createCharge pix repository (INSERT error)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating pix charge")
+	}
+
+	return id, nil
+}
+
+const pixChargeColumns = `c.id, c.client_id, c.patient_id, p.full_name, c.charge_entry_id, c.txid, c.amount_cents,
+	c.description, c.br_code, c.status, c.created_at, c.paid_at`
+
+func scanPixCharge(row interface{ Scan(...any) error }) (dtos.PixCharge, error) {
+	var (
+		charge  dtos.PixCharge
+		entryID uuid.NullUUID
+		paidAt  sql.NullTime
+	)
+
+	err := row.Scan(
+		&charge.ID,
+		&charge.ClientID,
+		&charge.PatientID,
+		&charge.PatientName,
+		&entryID,
+		&charge.TxID,
+		&charge.AmountCents,
+		&charge.Description,
+		&charge.BRCode,
+		&charge.Status,
+		&charge.CreatedAt,
+		&paidAt,
+	)
+	if err != nil {
+		return dtos.PixCharge{}, err
+	}
+
+	charge.ChargeEntryID = utils.NullUUIDPtr(entryID)
+
+	if paidAt.Valid {
+		charge.PaidAt = &paidAt.Time
+	}
+
+	return charge, nil
+}
+
+// GetCharge returns the charge by id, locked until tx ends.
+func (r *PixRepository) GetCharge(ctx context.Context, db DBTX, id uuid.UUID) (dtos.PixCharge, error) {
+	query := `SELECT ` + pixChargeColumns + `
+	FROM pix_charges c
+	JOIN patients p ON p.id = c.patient_id
+	WHERE c.id = $1
+	FOR UPDATE OF c`
+
+	charge, err := scanPixCharge(db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.PixCharge{}, utils.NotFoundError("pix charge not found")
+	}
+	if err != nil {
+		utils.LogError("getCharge pix repository (SELECT error)", err)
+		return dtos.PixCharge{}, utils.InternalServerError("error getting pix charge")
+	}
+
+	return charge, nil
+}
+
+// GetChargeByTxID returns the charge a payment refers to, locked until tx
+// ends.
+func (r *PixRepository) GetChargeByTxID(ctx context.Context, db DBTX, txid string) (dtos.PixCharge, error) {
+	query := `SELECT ` + pixChargeColumns + `
+	FROM pix_charges c
+	JOIN patients p ON p.id = c.patient_id
+	WHERE c.txid = $1
+	FOR UPDATE OF c`
+
+	charge, err := scanPixCharge(db.QueryRowContext(ctx, query, txid))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.PixCharge{}, utils.NotFoundError("pix charge not found")
+	}
+	if err != nil {
+		utils.LogError("getChargeByTxID pix repository (SELECT error)", err)
+		return dtos.PixCharge{}, utils.InternalServerError("error getting pix charge")
+	}
+
+	return charge, nil
+}
+
+// GetCharges lists charges, newest first. Nil ids and an empty status are not
+// used as filters.
+func (r *PixRepository) GetCharges(ctx context.Context, clientID, patientID uuid.UUID, status string) ([]dtos.PixChargeOutput, error) {
+	query := `SELECT ` + pixChargeColumns + `
+	FROM pix_charges c
+	JOIN patients p ON p.id = c.patient_id
+	WHERE ($1::uuid IS NULL OR c.client_id = $1)
+	AND ($2::uuid IS NULL OR c.patient_id = $2)
+	AND ($3 = '' OR c.status = $3)
+	ORDER BY c.created_at DESC
+	LIMIT 200`
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+	patient := uuid.NullUUID{UUID: patientID, Valid: patientID != uuid.Nil}
+
+	rows, err := DB.QueryContext(ctx, query, client, patient, status)
+	if err != nil {
+		utils.LogError("getCharges pix repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting pix charges")
+	}
+	defer rows.Close()
+
+	charges := make([]dtos.PixChargeOutput, 0)
+
+	for rows.Next() {
+		charge, err := scanPixCharge(rows)
+		if err != nil {
+			utils.LogError("getCharges pix repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching pix charges")
+		}
+
+		charges = append(charges, charge.PixChargeOutput)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getCharges pix repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating pix charges")
+	}
+
+	return charges, nil
+}
+
+// MarkPaid settles a charge that is not paid yet with the ledger entry of its
+// payment. endToEndID is empty when the payment was confirmed by hand.
+func (r *PixRepository) MarkPaid(ctx context.Context, db DBTX, id uuid.UUID, endToEndID string, paymentEntryID uuid.UUID, paidAt time.Time) error {
+	query := `UPDATE pix_charges
+	SET status = 'paid', end_to_end_id = NULLIF($1, ''), payment_entry_id = $2, paid_at = $3
+	WHERE id = $4 AND status != 'paid'`
+
+	res, err := db.ExecContext(ctx, query, endToEndID, paymentEntryID, paidAt, id)
+	if err != nil {
+		utils.LogError("markPaid pix repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating pix charge")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("markPaid pix repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating pix charge")
+	}
+
+	if rows == 0 {
+		return utils.ConflictError("this pix charge was already paid")
+	}
+
+	return nil
+}
+
+func (r *PixRepository) CancelCharge(ctx context.Context, id, clientID uuid.UUID) error {
+	query := `UPDATE pix_charges SET status = 'cancelled'
+	WHERE id = $1 AND client_id = $2 AND status = 'pending'`
+
+	res, err := DB.ExecContext(ctx, query, id, clientID)
+	if err != nil {
+		utils.LogError("cancelCharge pix repository (UPDATE error)", err)
+		return utils.InternalServerError("error cancelling pix charge")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("cancelCharge pix repository (error reading rows affected)", err)
+		return utils.InternalServerError("error cancelling pix charge")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("pending pix charge not found")
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type ReceiptRepository struct{}
+
+// GetSettings returns the psychologist's receipt data. With lock, the
+// psychologist's row stays locked until tx ends, so receipts are numbered one
+// at a time.
+func (r *ReceiptRepository) GetSettings(ctx context.Context, db DBTX, clientID uuid.UUID, lock bool) (dtos.ReceiptSettingsOutput, error) {
+	query := `SELECT full_name, COALESCE(crp, ''), cpf, COALESCE(office_address, '') FROM clients WHERE id = $1`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	var settings dtos.ReceiptSettingsOutput
+
+	err := db.QueryRowContext(ctx, query, clientID).Scan(&settings.FullName, &settings.Crp, &settings.CPF, &settings.OfficeAddress)
+	if err != nil {
+		utils.LogError("getSettings receipt repository (SELECT error)", err)
+		return dtos.ReceiptSettingsOutput{}, utils.InternalServerError("error getting receipt settings")
+	}
+
+	return settings, nil
+}
+
+func (r *ReceiptRepository) UpdateCPF(ctx context.Context, clientID uuid.UUID, cpf string) error {
+	query := `UPDATE clients SET cpf = $1 WHERE id = $2`
+
+	_, err := DB.ExecContext(ctx, query, cpf, clientID)
+	if err != nil {
+		utils.LogError("updateCPF receipt repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating cpf")
+	}
+
+	return nil
+}
+
+// UpdatePatientCPF sets the patient's cpf. A zero clientID skips the
+// ownership check, for patients updating their own.
+func (r *ReceiptRepository) UpdatePatientCPF(ctx context.Context, patientID, clientID uuid.UUID, cpf string) error {
+	query := `UPDATE patients SET cpf = $1 WHERE id = $2 AND ($3::uuid IS NULL OR client_id = $3)`
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+
+	res, err := DB.ExecContext(ctx, query, cpf, patientID, client)
+	if err != nil {
+		utils.LogError("updatePatientCPF receipt repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating cpf")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("updatePatientCPF receipt repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating cpf")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("patient not found")
+	}
+
+	return nil
+}
+
+// GetPayer returns the name and cpf of one of the psychologist's patients.
+func (r *ReceiptRepository) GetPayer(ctx context.Context, db DBTX, patientID, clientID uuid.UUID) (string, string, error) {
+	query := `SELECT full_name, cpf FROM patients WHERE id = $1 AND client_id = $2`
+
+	var name, cpf string
+
+	err := db.QueryRowContext(ctx, query, patientID, clientID).Scan(&name, &cpf)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", utils.NotFoundError("patient not found")
+	}
+	if err != nil {
+		utils.LogError("getPayer receipt repository (SELECT error)", err)
+		return "", "", utils.InternalServerError("error getting patient")
+	}
+
+	return name, cpf, nil
+}
+
+// FindReceipt looks up the receipt already issued for the payment or month.
+// It returns uuid.Nil when there is none.
+func (r *ReceiptRepository) FindReceipt(ctx context.Context, db DBTX, patientID uuid.UUID, entryID uuid.NullUUID, period sql.NullTime) (uuid.UUID, error) {
+	query := `SELECT id FROM receipts
+	WHERE patient_id = $1 AND (entry_id = $2 OR period = $3)`
+
+	var id uuid.UUID
+
+	err := db.QueryRowContext(ctx, query, patientID, entryID, period).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		utils.LogError("findReceipt receipt repository (SELECT error)", err)
+		return uuid.Nil, utils.InternalServerError("error getting receipt")
+	}
+
+	return id, nil
+}
+
+// GetMonthItems returns what the patient paid in [from, to), with refunds as
+// negative items. Payments that have a receipt of their own are left out,
+// along with the refunds of those payments.
+func (r *ReceiptRepository) GetMonthItems(ctx context.Context, db DBTX, patientID, clientID uuid.UUID, from, to time.Time) ([]dtos.ReceiptItem, error) {
+	query := `SELECT e.kind, e.description, e.amount_cents, e.created_at
+	FROM ledger_entries e
+	WHERE e.patient_id = $1 AND e.client_id = $2 AND e.kind IN ('payment', 'refund')
+	AND e.created_at >= $3 AND e.created_at < $4
+	AND NOT EXISTS (SELECT 1 FROM receipts r WHERE r.entry_id = e.id)
+	AND NOT (e.kind = 'refund' AND e.payment_id IS NOT NULL AND EXISTS (
+		SELECT 1 FROM receipts r JOIN ledger_entries p ON p.id = r.entry_id WHERE p.payment_id = e.payment_id))
+	ORDER BY e.created_at`
+
+	rows, err := db.QueryContext(ctx, query, patientID, clientID, from, to)
+	if err != nil {
+		utils.LogError("getMonthItems receipt repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting payments")
+	}
+	defer rows.Close()
+
+	loc := utils.AppLocation()
+	items := make([]dtos.ReceiptItem, 0)
+
+	for rows.Next() {
+		var (
+			item      dtos.ReceiptItem
+			kind      string
+			createdAt time.Time
+		)
+
+		if err := rows.Scan(&kind, &item.Description, &item.AmountCents, &createdAt); err != nil {
+			utils.LogError("getMonthItems receipt repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching payments")
+		}
+
+		switch {
+		case kind == dtos.EntryRefund:
+			item.AmountCents = -item.AmountCents
+			if item.Description == "" {
+				item.Description = "Estorno"
+			}
+		case item.Description == "":
+			item.Description = "Pagamento"
+		}
+
+		item.Date = createdAt.In(loc).Format("2006-01-02")
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getMonthItems receipt repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating payments")
+	}
+
+	return items, nil
+}
+
+// CreateReceipt numbers and stores the receipt. The psychologist's row must
+// be locked by tx (see GetSettings) so numbers have no repeats.
+func (r *ReceiptRepository) CreateReceipt(ctx context.Context, tx DBTX, receipt dtos.Receipt, entryID uuid.NullUUID, period sql.NullTime) (uuid.UUID, error) {
+	items, err := json.Marshal(receipt.Items)
+	if err != nil {
+		utils.LogError("createReceipt receipt repository (error encoding items)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating receipt")
+	}
+
+	query := `WITH counter AS (
+		UPDATE clients SET receipt_counter = receipt_counter + 1 WHERE id = $1 RETURNING receipt_counter
+	)
+	INSERT INTO receipts (client_id, patient_id, number, entry_id, period, issuer_name, issuer_crp, issuer_cpf,
+		issuer_address, patient_name, patient_cpf, reference, items, total_cents)
+	SELECT $1, $2, counter.receipt_counter, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+	FROM counter
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err = tx.QueryRowContext(ctx, query,
+		receipt.ClientID,
+		receipt.PatientID,
+		entryID,
+		period,
+		receipt.Issuer.FullName,
+		receipt.Issuer.Crp,
+		receipt.Issuer.CPF,
+		receipt.Issuer.OfficeAddress,
+		receipt.PatientName,
+		receipt.PatientCPF,
+		receipt.Reference,
+		items,
+		receipt.TotalCents,
+	).Scan(&id)
+	if err != nil {
+		utils.LogError("createReceipt receipt repository (INSERT error)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating receipt")
+	}
+
+	return id, nil
+}
+
+const receiptColumns = `id, client_id, patient_id, number, entry_id, period, issuer_name, issuer_crp, issuer_cpf,
+	issuer_address, patient_name, patient_cpf, reference, items, total_cents, created_at`
+
+func scanReceipt(row interface{ Scan(...any) error }) (dtos.Receipt, error) {
+	var (
+		receipt dtos.Receipt
+		entryID uuid.NullUUID
+		period  sql.NullTime
+		items   []byte
+	)
+
+	err := row.Scan(
+		&receipt.ID,
+		&receipt.ClientID,
+		&receipt.PatientID,
+		&receipt.Number,
+		&entryID,
+		&period,
+		&receipt.Issuer.FullName,
+		&receipt.Issuer.Crp,
+		&receipt.Issuer.CPF,
+		&receipt.Issuer.OfficeAddress,
+		&receipt.PatientName,
+		&receipt.PatientCPF,
+		&receipt.Reference,
+		&items,
+		&receipt.TotalCents,
+		&receipt.CreatedAt,
+	)
+	if err != nil {
+		return dtos.Receipt{}, err
+	}
+
+	if err := json.Unmarshal(items, &receipt.Items); err != nil {
+		return dtos.Receipt{}, err
+	}
+
+	receipt.EntryID = utils.NullUUIDPtr(entryID)
+
+	if period.Valid {
+		month := period.Time.Format("2006-01")
+		receipt.Month = &month
+	}
+
+	return receipt, nil
+}
+
+func (r *ReceiptRepository) GetReceipt(ctx context.Context, id uuid.UUID) (dtos.Receipt, error) {
+	query := `SELECT ` + receiptColumns + ` FROM receipts WHERE id = $1`
+
+	receipt, err := scanReceipt(DB.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.Receipt{}, utils.NotFoundError("receipt not found")
+	}
+	if err != nil {
+		utils.LogError("getReceipt receipt repository (SELECT error)", err)
+		return dtos.Receipt{}, utils.InternalServerError("error getting receipt")
+	}
+
+	return receipt, nil
+}
+
+// GetReceipts lists receipts, newest first. Zero ids are not filtered on, so
+// admins pass their clientID and optionally a patient, and patients pass only
+// their own id.
+func (r *ReceiptRepository) GetReceipts(ctx context.Context, clientID, patientID uuid.UUID) ([]dtos.ReceiptOutput, error) {
+	query := `SELECT ` + receiptColumns + ` FROM receipts
+	WHERE ($1::uuid IS NULL OR client_id = $1) AND ($2::uuid IS NULL OR patient_id = $2)
+	ORDER BY number DESC`
+
+	client := uuid.NullUUID{UUID: clientID, Valid: clientID != uuid.Nil}
+	patient := uuid.NullUUID{UUID: patientID, Valid: patientID != uuid.Nil}
+
+	rows, err := DB.QueryContext(ctx, query, client, patient)
+	if err != nil {
+		utils.LogError("getReceipts receipt repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting receipts")
+	}
+	defer rows.Close()
+
+	receipts := make([]dtos.ReceiptOutput, 0)
+
+	for rows.Next() {
+		receipt, err := scanReceipt(rows)
+		if err != nil {
+			utils.LogError("getReceipts receipt repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching receipts")
+		}
+
+		receipts = append(receipts, receipt.ReceiptOutput)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getReceipts receipt repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating receipts")
+	}
+
+	return receipts, nil
+}
\ No newline at end of file
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/lib/pq"
+)
+
+type ReminderRepository struct{}
+
+func (r *ReminderRepository) GetSettings(ctx context.Context, adminID uuid.UUID) (dtos.ReminderSettingsOutput, error) {
+	query := `SELECT reminders_enabled, reminder_offsets FROM clients WHERE id = $1`
+
+	var (
+		settings dtos.ReminderSettingsOutput
+		offsets []int64
+	)
+
+	err := DB.QueryRowContext(ctx, query, adminID).Scan(&settings.Enabled, pq.Array(&offsets))
+	if err != nil {
+		utils.LogError("getSettings reminder repository (SELECT error)", err)
+		return dtos.ReminderSettingsOutput{}, utils.InternalServerError("error getting reminder settings")
+	}
+
+	settings.OffsetsMinutes = make([]int, 0, len(offsets))
+	for _, offset := range offsets {
+		settings.OffsetsMinutes = append(settings.OffsetsMinutes, int(offset))
+	}
+
+	return settings, nil
+}
+
+func (r *ReminderRepository) UpdateSettings(ctx context.Context, adminID uuid.UUID, enabled bool, offsets []int) error {
+	query := `UPDATE clients SET reminders_enabled = $1, reminder_offsets = $2 WHERE id = $3`
+
+	values := make([]int64, 0, len(offsets))
+	for _, offset := range offsets {
+		values = append(values, int64(offset))
+	}
+
+	_, err := DB.ExecContext(ctx, query, enabled, pq.Array(values), adminID)
+	if err != nil {
+		utils.LogError("updateSettings reminder repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating reminder settings")
+	}
+
+	return nil
+}
+
+// GetDueReminders lists reminders whose send time has passed for appointments
+// that have not started yet and were not reminded at that offset.
+func (r *ReminderRepository) GetDueReminders(ctx context.Context, now time.Time, limit int) ([]dtos.DueReminder, error) {
+	query := `SELECT a.id, a.client_id, o.offset_minutes, p.full_name, p.email, p.phone, p.notification_channels, a.date, a.start_time, a.end_time,
+	a.modality, a.video_url, COALESCE(l.address, c.office_address, '')
+	FROM appointments a
+	JOIN clients c ON c.id = a.client_id
+	JOIN patients p ON p.id = a.patient_id
+	LEFT JOIN locations l ON l.id = a.location_id
+	CROSS JOIN LATERAL unnest(c.reminder_offsets) AS o(offset_minutes)
+	WHERE c.reminders_enabled
+	AND a.status IN ('scheduled', 'confirmed')
+	AND (a.date + a.start_time) > $1
+	AND (a.date + a.start_time) - make_interval(mins => o.offset_minutes) <= $1
+	AND NOT EXISTS (
+		SELECT 1 FROM appointment_reminders r
+		WHERE r.appointment_id = a.id AND r.offset_minutes = o.offset_minutes
+	)
+	ORDER BY a.date, a.start_time, o.offset_minutes
+	LIMIT $2`
+
+	rows, err := DB.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		utils.LogError("getDueReminders repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting due reminders")
+	}
+	defer rows.Close()
+
+	reminders := make([]dtos.DueReminder, 0)
+
+	for rows.Next() {
+		var reminder dtos.DueReminder
+
+		err := rows.Scan(
+			&reminder.AppointmentID,
+			&reminder.ClientID,
+			&reminder.OffsetMinutes,
+			&reminder.PatientName,
+			&reminder.Email,
+			&reminder.Phone,
+			pq.Array(&reminder.Channels),
+			&reminder.Date,
+			&reminder.StartTime,
+			&reminder.EndTime,
+			&reminder.Modality,
+			&reminder.VideoURL,
+			&reminder.Address,
+		)
+		if err != nil {
+			utils.LogError("getDueReminders repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching due reminders")
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getDueReminders repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating due reminders")
+	}
+
+	return reminders, nil
+}
+
+// ClaimReminder records the reminder as sent. It returns false when another
+// instance already claimed it.
+func (r *ReminderRepository) ClaimReminder(ctx context.Context, db DBTX, appointmentID uuid.UUID, offsetMinutes int) (bool, error) {
+	query := `INSERT INTO appointment_reminders (appointment_id, offset_minutes)
+	VALUES ($1, $2)
+	ON CONFLICT DO NOTHING`
+
+	res, err := db.ExecContext(ctx, query, appointmentID, offsetMinutes)
+	if err != nil {
+		utils.LogError("claimReminder repository (INSERT error)", err)
+		return false, utils.InternalServerError("error claiming reminder")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("claimReminder repository (error reading rows affected)", err)
+		return false, utils.InternalServerError("error claiming reminder")
+	}
+
+	return rows == 1, nil
+}
+
+// SkipPastReminders marks reminders whose send time is already over as sent,
+// so booking a session a few hours ahead does not trigger the 24h reminder
+// right after the confirmation email.
+func (r *ReminderRepository) SkipPastReminders(ctx context.Context, db DBTX, appointmentID uuid.UUID, now time.Time) error {
+	query := `INSERT INTO appointment_reminders (appointment_id, offset_minutes)
+	SELECT a.id, o.offset_minutes
+	FROM appointments a
+	JOIN clients c ON c.id = a.client_id
+	CROSS JOIN LATERAL unnest(c.reminder_offsets) AS o(offset_minutes)
+	WHERE a.id = $1
+	AND (a.date + a.start_time) - make_interval(mins => o.offset_minutes) <= $2
+	ON CONFLICT DO NOTHING`
+
+	_, err := db.ExecContext(ctx, query, appointmentID, now)
+	if err != nil {
+		utils.LogError("skipPastReminders repository (INSERT error)", err)
+		return utils.InternalServerError("error skipping past reminders")
+	}
+
+	return nil
+}
+
+// ResetReminders forgets which reminders were sent, so a rescheduled
+// appointment gets them again for its new time.
+func (r *ReminderRepository) ResetReminders(ctx context.Context, db DBTX, appointmentID uuid.UUID) error {
+	query := `DELETE FROM appointment_reminders WHERE appointment_id = $1`
+
+	_, err := db.ExecContext(ctx, query, appointmentID)
+	if err != nil {
+		utils.LogError("resetReminders repository (DELETE error)", err)
+		return utils.InternalServerError("error resetting reminders")
+	}
+
+	return nil
+}
\ No newline at end of file
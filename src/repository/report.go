@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type ReportRepository struct{}
+
+// GetSessions returns the appointments completed between from and to
+// (inclusive, "2006-01-02"), oldest first, with what each was worth. A
+// session is worth its charge net of discounts; without one, the price per
+// session of the package credit it used, or else what its insurance plan was
+// billed.
+func (r *ReportRepository) GetSessions(ctx context.Context, clientID uuid.UUID, from, to string) ([]dtos.ReportSession, error) {
+	query := `SELECT a.patient_id, p.full_name, a.date, a.modality, COALESCE(
+		(SELECT SUM(CASE WHEN e.kind = 'charge' THEN e.amount_cents ELSE -e.amount_cents END)
+			FROM ledger_entries e WHERE e.appointment_id = a.id AND e.kind IN ('charge', 'discount')),
+		(SELECT pp.price_cents / pp.sessions
+			FROM package_credits pc JOIN patient_packages pp ON pp.id = pc.patient_package_id
+			WHERE pc.appointment_id = a.id AND pc.used_at IS NOT NULL),
+		(SELECT bi.amount_cents FROM insurance_batch_items bi WHERE bi.appointment_id = a.id),
+		0)
+	FROM appointments a
+	JOIN patients p ON p.id = a.patient_id
+	WHERE a.client_id = $1 AND a.status = 'completed' AND a.date >= $2 AND a.date <= $3
+	ORDER BY a.date, a.start_time`
+
+	rows, err := DB.QueryContext(ctx, query, clientID, from, to)
+	if err != nil {
+		utils.LogError("getSessions report repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting sessions")
+	}
+	defer rows.Close()
+
+	sessions := make([]dtos.ReportSession, 0)
+
+	for rows.Next() {
+		var session dtos.ReportSession
+
+		if err := rows.Scan(&session.PatientID, &session.PatientName, &session.Date, &session.Modality, &session.ValueCents); err != nil {
+			utils.LogError("getSessions report repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching sessions")
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getSessions report repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating sessions")
+	}
+
+	return sessions, nil
+}
+
+// GetOutstandingBalances returns the patients who owed something once the
+// entries made before until are counted, largest debts first.
+func (r *ReportRepository) GetOutstandingBalances(ctx context.Context, clientID uuid.UUID, until time.Time) ([]dtos.PatientBalanceOutput, error) {
+	query := `SELECT p.id, p.full_name, SUM(` + signedAmount + `), MAX(l.created_at)
+	FROM ledger_entries l
+	JOIN patients p ON p.id = l.patient_id
+	WHERE l.client_id = $1 AND l.created_at < $2
+	GROUP BY p.id, p.full_name
+	HAVING SUM(` + signedAmount + `) > 0
+	ORDER BY 3 DESC, p.full_name`
+
+	rows, err := DB.QueryContext(ctx, query, clientID, until)
+	if err != nil {
+		utils.LogError("getOutstandingBalances report repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting balances")
+	}
+	defer rows.Close()
+
+	balances := make([]dtos.PatientBalanceOutput, 0)
+
+	for rows.Next() {
+		var balance dtos.PatientBalanceOutput
+
+		if err := rows.Scan(&balance.PatientID, &balance.PatientName, &balance.BalanceCents, &balance.LastEntryAt); err != nil {
+			utils.LogError("getOutstandingBalances report repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching balances")
+		}
+
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getOutstandingBalances report repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating balances")
+	}
+
+	return balances, nil
+}
\ No newline at end of file
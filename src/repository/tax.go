@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type TaxRepository struct{}
+
+func (r *TaxRepository) UpdatePayerCPF(ctx context.Context, patientID, clientID uuid.UUID, cpf string) error {
+	query := `UPDATE patients SET payer_cpf = $1 WHERE id = $2 AND client_id = $3`
+
+	res, err := DB.ExecContext(ctx, query, cpf, patientID, clientID)
+	if err != nil {
+		utils.LogError("updatePayerCPF tax repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating payer cpf")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("updatePayerCPF tax repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating payer cpf")
+	}
+
+	if rows == 0 {
+		return utils.NotFoundError("patient not found")
+	}
+
+	return nil
+}
+
+// GetIncome returns the payments and refunds booked in [from, to), oldest
+// first.
+func (r *TaxRepository) GetIncome(ctx context.Context, clientID uuid.UUID, from, to time.Time) ([]dtos.IncomeEntry, error) {
+	query := `SELECT e.patient_id, p.full_name, p.cpf, p.payer_cpf, e.kind, e.amount_cents, e.created_at
+	FROM ledger_entries e
+	JOIN patients p ON p.id = e.patient_id
+	WHERE e.client_id = $1 AND e.kind IN ('payment', 'refund')
+	AND e.created_at >= $2 AND e.created_at < $3
+	ORDER BY e.created_at`
+
+	rows, err := DB.QueryContext(ctx, query, clientID, from, to)
+	if err != nil {
+		utils.LogError("getIncome tax repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting income")
+	}
+	defer rows.Close()
+
+	entries := make([]dtos.IncomeEntry, 0)
+
+	for rows.Next() {
+		var entry dtos.IncomeEntry
+
+		err := rows.Scan(
+			&entry.PatientID,
+			&entry.PatientName,
+			&entry.PatientCPF,
+			&entry.PayerCPF,
+			&entry.Kind,
+			&entry.AmountCents,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			utils.LogError("getIncome tax repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching income")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getIncome tax repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating income")
+	}
+
+	return entries, nil
+}
\ No newline at end of file
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so repository methods that
+// take it can run on their own or as part of a transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// WithTx runs fn in a transaction, committing when it returns nil and rolling
+// back otherwise.
+func WithTx(ctx context.Context, fn func(tx DBTX) error) error {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		utils.LogError("withTx (error starting transaction)", err)
+		return utils.InternalServerError("error starting transaction")
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			utils.LogError("withTx (error rolling back transaction)", rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.LogError("withTx (error committing transaction)", err)
+		return utils.InternalServerError("error committing transaction")
+	}
+
+	return nil
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/lib/pq"
+)
+
+type WaitlistRepository struct{}
+
+// entryQuery selects waiting entries with their place in line and the offer
+// they were sent, if it is still open.
+const entryQuery = `SELECT e.id, e.patient_id, p.full_name, e.weekdays, e.start_time, e.end_time, e.modality, e.created_at,
+	(SELECT COUNT(*) FROM waitlist_entries w
+		WHERE w.client_id = e.client_id AND w.status = 'waiting' AND w.created_at <= e.created_at),
+	o.id, o.date, o.start_time, o.end_time, o.modality, o.expires_at
+	FROM waitlist_entries e
+	JOIN patients p ON p.id = e.patient_id
+	LEFT JOIN waitlist_offers o ON o.entry_id = e.id AND o.status = 'pending'`
+
+func (r *WaitlistRepository) CreateEntry(ctx context.Context, patientID uuid.UUID, input dtos.WaitlistInput, start, end time.Time) (uuid.UUID, error) {
+	query := `INSERT INTO waitlist_entries (client_id, patient_id, weekdays, start_time, end_time, modality)
+	SELECT client_id, id, $2, $3, $4, $5 FROM patients WHERE id = $1
+	RETURNING id`
+
+	weekdays := make([]int64, 0, len(input.Weekdays))
+	for _, weekday := range input.Weekdays {
+		weekdays = append(weekdays, int64(weekday))
+	}
+
+	var id uuid.UUID
+
+	err := DB.QueryRowContext(ctx, query, patientID, pq.Array(weekdays), start, end, input.Modality).Scan(&id)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return uuid.UUID{}, utils.ConflictError("you are already on the waitlist")
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, utils.NotFoundError("patient not found")
+	}
+	if err != nil {
+		utils.LogError("createEntry waitlist repository (INSERT error)", err)
+		return uuid.UUID{}, utils.InternalServerError("error joining the waitlist")
+	}
+
+	return id, nil
+}
+
+func (r *WaitlistRepository) GetEntryByPatient(ctx context.Context, patientID uuid.UUID) (dtos.WaitlistEntryOutput, error) {
+	query := entryQuery + ` WHERE e.patient_id = $1 AND e.status = 'waiting'`
+
+	entry, err := scanEntry(DB.QueryRowContext(ctx, query, patientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.WaitlistEntryOutput{}, utils.NotFoundError("you are not on the waitlist")
+	}
+	if err != nil {
+		utils.LogError("getEntryByPatient waitlist repository (SELECT error)", err)
+		return dtos.WaitlistEntryOutput{}, utils.InternalServerError("error getting waitlist entry")
+	}
+
+	return entry, nil
+}
+
+func (r *WaitlistRepository) GetEntries(ctx context.Context, adminID uuid.UUID) ([]dtos.WaitlistEntryOutput, error) {
+	query := entryQuery + ` WHERE e.client_id = $1 AND e.status = 'waiting' ORDER BY e.created_at`
+
+	rows, err := DB.QueryContext(ctx, query, adminID)
+	if err != nil {
+		utils.LogError("getEntries waitlist repository (SELECT error)", err)
+		return nil, utils.InternalServerError("error getting waitlist")
+	}
+	defer rows.Close()
+
+	entries := make([]dtos.WaitlistEntryOutput, 0)
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			utils.LogError("getEntries waitlist repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching waitlist")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("getEntries waitlist repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating waitlist")
+	}
+
+	return entries, nil
+}
+
+func scanEntry(row interface{ Scan(...any) error }) (dtos.WaitlistEntryOutput, error) {
+	var (
+		entry dtos.WaitlistEntryOutput
+		weekdays []int64
+		start time.Time
+		end time.Time
+		offerID uuid.NullUUID
+		offerDate sql.NullTime
+		offerStart sql.NullTime
+		offerEnd sql.NullTime
+		offerModality sql.NullString
+		offerExpiresAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.PatientID,
+		&entry.PatientName,
+		pq.Array(&weekdays),
+		&start,
+		&end,
+		&entry.Modality,
+		&entry.CreatedAt,
+		&entry.Position,
+		&offerID,
+		&offerDate,
+		&offerStart,
+		&offerEnd,
+		&offerModality,
+		&offerExpiresAt,
+	)
+	if err != nil {
+		return dtos.WaitlistEntryOutput{}, err
+	}
+
+	entry.Weekdays = make([]int, 0, len(weekdays))
+	for _, weekday := range weekdays {
+		entry.Weekdays = append(entry.Weekdays, int(weekday))
+	}
+
+	entry.StartTime = start.Format("15:04")
+	entry.EndTime = end.Format("15:04")
+
+	if offerID.Valid {
+		entry.Offer = &dtos.WaitlistOfferOutput{
+			ID: offerID.UUID,
+			Date: offerDate.Time.Format("2006-01-02"),
+			StartTime: offerStart.Time.Format("15:04"),
+			EndTime: offerEnd.Time.Format("15:04"),
+			Modality: offerModality.String,
+			ExpiresAt: offerExpiresAt.Time,
+		}
+	}
+
+	return entry, nil
+}
+
+// LeaveWaitlist takes the patient out of the line. The offer they were
+// holding, if any, is declined and its day returned so it can be offered again.
+func (r *WaitlistRepository) LeaveWaitlist(ctx context.Context, db DBTX, patientID uuid.UUID) ([]dtos.OpenDay, error) {
+	query := `UPDATE waitlist_entries SET status = 'left' WHERE patient_id = $1 AND status = 'waiting' RETURNING id`
+
+	var entryID uuid.UUID
+
+	err := db.QueryRowContext(ctx, query, patientID).Scan(&entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, utils.NotFoundError("you are not on the waitlist")
+	}
+	if err != nil {
+		utils.LogError("leaveWaitlist repository (UPDATE error)", err)
+		return nil, utils.InternalServerError("error leaving the waitlist")
+	}
+
+	queryOffers := `UPDATE waitlist_offers SET status = 'declined', responded_at = NOW()
+	WHERE entry_id = $1 AND status = 'pending'
+	RETURNING client_id, date`
+
+	rows, err := db.QueryContext(ctx, queryOffers, entryID)
+	if err != nil {
+		utils.LogError("leaveWaitlist repository (error declining offers)", err)
+		return nil, utils.InternalServerError("error leaving the waitlist")
+	}
+
+	return scanOpenDays(rows)
+}
+
+func (r *WaitlistRepository) SetEntryStatus(ctx context.Context, db DBTX, entryID uuid.UUID, status string) error {
+	query := `UPDATE waitlist_entries SET status = $1 WHERE id = $2`
+
+	_, err := db.ExecContext(ctx, query, status, entryID)
+	if err != nil {
+		utils.LogError("setEntryStatus waitlist repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating waitlist entry")
+	}
+
+	return nil
+}
+
+// CreateOffer offers the slot to the first patient in line whose preferences
+// match it and who has neither an open offer nor already passed on this same
+// slot. It returns false when nobody matches or the slot already has an open
+// offer.
+func (r *WaitlistRepository) CreateOffer(ctx context.Context, db DBTX, clientID uuid.UUID, date, start, end time.Time, locationID uuid.NullUUID, modality string, expiresAt time.Time) (uuid.UUID, bool, error) {
+	query := `WITH candidate AS (
+		SELECT e.id FROM waitlist_entries e
+		WHERE e.client_id = $1 AND e.status = 'waiting'
+		AND $8 = ANY(e.weekdays)
+		AND e.start_time <= $3 AND e.end_time >= $4
+		AND (e.modality = '' OR e.modality = $6)
+		AND NOT EXISTS (
+			SELECT 1 FROM waitlist_offers o
+			WHERE o.entry_id = e.id AND (o.status = 'pending' OR (o.date = $2 AND o.start_time = $3))
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM appointments a
+			WHERE a.patient_id = e.patient_id AND a.date = $2 AND a.start_time = $3
+			AND a.status IN ('scheduled', 'confirmed')
+		)
+		ORDER BY e.created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	INSERT INTO waitlist_offers (entry_id, client_id, date, start_time, end_time, location_id, modality, expires_at)
+	SELECT id, $1, $2, $3, $4, $5::uuid, $6, $7::timestamptz FROM candidate
+	ON CONFLICT (client_id, date, start_time) WHERE status = 'pending' DO NOTHING
+	RETURNING id`
+
+	var id uuid.UUID
+
+	err := db.QueryRowContext(ctx, query, clientID, date, start, end, locationID, modality, expiresAt, int(date.Weekday())).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, false, nil
+	}
+	if err != nil {
+		utils.LogError("createOffer waitlist repository (INSERT error)", err)
+		return uuid.UUID{}, false, utils.InternalServerError("error creating waitlist offer")
+	}
+
+	return id, true, nil
+}
+
+// GetOffer returns the offer with the patient it was sent to. Inside a
+// transaction the offer stays locked until it ends.
+func (r *WaitlistRepository) GetOffer(ctx context.Context, db DBTX, offerID uuid.UUID) (dtos.WaitlistOffer, error) {
+	query := `SELECT o.id, o.entry_id, o.client_id, e.patient_id, p.full_name, p.email, p.phone, p.notification_channels,
+	o.date, o.start_time, o.end_time, o.location_id, o.modality, COALESCE(l.address, c.office_address, ''),
+	o.status, o.expires_at, o.appointment_id
+	FROM waitlist_offers o
+	JOIN waitlist_entries e ON e.id = o.entry_id
+	JOIN patients p ON p.id = e.patient_id
+	JOIN clients c ON c.id = o.client_id
+	LEFT JOIN locations l ON l.id = o.location_id
+	WHERE o.id = $1
+	FOR UPDATE OF o`
+
+	var offer dtos.WaitlistOffer
+
+	err := db.QueryRowContext(ctx, query, offerID).Scan(
+		&offer.ID,
+		&offer.EntryID,
+		&offer.ClientID,
+		&offer.PatientID,
+		&offer.Patient.Name,
+		&offer.Patient.Email,
+		&offer.Patient.Phone,
+		pq.Array(&offer.Patient.Channels),
+		&offer.Date,
+		&offer.StartTime,
+		&offer.EndTime,
+		&offer.LocationID,
+		&offer.Modality,
+		&offer.Address,
+		&offer.Status,
+		&offer.ExpiresAt,
+		&offer.AppointmentID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dtos.WaitlistOffer{}, utils.NotFoundError("offer not found")
+	}
+	if err != nil {
+		utils.LogError("getOffer waitlist repository (SELECT error)", err)
+		return dtos.WaitlistOffer{}, utils.InternalServerError("error getting waitlist offer")
+	}
+
+	return offer, nil
+}
+
+// RespondOffer closes a pending offer as claimed or declined.
+func (r *WaitlistRepository) RespondOffer(ctx context.Context, db DBTX, offerID uuid.UUID, status string, appointmentID uuid.NullUUID) error {
+	query := `UPDATE waitlist_offers SET status = $1, appointment_id = $2, responded_at = NOW()
+	WHERE id = $3 AND status = 'pending'`
+
+	res, err := db.ExecContext(ctx, query, status, appointmentID, offerID)
+	if err != nil {
+		utils.LogError("respondOffer waitlist repository (UPDATE error)", err)
+		return utils.InternalServerError("error updating waitlist offer")
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		utils.LogError("respondOffer waitlist repository (error reading rows affected)", err)
+		return utils.InternalServerError("error updating waitlist offer")
+	}
+
+	if rows == 0 {
+		return utils.ConflictError("this offer is no longer available")
+	}
+
+	return nil
+}
+
+// ExpireOffers closes the pending offers whose claim window has passed and
+// returns their days.
+func (r *WaitlistRepository) ExpireOffers(ctx context.Context, now time.Time) ([]dtos.OpenDay, error) {
+	query := `UPDATE waitlist_offers SET status = 'expired'
+	WHERE status = 'pending' AND expires_at <= $1
+	RETURNING client_id, date`
+
+	rows, err := DB.QueryContext(ctx, query, now)
+	if err != nil {
+		utils.LogError("expireOffers waitlist repository (UPDATE error)", err)
+		return nil, utils.InternalServerError("error expiring waitlist offers")
+	}
+
+	return scanOpenDays(rows)
+}
+
+func scanOpenDays(rows *sql.Rows) ([]dtos.OpenDay, error) {
+	defer rows.Close()
+
+	days := make([]dtos.OpenDay, 0)
+
+	for rows.Next() {
+		var day dtos.OpenDay
+
+		if err := rows.Scan(&day.ClientID, &day.Date); err != nil {
+			utils.LogError("scanOpenDays waitlist repository (scan error)", err)
+			return nil, utils.InternalServerError("error fetching waitlist offers")
+		}
+
+		days = append(days, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		utils.LogError("scanOpenDays waitlist repository (rows error)", err)
+		return nil, utils.InternalServerError("error iterating waitlist offers")
+	}
+
+	return days, nil
+}
\ No newline at end of file
@@ -3,16 +3,81 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jhonnydsl/clinify-backend/src/controllers"
-	"github.com/jhonnydsl/clinify-backend/src/mailer"
 	"github.com/jhonnydsl/clinify-backend/src/repository"
 	"github.com/jhonnydsl/clinify-backend/src/services"
 	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+	"github.com/jhonnydsl/clinify-backend/src/video"
 )
 
-func SetupAdminRoutes(app *gin.RouterGroup, mailer *mailer.Mailer) {
-	adminService := &services.AdminService{Repo: &repository.AdminRepository{}, Mailer: mailer}
+func SetupAdminRoutes(app *gin.RouterGroup) {
+	emailService := &services.EmailService{Repo: &repository.EmailTemplateRepository{}}
+	emailController := &controllers.EmailController{Service: emailService}
+
+	notificationService := &services.NotificationService{
+		OutboxRepo: &repository.OutboxRepository{},
+		Email: emailService,
+	}
+
+	videoProvider := video.NewProviderFromEnv()
+
+	adminService := &services.AdminService{
+		Repo: &repository.AdminRepository{},
+		LocationRepo: &repository.LocationRepository{},
+		AppointmentRepo: &repository.AppointmentRepository{},
+		ExceptionRepo: &repository.AvailabilityExceptionRepository{},
+		ReminderRepo: &repository.ReminderRepository{},
+		PackageRepo: &repository.PackageRepository{},
+		Notifications: notificationService,
+		Video: videoProvider,
+	}
 	adminController := &controllers.AdminController{Service: adminService}
 
+	// the waitlist books claimed offers through the admin service, which in
+	// turn offers new availability to the waitlist
+	waitlistService := &services.WaitlistService{
+		Repo: &repository.WaitlistRepository{},
+		Admin: adminService,
+		Notifications: notificationService,
+	}
+	adminService.Waitlist = waitlistService
+	waitlistController := &controllers.WaitlistController{Service: waitlistService}
+
+	locationService := &services.LocationService{Repo: &repository.LocationRepository{}}
+	locationController := &controllers.LocationController{Service: locationService}
+
+	reminderService := &services.ReminderService{Repo: &repository.ReminderRepository{}}
+	reminderController := &controllers.ReminderController{Service: reminderService}
+
+	appointmentService := &services.AppointmentService{
+		Repo: &repository.AppointmentRepository{},
+		AdminRepo: &repository.AdminRepository{},
+		ReminderRepo: &repository.ReminderRepository{},
+		OutboxRepo: &repository.OutboxRepository{},
+		BillingRepo: &repository.BillingRepository{},
+		PackageRepo: &repository.PackageRepository{},
+		Email: emailService,
+		Notifications: notificationService,
+		Waitlist: waitlistService,
+		Video: videoProvider,
+	}
+	appointmentController := &controllers.AppointmentController{Service: appointmentService}
+
+	calendarFeedService := &services.CalendarFeedService{Repo: &repository.CalendarFeedRepository{}}
+	calendarFeedController := &controllers.CalendarFeedController{Service: calendarFeedService}
+
+	caldavService := &services.CalDAVService{
+		Repo: &repository.CalDAVRepository{},
+		FeedRepo: &repository.CalendarFeedRepository{},
+		ExceptionRepo: &repository.AvailabilityExceptionRepository{},
+	}
+	caldavController := &controllers.CalDAVController{Service: caldavService}
+
+	patientService := &services.PatientService{Repo: &repository.PatientRepository{}}
+	patientController := &controllers.PatientController{Service: patientService}
+
+	outboxService := &services.OutboxService{Repo: &repository.OutboxRepository{}}
+	outboxController := &controllers.OutboxController{Service: outboxService}
+
 	app.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "Server online"})
 	})
@@ -20,6 +85,7 @@ func SetupAdminRoutes(app *gin.RouterGroup, mailer *mailer.Mailer) {
 	admin := app.Group("/admin")
 	{
 		admin.POST("", adminController.CreateAdmin)
+		admin.GET("/:slug/available-slots", adminController.GetAvaliableSlots)	// => GET /api/v1/admin/:slug/available-slots?date=2025-01-10&location_id=...&modality=online
 	}
 
 	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
@@ -28,8 +94,34 @@ func SetupAdminRoutes(app *gin.RouterGroup, mailer *mailer.Mailer) {
 		protectedAdmin.GET("/patients", adminController.GetPatients)			// => rota correta com paginação GET /api/v1/admin/patients?page=1&limit=10
 		protectedAdmin.GET("/appointments", adminController.GetAppointments)	// => rota correta com paginação GET /api/v1/admin/appointments?page=1&limit=10
 		protectedAdmin.DELETE("patients/:id", adminController.DeletePatient)
+		protectedAdmin.GET("/patients/:id/notification-preferences", patientController.GetPatientNotificationPreferences)
+		protectedAdmin.PUT("/patients/:id/notification-preferences", patientController.UpdatePatientNotificationPreferences)
+		protectedAdmin.GET("/waitlist", waitlistController.GetEntries)
 		protectedAdmin.POST("/calendar-slots", adminController.CreateCalendarSlot)
 		protectedAdmin.GET("/calendar-slots", adminController.GetCalendarSlots)
 		protectedAdmin.DELETE("/calendar-slots/:id", adminController.DeleteCalendarSlot)
+		protectedAdmin.POST("/locations", locationController.CreateLocation)
+		protectedAdmin.GET("/locations", locationController.GetLocations)
+		protectedAdmin.DELETE("/locations/:id", locationController.DeleteLocation)
+		protectedAdmin.GET("/reminder-settings", reminderController.GetSettings)
+		protectedAdmin.PUT("/reminder-settings", reminderController.UpdateSettings)
+		protectedAdmin.PATCH("/appointments/:id", appointmentController.Reschedule)
+		protectedAdmin.PATCH("/appointments/:id/status", appointmentController.UpdateStatus)
+		protectedAdmin.POST("/appointments/:id/waive-fee", appointmentController.WaiveFee)		// => late-cancel and no-show fees, after they were charged
+		protectedAdmin.GET("/cancellation-settings", appointmentController.GetCancellationSettings)
+		protectedAdmin.PUT("/cancellation-settings", appointmentController.UpdateCancellationSettings)
+		protectedAdmin.GET("/outbox", outboxController.GetMessages)		// => GET /api/v1/admin/outbox?status=dead&page=1&limit=10
+		protectedAdmin.POST("/outbox/:id/resend", outboxController.Resend)
+		protectedAdmin.GET("/email-branding", emailController.GetBranding)
+		protectedAdmin.PUT("/email-branding", emailController.UpdateBranding)
+		protectedAdmin.GET("/email-templates", emailController.GetTemplates)
+		protectedAdmin.PUT("/email-templates/:name", emailController.SaveTemplate)
+		protectedAdmin.DELETE("/email-templates/:name", emailController.DeleteTemplate)
+		protectedAdmin.GET("/calendar-feed", calendarFeedController.GetStatus)
+		protectedAdmin.POST("/calendar-feed", calendarFeedController.Regenerate)
+		protectedAdmin.DELETE("/calendar-feed", calendarFeedController.Revoke)
+		protectedAdmin.GET("/caldav", caldavController.GetAccount)
+		protectedAdmin.POST("/caldav/password", caldavController.GeneratePassword)
+		protectedAdmin.DELETE("/caldav/password", caldavController.RevokePassword)
 	}
 }
\ No newline at end of file
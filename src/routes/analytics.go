@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupAnalyticsRoutes(app *gin.RouterGroup) {
+	analyticsService := &services.AnalyticsService{Repo: &repository.AnalyticsRepository{}}
+	analyticsController := &controllers.AnalyticsController{Service: analyticsService}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.GET("/analytics", analyticsController.GetDashboard)		// => GET /api/v1/admin/analytics?from=2025-01-01&to=2025-06-30
+	}
+}
\ No newline at end of file
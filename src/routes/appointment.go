@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+)
+
+func SetupAppointmentRoutes(app *gin.RouterGroup) {
+	emailService := &services.EmailService{Repo: &repository.EmailTemplateRepository{}}
+
+	notificationService := &services.NotificationService{
+		OutboxRepo: &repository.OutboxRepository{},
+		Email: emailService,
+	}
+
+	// cancellations through the links free a time for the waitlist, which
+	// only needs the admin service to list free slots here
+	waitlistService := &services.WaitlistService{
+		Repo: &repository.WaitlistRepository{},
+		Admin: &services.AdminService{
+			Repo: &repository.AdminRepository{},
+			ExceptionRepo: &repository.AvailabilityExceptionRepository{},
+		},
+		Notifications: notificationService,
+	}
+
+	appointmentService := &services.AppointmentService{
+		Repo: &repository.AppointmentRepository{},
+		OutboxRepo: &repository.OutboxRepository{},
+		BillingRepo: &repository.BillingRepository{},
+		PackageRepo: &repository.PackageRepository{},
+		Email: emailService,
+		Notifications: notificationService,
+		Waitlist: waitlistService,
+	}
+	appointmentController := &controllers.AppointmentController{Service: appointmentService}
+
+	// links sent by email: GET shows a confirmation page, POST runs the action
+	appointments := app.Group("/appointments")
+	{
+		appointments.GET("/:action", appointmentController.ShowPatientAction)		// => GET /api/v1/appointments/confirm?token=...
+		appointments.POST("/:action", appointmentController.RunPatientAction)
+	}
+}
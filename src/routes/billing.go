@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupBillingRoutes(app *gin.RouterGroup) {
+	billingService := &services.BillingService{
+		Repo: &repository.BillingRepository{},
+		PatientRepo: &repository.PatientRepository{},
+	}
+	billingController := &controllers.BillingController{Service: billingService}
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.GET("/ledger", billingController.GetLedger)
+	}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.GET("/pricing", billingController.GetPricing)
+		protectedAdmin.PUT("/pricing", billingController.UpdatePricing)
+		protectedAdmin.GET("/balances", billingController.GetBalances)		// => who owes what, largest debts first
+		protectedAdmin.GET("/patients/:id/price", billingController.GetPatientPrice)
+		protectedAdmin.PUT("/patients/:id/price", billingController.UpdatePatientPrice)
+		protectedAdmin.GET("/patients/:id/ledger", billingController.GetPatientLedger)
+		protectedAdmin.POST("/patients/:id/ledger", billingController.AddEntry)
+	}
+}
\ No newline at end of file
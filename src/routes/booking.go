@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+	"github.com/jhonnydsl/clinify-backend/src/video"
+)
+
+func SetupBookingRoutes(app *gin.RouterGroup) {
+	notificationService := &services.NotificationService{
+		OutboxRepo: &repository.OutboxRepository{},
+		Email: &services.EmailService{Repo: &repository.EmailTemplateRepository{}},
+	}
+
+	adminService := &services.AdminService{
+		Repo: &repository.AdminRepository{},
+		LocationRepo: &repository.LocationRepository{},
+		AppointmentRepo: &repository.AppointmentRepository{},
+		ExceptionRepo: &repository.AvailabilityExceptionRepository{},
+		ReminderRepo: &repository.ReminderRepository{},
+		PackageRepo: &repository.PackageRepository{},
+		Notifications: notificationService,
+		Video: video.NewProviderFromEnv(),
+	}
+
+	waitlistService := &services.WaitlistService{
+		Repo: &repository.WaitlistRepository{},
+		Admin: adminService,
+		Notifications: notificationService,
+	}
+	adminService.Waitlist = waitlistService
+
+	bookingService := &services.BookingService{
+		Repo: &repository.BookingRepository{},
+		Admin: adminService,
+		Notifications: notificationService,
+		Waitlist: waitlistService,
+	}
+	bookingController := &controllers.BookingController{Service: bookingService}
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.POST("/appointments", bookingController.Book)		// => 201 when booked, 202 when waiting for approval
+		protectedPatient.GET("/booking-requests", bookingController.GetPatientRequests)
+		protectedPatient.DELETE("/booking-requests/:id", bookingController.Withdraw)
+	}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.GET("/booking-settings", bookingController.GetSettings)
+		protectedAdmin.PUT("/booking-settings", bookingController.UpdateSettings)
+		protectedAdmin.GET("/booking-rules", bookingController.GetRules)
+		protectedAdmin.PUT("/booking-rules", bookingController.UpdateRules)
+		protectedAdmin.GET("/booking-requests", bookingController.GetRequests)		// => GET /api/v1/admin/booking-requests?status=pending
+		protectedAdmin.POST("/booking-requests/:id/accept", bookingController.Accept)
+		protectedAdmin.POST("/booking-requests/:id/decline", bookingController.Decline)
+	}
+}
\ No newline at end of file
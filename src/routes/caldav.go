@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/caldav"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+)
+
+// SetupCalDAVRoutes mounts the CalDAV server outside /api/v1: calendar apps
+// look for /.well-known/caldav at the root of the host.
+func SetupCalDAVRoutes(app *gin.Engine) {
+	caldavService := &services.CalDAVService{
+		Repo: &repository.CalDAVRepository{},
+		FeedRepo: &repository.CalendarFeedRepository{},
+		ExceptionRepo: &repository.AvailabilityExceptionRepository{},
+	}
+	handler := gin.WrapH(caldav.NewHandler(caldavService, "/caldav"))
+
+	dav := app.Group("", caldav.BasicAuthMiddleware(caldavService))
+	for _, method := range caldav.Methods {
+		dav.Handle(method, "/caldav/*path", handler)		// => server URL https://host/caldav/
+		dav.Handle(method, "/.well-known/caldav", handler)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+)
+
+func SetupCalendarRoutes(app *gin.RouterGroup) {
+	calendarFeedService := &services.CalendarFeedService{Repo: &repository.CalendarFeedRepository{}}
+	calendarFeedController := &controllers.CalendarFeedController{Service: calendarFeedService}
+
+	// the token in the path is the only credential, calendar apps cannot send headers
+	app.GET("/calendar/:token", calendarFeedController.GetFeed)		// => GET /api/v1/calendar/<token>.ics
+}
\ No newline at end of file
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupCertificateRoutes(app *gin.RouterGroup) {
+	certificateService := &services.CertificateService{
+		Repo: &repository.CertificateRepository{},
+		AppointmentRepo: &repository.AppointmentRepository{},
+		ReceiptRepo: &repository.ReceiptRepository{},
+	}
+	certificateController := &controllers.CertificateController{Service: certificateService}
+
+	// public, the verification code printed on the certificate is the credential
+	app.GET("/certificates/:code", certificateController.Verify)
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.GET("/appointments/:id/certificate", certificateController.GetPatientPDF)
+	}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.GET("/appointments/:id/certificate", certificateController.GetPDF)
+	}
+}
\ No newline at end of file
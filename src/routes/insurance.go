@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupInsuranceRoutes(app *gin.RouterGroup) {
+	insuranceService := &services.InsuranceService{
+		Repo: &repository.InsuranceRepository{},
+		AppointmentRepo: &repository.AppointmentRepository{},
+	}
+	insuranceController := &controllers.InsuranceController{Service: insuranceService}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.GET("/insurance-plans", insuranceController.GetPlans)
+		protectedAdmin.POST("/insurance-plans", insuranceController.CreatePlan)
+		protectedAdmin.PUT("/insurance-plans/:id", insuranceController.UpdatePlan)
+		protectedAdmin.DELETE("/insurance-plans/:id", insuranceController.DeletePlan)		// => stops taking new cards, history is kept
+		protectedAdmin.POST("/insurance-plans/:id/batches", insuranceController.CreateBatch)		// => {"month": "2025-01"}
+		protectedAdmin.POST("/patients/:id/coverages", insuranceController.CreateCoverage)
+		protectedAdmin.GET("/patients/:id/coverages", insuranceController.GetCoverages)
+		protectedAdmin.POST("/coverages/:id/authorizations", insuranceController.CreateAuthorization)
+		protectedAdmin.GET("/coverages/:id/authorizations", insuranceController.GetAuthorizations)
+		protectedAdmin.PUT("/appointments/:id/authorization", insuranceController.SetAppointmentAuthorization)		// => {"authorization_id": null} takes it out of the guide
+		protectedAdmin.GET("/insurance-batches", insuranceController.GetBatches)		// => GET /api/v1/admin/insurance-batches?plan_id=...
+		protectedAdmin.GET("/insurance-batches/:id", insuranceController.GetBatch)
+		protectedAdmin.GET("/insurance-batches/:id/csv", insuranceController.GetBatchCSV)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupPackageRoutes(app *gin.RouterGroup) {
+	packageService := &services.PackageService{
+		Repo: &repository.PackageRepository{},
+		BillingRepo: &repository.BillingRepository{},
+		PatientRepo: &repository.PatientRepository{},
+	}
+	packageController := &controllers.PackageController{Service: packageService}
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.GET("/packages", packageController.GetPatientPackages)
+		protectedPatient.POST("/packages/:id/purchase", packageController.BuyPackage)
+		protectedPatient.GET("/credits", packageController.GetCredits)
+	}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.GET("/packages", packageController.GetPackages)
+		protectedAdmin.POST("/packages", packageController.CreatePackage)
+		protectedAdmin.PUT("/packages/:id", packageController.UpdatePackage)
+		protectedAdmin.DELETE("/packages/:id", packageController.DeletePackage)		// => takes the package off sale, purchases are kept
+		protectedAdmin.POST("/patients/:id/packages", packageController.SellPackage)
+		protectedAdmin.GET("/patients/:id/credits", packageController.GetPatientCredits)
+	}
+}
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 	"github.com/jhonnydsl/clinify-backend/src/controllers"
 	"github.com/jhonnydsl/clinify-backend/src/repository"
 	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
 )
 
 func SetupPatientRoutes(app *gin.RouterGroup) {
@@ -18,4 +19,11 @@ func SetupPatientRoutes(app *gin.RouterGroup) {
 	{
 		patient.POST("", patientController.CreatePatient)
 	}
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.GET("/appointments", patientController.GetAppointments)	// => GET /api/v1/patient/appointments?page=1&limit=10
+		protectedPatient.GET("/notification-preferences", patientController.GetNotificationPreferences)
+		protectedPatient.PUT("/notification-preferences", patientController.UpdateNotificationPreferences)
+	}
 }
\ No newline at end of file
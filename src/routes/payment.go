@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/payment"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupPaymentRoutes(app *gin.RouterGroup, gateway payment.Gateway) {
+	paymentService := &services.PaymentService{
+		Repo: &repository.PaymentRepository{},
+		BillingRepo: &repository.BillingRepository{},
+		PatientRepo: &repository.PatientRepository{},
+		Gateway: gateway,
+	}
+	paymentController := &controllers.PaymentController{Service: paymentService}
+
+	app.POST("/payments/webhook", paymentController.Webhook)
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.GET("/payments", paymentController.GetPatientPayments)
+	}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.POST("/patients/:id/payments", paymentController.CreatePayment)
+		protectedAdmin.GET("/payments", paymentController.GetPayments)		// => GET /api/v1/admin/payments?status=authorized
+		protectedAdmin.POST("/payments/:id/capture", paymentController.Capture)
+		protectedAdmin.POST("/payments/:id/refund", paymentController.Refund)
+		protectedAdmin.POST("/payments/:id/sync", paymentController.Sync)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/pix"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupPixRoutes(app *gin.RouterGroup, provider pix.Provider) {
+	pixService := &services.PixService{
+		Repo: &repository.PixRepository{},
+		BillingRepo: &repository.BillingRepository{},
+		PatientRepo: &repository.PatientRepository{},
+		Notifications: &services.NotificationService{
+			OutboxRepo: &repository.OutboxRepository{},
+			Email: &services.EmailService{Repo: &repository.EmailTemplateRepository{}},
+		},
+		Provider: provider,
+	}
+	pixController := &controllers.PixController{Service: pixService}
+
+	// providers following the Banco Central API post to the registered url
+	// with /pix appended
+	webhook := app.Group("/pix/webhook")
+	{
+		webhook.POST("", pixController.Webhook)
+		webhook.POST("/pix", pixController.Webhook)
+	}
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.GET("/pix-charges", pixController.GetPatientCharges)
+		protectedPatient.GET("/pix-charges/:id/qrcode", pixController.GetPatientQRCode)
+	}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.GET("/pix-settings", pixController.GetSettings)
+		protectedAdmin.PUT("/pix-settings", pixController.UpdateSettings)
+		protectedAdmin.POST("/patients/:id/pix-charges", pixController.CreateCharge)
+		protectedAdmin.GET("/pix-charges", pixController.GetCharges)		// => GET /api/v1/admin/pix-charges?status=pending
+		protectedAdmin.GET("/pix-charges/:id/qrcode", pixController.GetQRCode)
+		protectedAdmin.POST("/pix-charges/:id/paid", pixController.MarkPaid)
+		protectedAdmin.DELETE("/pix-charges/:id", pixController.CancelCharge)
+	}
+}
\ No newline at end of file
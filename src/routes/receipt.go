@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupReceiptRoutes(app *gin.RouterGroup) {
+	receiptService := &services.ReceiptService{
+		Repo: &repository.ReceiptRepository{},
+		BillingRepo: &repository.BillingRepository{},
+		PatientRepo: &repository.PatientRepository{},
+		Notifications: &services.NotificationService{
+			OutboxRepo: &repository.OutboxRepository{},
+			Email: &services.EmailService{Repo: &repository.EmailTemplateRepository{}},
+		},
+	}
+	receiptController := &controllers.ReceiptController{Service: receiptService}
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.PUT("/cpf", receiptController.UpdateOwnCPF)
+		protectedPatient.GET("/receipts", receiptController.GetPatientReceipts)
+		protectedPatient.GET("/receipts/:id/pdf", receiptController.GetPatientPDF)
+	}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.GET("/receipt-settings", receiptController.GetSettings)
+		protectedAdmin.PUT("/receipt-settings", receiptController.UpdateSettings)
+		protectedAdmin.PUT("/patients/:id/cpf", receiptController.UpdatePatientCPF)
+		protectedAdmin.POST("/patients/:id/receipts", receiptController.Issue)		// => {"entry_id": "..."} or {"month": "2025-01"}
+		protectedAdmin.GET("/receipts", receiptController.GetReceipts)		// => GET /api/v1/admin/receipts?patient_id=...
+		protectedAdmin.GET("/receipts/:id/pdf", receiptController.GetPDF)
+		protectedAdmin.POST("/receipts/:id/email", receiptController.Email)
+	}
+}
\ No newline at end of file
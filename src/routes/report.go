@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupReportRoutes(app *gin.RouterGroup) {
+	reportService := &services.ReportService{
+		Repo: &repository.ReportRepository{},
+		TaxRepo: &repository.TaxRepository{},
+	}
+	reportController := &controllers.ReportController{Service: reportService}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		// revenue-by-month, balances, revenue-by-patient, revenue-by-modality or average-ticket
+		protectedAdmin.GET("/reports/:report", reportController.GetReport)		// => GET /api/v1/admin/reports/revenue-by-month?from=2025-01-01&to=2025-06-30&format=csv
+	}
+}
\ No newline at end of file
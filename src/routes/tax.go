@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+)
+
+func SetupTaxRoutes(app *gin.RouterGroup) {
+	taxService := &services.TaxService{Repo: &repository.TaxRepository{}}
+	taxController := &controllers.TaxController{Service: taxService}
+
+	protectedAdmin := app.Group("/admin", middlewares.AuthMiddleware(), middlewares.AdminOnlyMiddleware())
+	{
+		protectedAdmin.PUT("/patients/:id/payer-cpf", taxController.UpdatePayerCPF)		// => {"payer_cpf": ""} makes the patient the payer
+		protectedAdmin.GET("/carne-leao", taxController.CarneLeaoCSV)		// => GET /api/v1/admin/carne-leao?month=2025-01
+		protectedAdmin.GET("/carne-leao/summary", taxController.YearSummary)		// => GET /api/v1/admin/carne-leao/summary?year=2025
+	}
+}
\ No newline at end of file
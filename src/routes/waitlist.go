@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jhonnydsl/clinify-backend/src/controllers"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/services"
+	"github.com/jhonnydsl/clinify-backend/src/utils/middlewares"
+	"github.com/jhonnydsl/clinify-backend/src/video"
+)
+
+func SetupWaitlistRoutes(app *gin.RouterGroup) {
+	notificationService := &services.NotificationService{
+		OutboxRepo: &repository.OutboxRepository{},
+		Email: &services.EmailService{Repo: &repository.EmailTemplateRepository{}},
+	}
+
+	// claiming an offer books the appointment like the psychologist would
+	adminService := &services.AdminService{
+		Repo: &repository.AdminRepository{},
+		LocationRepo: &repository.LocationRepository{},
+		AppointmentRepo: &repository.AppointmentRepository{},
+		ExceptionRepo: &repository.AvailabilityExceptionRepository{},
+		ReminderRepo: &repository.ReminderRepository{},
+		PackageRepo: &repository.PackageRepository{},
+		Notifications: notificationService,
+		Video: video.NewProviderFromEnv(),
+	}
+
+	waitlistService := &services.WaitlistService{
+		Repo: &repository.WaitlistRepository{},
+		Admin: adminService,
+		Notifications: notificationService,
+	}
+	adminService.Waitlist = waitlistService
+	waitlistController := &controllers.WaitlistController{Service: waitlistService}
+
+	// links sent with an offer: GET shows a confirmation page, POST runs the action
+	waitlist := app.Group("/waitlist")
+	{
+		waitlist.GET("/:action", waitlistController.ShowOfferAction)		// => GET /api/v1/waitlist/claim?token=...
+		waitlist.POST("/:action", waitlistController.RunOfferAction)
+	}
+
+	protectedPatient := app.Group("/patient", middlewares.AuthMiddleware(), middlewares.PatientOnlyMiddleware())
+	{
+		protectedPatient.GET("/waitlist", waitlistController.GetEntry)
+		protectedPatient.POST("/waitlist", waitlistController.Join)
+		protectedPatient.DELETE("/waitlist", waitlistController.Leave)
+	}
+}
\ No newline at end of file
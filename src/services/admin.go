@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,12 +11,20 @@ import (
 	"github.com/jhonnydsl/clinify-backend/src/mailer"
 	"github.com/jhonnydsl/clinify-backend/src/repository"
 	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/jhonnydsl/clinify-backend/src/video"
 	"github.com/patrickmn/go-cache"
 )
 
 type AdminService struct {
 	Repo *repository.AdminRepository
-	Mailer *mailer.Mailer
+	LocationRepo *repository.LocationRepository
+	AppointmentRepo *repository.AppointmentRepository
+	ExceptionRepo *repository.AvailabilityExceptionRepository
+	ReminderRepo *repository.ReminderRepository
+	PackageRepo *repository.PackageRepository
+	Notifications *NotificationService
+	Waitlist *WaitlistService
+	Video video.RoomProvider
 }
 
 func (services *AdminService) CreateAdmin(ctx context.Context, admin dtos.AdminInput) (uuid.UUID, error) {
@@ -70,34 +79,96 @@ func (service *AdminService) CreateAppointment(ctx context.Context, input dtos.A
 		return uuid.UUID{}, utils.BadRequestError("start_time must be before end_time")
 	}
 
-	id, err := service.Repo.CreateAppointment(ctx, input, parsedDate, start, end, clientID)
+	locationID, modality, err := resolveLocation(ctx, service.LocationRepo, clientID, input.LocationID, input.Modality)
 	if err != nil {
-		utils.LogError("createAppointment service (error call to createAppointment repository)", err)
-		return uuid.UUID{}, utils.InternalServerError("error creating appointment")
+		return uuid.UUID{}, err
 	}
 
-	patientUUID, err := uuid.Parse(input.PatientID)
-	if err != nil {
+	input.Modality = modality
+
+	if modality == dtos.ModalityInPerson {
+		input.VideoURL = ""
+	}
+
+	if _, err := uuid.Parse(input.PatientID); err != nil {
 		return uuid.UUID{}, utils.BadRequestError("invalid patient id format")
 	}
 
-	email, err := service.Repo.GetPatientEmailByID(ctx, patientUUID)
+	var id uuid.UUID
+
+	// the appointment and its confirmation email are committed together, so a
+	// crash can neither lose the email nor send it for a rolled back booking
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		id, err = service.bookAppointment(ctx, tx, input, parsedDate, start, end, locationID, clientID)
+		return err
+	})
 	if err != nil {
-		utils.LogError("createAppointment service (error call to getPatientsByEmail repository)", err)
-		return uuid.UUID{}, utils.InternalServerError("error getting email")
+		return uuid.UUID{}, err
 	}
 
-	body := utils.BuildAppointmentEmailBody(input.Date, input.StartTime, input.EndTime)
+	return id, nil
+}
 
-	go func() {
-		if err := service.Mailer.Send(email, "Confirmação de Agendamento", body); err != nil {
-			utils.LogError("error sending email", err)
+// bookAppointment creates an already validated appointment in tx, with its
+// video room and confirmation message.
+func (service *AdminService) bookAppointment(ctx context.Context, tx repository.DBTX, input dtos.AppointmentInput, parsedDate, start, end time.Time, locationID uuid.NullUUID, clientID uuid.UUID) (uuid.UUID, error) {
+	id, err := service.Repo.CreateAppointment(ctx, tx, input, parsedDate, start, end, locationID, clientID)
+	if err != nil {
+		utils.LogError("createAppointment service (error call to createAppointment repository)", err)
+		return uuid.UUID{}, utils.InternalServerError("error creating appointment")
+	}
+
+	if err := service.ReminderRepo.SkipPastReminders(ctx, tx, id, time.Now().In(utils.AppLocation())); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	if err := service.PackageRepo.ReserveCredit(ctx, tx, id); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	if input.Modality == dtos.ModalityOnline && input.VideoURL == "" && service.Video != nil {
+		input.VideoURL, err = service.createVideoRoom(ctx, tx, id, input)
+		if err != nil {
+			utils.LogError("createAppointment service (error creating video room)", err)
+			return uuid.UUID{}, utils.InternalServerError("error creating video room")
 		}
-	}()
+	}
+
+	details, err := service.AppointmentRepo.GetAppointmentDetails(ctx, tx, id)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	if err := notifyAppointment(ctx, tx, service.Notifications, mailer.TemplateAppointmentConfirmation, details); err != nil {
+		return uuid.UUID{}, err
+	}
 
 	return id, nil
 }
 
+func (service *AdminService) createVideoRoom(ctx context.Context, db repository.DBTX, appointmentID uuid.UUID, input dtos.AppointmentInput) (string, error) {
+	start, err := utils.ParseDateTimeInLocation(input.Date, input.StartTime)
+	if err != nil {
+		return "", err
+	}
+
+	end, err := utils.ParseDateTimeInLocation(input.Date, input.EndTime)
+	if err != nil {
+		return "", err
+	}
+
+	room, err := service.Video.CreateRoom(ctx, appointmentID, start, end)
+	if err != nil {
+		return "", err
+	}
+
+	if err := service.Repo.UpdateAppointmentVideoURL(ctx, db, appointmentID, room.URL); err != nil {
+		return "", err
+	}
+
+	return room.URL, nil
+}
+
 func (service *AdminService) GetAppointments(ctx context.Context, adminID uuid.UUID, page, limit int) ([]dtos.AppointmentOutput, int, error) {
 	if page < 1 {
 		page = 1
@@ -180,12 +251,27 @@ func (service *AdminService) CreateCalendarSlot(ctx context.Context, input dtos.
 		return uuid.UUID{}, utils.BadRequestError("end time must be after start time")
 	}
 
-	id, err := service.Repo.CreateCalendarSlot(ctx, input, start, end, adminID)
+	locationID, modality, err := resolveLocation(ctx, service.LocationRepo, adminID, input.LocationID, input.Modality)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	input.Modality = modality
+
+	id, err := service.Repo.CreateCalendarSlot(ctx, input, start, end, locationID, adminID)
 	if err != nil {
 		utils.LogError("createCalendarSlot service (error call to repository)", nil)
 		return uuid.UUID{}, utils.InternalServerError("error creating calendar slot")
 	}
 
+	utils.Cache.Delete(fmt.Sprintf("calendar_slots_%s", adminID))
+
+	if service.Waitlist != nil {
+		if err := service.Waitlist.OfferWeekday(ctx, adminID, input.Weekday); err != nil {
+			utils.LogError("createCalendarSlot service (error offering new slots to the waitlist)", err)
+		}
+	}
+
 	return id, nil
 }
 
@@ -217,13 +303,27 @@ func (service *AdminService) DeleteCalendarSlot(ctx context.Context, slotID uuid
 	return service.Repo.DeleteCalendarSlot(ctx, slotID)
 }
 
-func (service *AdminService) GetAvaliableSlots(ctx context.Context, adminID uuid.UUID, date string) ([]string, error) {
+func (service *AdminService) GetAvaliableSlots(ctx context.Context, adminID uuid.UUID, date, locationID, modality string) ([]dtos.AvailableSlotOutput, error) {
 	parsedDate, err := utils.ParseDate(date)
 	if err != nil {
 		utils.LogError("getAvaliableSlots service (error parsed date)", err)
 		return nil, utils.BadRequestError("invalid date format")
 	}
 
+	rules, err := service.Repo.GetBookingRules(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	// a day out of the booking horizon or already full has no slots to offer
+	if err := service.checkBookingDay(ctx, adminID, rules, parsedDate); err != nil {
+		if utils.GetStatusCode(err) >= http.StatusInternalServerError {
+			return nil, err
+		}
+
+		return make([]dtos.AvailableSlotOutput, 0), nil
+	}
+
 	weekday := int(parsedDate.Weekday())
 
 	slots, err := service.Repo.GetCalendarSlotsByWeekday(ctx, adminID, weekday)
@@ -232,14 +332,27 @@ func (service *AdminService) GetAvaliableSlots(ctx context.Context, adminID uuid
 		return nil, utils.InternalServerError("error getting calendar slots")
 	}
 
-	var possibleSlots []time.Time
+	var possibleSlots []dtos.AvailableSlotOutput
 	interval := 60 * time.Minute
 
 	for _, slot := range slots {
+		if modality != "" && slot.Modality != modality {
+			continue
+		}
+
+		if locationID != "" && (!slot.LocationID.Valid || slot.LocationID.UUID.String() != locationID) {
+			continue
+		}
+
 		current := slot.StartTime
 
 		for current.Add(interval).Equal(slot.EndTime) || current.Add(interval).Before(slot.EndTime) {
-			possibleSlots = append(possibleSlots, current)
+			possibleSlots = append(possibleSlots, dtos.AvailableSlotOutput{
+				StartTime: current.Format("15:04"),
+				EndTime: current.Add(interval).Format("15:04"),
+				LocationID: utils.NullUUIDPtr(slot.LocationID),
+				Modality: slot.Modality,
+			})
 			current = current.Add(interval)
 		}
 	}
@@ -255,14 +368,53 @@ func (service *AdminService) GetAvaliableSlots(ctx context.Context, adminID uuid
 		occupied[appt.StartTime] = true
 	}
 
-	var available []string
+	holds, err := service.Repo.GetBookingHolds(ctx, adminID, parsedDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, startTime := range holds {
+		occupied[startTime] = true
+	}
+
+	loc := utils.AppLocation()
+	dayStart := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, loc)
+
+	busy, err := busyIntervals(ctx, service.ExceptionRepo, adminID, dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		utils.LogError("getAvaliableSlots service (error getting availability exceptions)", err)
+		return nil, utils.InternalServerError("error getting availability exceptions")
+	}
+
+	available := make([]dtos.AvailableSlotOutput, 0)
 	for _, slot := range possibleSlots {
-		formatted := slot.Format("15:04")
+		if occupied[slot.StartTime] {
+			continue
+		}
 
-		if !occupied[formatted] {
-			available = append(available, formatted)
+		start, startErr := utils.ParseDateTimeInLocation(date, slot.StartTime)
+		end, endErr := utils.ParseDateTimeInLocation(date, slot.EndTime)
+
+		if startErr == nil && checkBookingNotice(rules, start) != nil {
+			continue
+		}
+
+		if startErr == nil && endErr == nil && overlapsBusy(busy, start, end) {
+			continue
 		}
+
+		available = append(available, slot)
 	}
 
 	return available, nil
+}
+
+func (service *AdminService) GetAvaliableSlotsBySlug(ctx context.Context, slug, date, locationID, modality string) ([]dtos.AvailableSlotOutput, error) {
+	adminID, err := service.Repo.FindAdminIDBySlug(ctx, slug)
+	if err != nil {
+		utils.LogError("getAvaliableSlotsBySlug service (error get client_id by public_slug)", err)
+		return nil, utils.NotFoundError("psychologist not found")
+	}
+
+	return service.GetAvaliableSlots(ctx, adminID, date, locationID, modality)
 }
\ No newline at end of file
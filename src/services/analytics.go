@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// churnDays is how long a patient goes without sessions, and with nothing
+// booked, before they count as having dropped out.
+const churnDays = 60
+
+type AnalyticsService struct {
+	Repo *repository.AnalyticsRepository
+}
+
+// Dashboard computes the practice's metrics month by month for the range
+// between from and to, with the same defaults as reports.
+func (service *AnalyticsService) Dashboard(ctx context.Context, adminID uuid.UUID, from, to string) (dtos.AnalyticsOutput, error) {
+	start, end, err := reportRange(from, to)
+	if err != nil {
+		return dtos.AnalyticsOutput{}, err
+	}
+
+	output := dtos.AnalyticsOutput{From: start.Format("2006-01-02"), To: end.Format("2006-01-02")}
+
+	churnedBefore := time.Now().In(utils.AppLocation()).AddDate(0, 0, -churnDays).Format("2006-01-02")
+
+	output.Periods, err = service.Repo.GetPeriods(ctx, adminID, output.From, output.To, churnedBefore)
+	if err != nil {
+		return dtos.AnalyticsOutput{}, err
+	}
+
+	totals := &output.Totals
+
+	for i := range output.Periods {
+		period := &output.Periods[i]
+
+		setAgendaRates(&period.AgendaMetrics)
+		period.ReturningPatients = period.ActivePatients - period.NewPatients
+		period.ChurnRate = rate(period.ChurnedPatients, period.ActivePatients)
+
+		totals.AvailableMinutes += period.AvailableMinutes
+		totals.BookedMinutes += period.BookedMinutes
+		totals.Appointments += period.Appointments
+		totals.Completed += period.Completed
+		totals.Cancelled += period.Cancelled
+		totals.NoShows += period.NoShows
+		totals.NewPatients += period.NewPatients
+		totals.ChurnedPatients += period.ChurnedPatients
+	}
+
+	setAgendaRates(&totals.AgendaMetrics)
+
+	patients, days, sessions, err := service.Repo.GetTreatmentLength(ctx, adminID, output.From, output.To)
+	if err != nil {
+		return dtos.AnalyticsOutput{}, err
+	}
+
+	totals.TreatedPatients = patients
+	totals.AverageTreatmentDays = math.Round(days*10) / 10
+	totals.AverageSessions = math.Round(sessions*10) / 10
+
+	return output, nil
+}
+
+func setAgendaRates(metrics *dtos.AgendaMetrics) {
+	if metrics.AvailableMinutes > 0 {
+		metrics.OccupancyRate = math.Round(float64(metrics.BookedMinutes)/float64(metrics.AvailableMinutes)*10000) / 10000
+	}
+
+	metrics.CancellationRate = rate(metrics.Cancelled, metrics.Appointments)
+	metrics.NoShowRate = rate(metrics.NoShows, metrics.Completed+metrics.NoShows)
+}
+
+// rate is part over total rounded to four places, or 0 without a total.
+func rate(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
\ No newline at end of file
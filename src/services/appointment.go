@@ -0,0 +1,481 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/jhonnydsl/clinify-backend/src/video"
+)
+
+const maxPolicyLength = 2000
+
+// statusTransitions lists, for each status, the statuses an appointment can move to.
+// cancelled, completed and no_show are final.
+var statusTransitions = map[string][]string{
+	dtos.StatusScheduled: {dtos.StatusConfirmed, dtos.StatusCancelled, dtos.StatusCompleted, dtos.StatusNoShow},
+	dtos.StatusConfirmed: {dtos.StatusCancelled, dtos.StatusCompleted, dtos.StatusNoShow},
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type AppointmentService struct {
+	Repo *repository.AppointmentRepository
+	AdminRepo *repository.AdminRepository
+	ReminderRepo *repository.ReminderRepository
+	OutboxRepo *repository.OutboxRepository
+	BillingRepo *repository.BillingRepository
+	PackageRepo *repository.PackageRepository
+	Email *EmailService
+	Notifications *NotificationService
+	Waitlist *WaitlistService
+	Video video.RoomProvider
+}
+
+func (service *AppointmentService) UpdateStatus(ctx context.Context, appointmentID, adminID uuid.UUID, input dtos.AppointmentStatusInput) error {
+	details, err := service.Repo.GetAppointmentDetails(ctx, repository.DB, appointmentID)
+	if err != nil {
+		return err
+	}
+
+	if details.ClientID != adminID {
+		return utils.NotFoundError("appointment not found")
+	}
+
+	status := input.Status
+
+	if !canTransition(details.Status, status) {
+		return utils.BadRequestError(fmt.Sprintf("cannot change appointment from %s to %s", details.Status, status))
+	}
+
+	switch status {
+	case dtos.StatusCompleted:
+		return repository.WithTx(ctx, func(tx repository.DBTX) error {
+			if err := service.Repo.UpdateStatus(ctx, tx, appointmentID, details.Status, status, ""); err != nil {
+				return err
+			}
+
+			// sessions under an insurance authorization are billed to the plan
+			if details.AuthorizationID != nil {
+				return service.PackageRepo.ReleaseCredit(ctx, tx, appointmentID)
+			}
+
+			_, err := service.chargeSession(ctx, tx, details, "Sessão de "+details.Date.Format("02/01/2006"))
+			return err
+		})
+
+	case dtos.StatusNoShow:
+		return repository.WithTx(ctx, func(tx repository.DBTX) error {
+			if err := service.Repo.UpdateStatus(ctx, tx, appointmentID, details.Status, status, ""); err != nil {
+				return err
+			}
+
+			return service.applyFee(ctx, tx, details, false, details.NoShowFeeCents, input.WaiveFee, "Falta na sessão de "+details.Date.Format("02/01/2006"))
+		})
+
+	case dtos.StatusCancelled:
+
+	default:
+		return service.Repo.UpdateStatus(ctx, repository.DB, appointmentID, details.Status, status, "")
+	}
+
+	cancelledBy := input.CancelledBy
+	if cancelledBy == "" {
+		cancelledBy = "admin"
+	}
+
+	if cancelledBy != "admin" && cancelledBy != "patient" {
+		return utils.BadRequestError("cancelled_by must be admin or patient")
+	}
+
+	// a patient cancelling inside the notice window pays the late-cancel fee;
+	// otherwise a package credit held by the appointment is returned
+	late := cancelledBy == "patient" && time.Until(appointmentStart(details)) < time.Duration(details.MinNoticeHours)*time.Hour
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		if err := service.Repo.UpdateStatus(ctx, tx, appointmentID, details.Status, status, cancelledBy); err != nil {
+			return err
+		}
+
+		if late {
+			if err := service.applyFee(ctx, tx, details, true, details.LateCancelFeeCents, input.WaiveFee, "Cancelamento tardio da sessão de "+details.Date.Format("02/01/2006")); err != nil {
+				return err
+			}
+		} else if err := service.PackageRepo.ReleaseCredit(ctx, tx, appointmentID); err != nil {
+			return err
+		}
+
+		return service.notifyAppointmentChange(ctx, tx, appointmentID, mailer.TemplateAppointmentCancelled)
+	})
+	if err != nil {
+		return err
+	}
+
+	service.offerFreedSlot(ctx, details)
+
+	return nil
+}
+
+// chargeSession spends a package credit on the appointment, charging the
+// session price only when the patient has no credit left. It returns the
+// amount charged.
+func (service *AppointmentService) chargeSession(ctx context.Context, tx repository.DBTX, details dtos.AppointmentDetails, description string) (int64, error) {
+	covered, err := service.PackageRepo.UseCredit(ctx, tx, details.ID)
+	if err != nil || covered {
+		return 0, err
+	}
+
+	if err := service.BillingRepo.ChargeAppointment(ctx, tx, details.ID, description); err != nil {
+		return 0, err
+	}
+
+	return details.SessionPriceCents, nil
+}
+
+// applyFee charges a late-cancel or no-show fee and records it on the
+// appointment. A nil fee is the session price, which a package credit can
+// cover; a fixed fee is always charged and the credit is returned.
+func (service *AppointmentService) applyFee(ctx context.Context, tx repository.DBTX, details dtos.AppointmentDetails, lateCancelled bool, fee *int64, waive bool, description string) error {
+	if waive {
+		if err := service.PackageRepo.ReleaseCredit(ctx, tx, details.ID); err != nil {
+			return err
+		}
+
+		return service.Repo.RecordFee(ctx, tx, details.ID, lateCancelled, 0, true)
+	}
+
+	var charged int64
+
+	if fee == nil {
+		var err error
+
+		charged, err = service.chargeSession(ctx, tx, details, description)
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := service.PackageRepo.ReleaseCredit(ctx, tx, details.ID); err != nil {
+			return err
+		}
+
+		if *fee > 0 {
+			_, err := service.BillingRepo.CreateEntry(ctx, tx, details.ClientID, details.PatientID, uuid.NullUUID{UUID: details.ID, Valid: true}, dtos.LedgerEntryInput{
+				Kind: dtos.EntryCharge,
+				AmountCents: *fee,
+				Description: description,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		charged = *fee
+	}
+
+	return service.Repo.RecordFee(ctx, tx, details.ID, lateCancelled, charged, false)
+}
+
+// WaiveFee forgives the fee of a late cancellation or no-show after it was
+// charged, giving back the package credit or discounting the amount.
+func (service *AppointmentService) WaiveFee(ctx context.Context, appointmentID, adminID uuid.UUID) error {
+	details, err := service.Repo.GetAppointmentDetails(ctx, repository.DB, appointmentID)
+	if err != nil {
+		return err
+	}
+
+	if details.ClientID != adminID {
+		return utils.NotFoundError("appointment not found")
+	}
+
+	description := "Isenção da taxa de falta na sessão de " + details.Date.Format("02/01/2006")
+
+	switch {
+	case details.Status == dtos.StatusNoShow:
+	case details.Status == dtos.StatusCancelled && details.LateCancelled:
+		description = "Isenção da taxa de cancelamento tardio da sessão de " + details.Date.Format("02/01/2006")
+	default:
+		return utils.BadRequestError("only late cancellations and no-shows have a fee")
+	}
+
+	return repository.WithTx(ctx, func(tx repository.DBTX) error {
+		if err := service.Repo.WaiveFee(ctx, tx, appointmentID); err != nil {
+			return err
+		}
+
+		returned, err := service.PackageRepo.ReturnCredit(ctx, tx, appointmentID)
+		if err != nil || returned || details.FeeCents == 0 {
+			return err
+		}
+
+		_, err = service.BillingRepo.CreateEntry(ctx, tx, details.ClientID, details.PatientID, uuid.NullUUID{UUID: appointmentID, Valid: true}, dtos.LedgerEntryInput{
+			Kind: dtos.EntryDiscount,
+			AmountCents: details.FeeCents,
+			Description: description,
+		})
+
+		return err
+	})
+}
+
+// Reschedule moves an open appointment to a new time and sends the patient
+// the updated calendar event with new confirmation links.
+func (service *AppointmentService) Reschedule(ctx context.Context, appointmentID, adminID uuid.UUID, input dtos.RescheduleInput) error {
+	parsedDate, err := utils.ParseDate(input.Date)
+	if err != nil {
+		return utils.BadRequestError("invalid format date")
+	}
+
+	start, err := utils.ParseTime(input.StartTime)
+	if err != nil {
+		return utils.BadRequestError("invalid format start_time")
+	}
+
+	end, err := utils.ParseTime(input.EndTime)
+	if err != nil {
+		return utils.BadRequestError("invalid format end_time")
+	}
+
+	if !start.Before(end) {
+		return utils.BadRequestError("start_time must be before end_time")
+	}
+
+	details, err := service.Repo.GetAppointmentDetails(ctx, repository.DB, appointmentID)
+	if err != nil {
+		return err
+	}
+
+	if details.ClientID != adminID {
+		return utils.NotFoundError("appointment not found")
+	}
+
+	if details.Status != dtos.StatusScheduled && details.Status != dtos.StatusConfirmed {
+		return utils.BadRequestError(fmt.Sprintf("cannot reschedule a %s appointment", details.Status))
+	}
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		if err := service.Repo.Reschedule(ctx, tx, appointmentID, details.Status, parsedDate, start, end); err != nil {
+			return err
+		}
+
+		if err := service.ReminderRepo.ResetReminders(ctx, tx, appointmentID); err != nil {
+			return err
+		}
+
+		if err := service.ReminderRepo.SkipPastReminders(ctx, tx, appointmentID, time.Now().In(utils.AppLocation())); err != nil {
+			return err
+		}
+
+		if details.Modality == dtos.ModalityOnline && service.Video != nil {
+			if err := service.refreshVideoRoom(ctx, tx, appointmentID, details.VideoURL, input); err != nil {
+				utils.LogError("reschedule service (error refreshing video room)", err)
+				return utils.InternalServerError("error creating video room")
+			}
+		}
+
+		return service.notifyAppointmentChange(ctx, tx, appointmentID, mailer.TemplateAppointmentRescheduled)
+	})
+	if err != nil {
+		return err
+	}
+
+	service.offerFreedSlot(ctx, details)
+
+	return nil
+}
+
+// refreshVideoRoom issues a new link for rooms created by the video provider,
+// as join tokens are only valid around the original time. Links typed in by
+// the psychologist are kept as they are.
+func (service *AppointmentService) refreshVideoRoom(ctx context.Context, tx repository.DBTX, appointmentID uuid.UUID, currentURL string, input dtos.RescheduleInput) error {
+	start, err := utils.ParseDateTimeInLocation(input.Date, input.StartTime)
+	if err != nil {
+		return err
+	}
+
+	end, err := utils.ParseDateTimeInLocation(input.Date, input.EndTime)
+	if err != nil {
+		return err
+	}
+
+	room, err := service.Video.CreateRoom(ctx, appointmentID, start, end)
+	if err != nil {
+		return err
+	}
+
+	if currentURL != "" && withoutQuery(currentURL) != withoutQuery(room.URL) {
+		return nil
+	}
+
+	return service.AdminRepo.UpdateAppointmentVideoURL(ctx, tx, appointmentID, room.URL)
+}
+
+// notifyAppointmentChange notifies the patient of the current state of the
+// appointment. It reads the appointment inside tx so the message reflects the
+// change just made.
+func (service *AppointmentService) notifyAppointmentChange(ctx context.Context, tx repository.DBTX, appointmentID uuid.UUID, template string) error {
+	details, err := service.Repo.GetAppointmentDetails(ctx, tx, appointmentID)
+	if err != nil {
+		return err
+	}
+
+	return notifyAppointment(ctx, tx, service.Notifications, template, details)
+}
+
+// offerFreedSlot offers the time the appointment no longer takes to the
+// waitlist. The change is already committed, so failures are only logged.
+func (service *AppointmentService) offerFreedSlot(ctx context.Context, details dtos.AppointmentDetails) {
+	if service.Waitlist == nil {
+		return
+	}
+
+	if err := service.Waitlist.OfferDate(ctx, details.ClientID, details.Date); err != nil {
+		utils.LogError("offerFreedSlot service (error offering slot to the waitlist)", err)
+	}
+}
+
+// RunPatientAction applies the action carried by a signed email link. Running
+// the same link twice is not an error.
+func (service *AppointmentService) RunPatientAction(ctx context.Context, token, action string) (dtos.AppointmentDetails, error) {
+	appointmentID, tokenAction, err := utils.ParseActionToken(token)
+	if err != nil || tokenAction != action {
+		return dtos.AppointmentDetails{}, utils.BadRequestError("invalid or expired link")
+	}
+
+	details, err := service.Repo.GetAppointmentDetails(ctx, repository.DB, appointmentID)
+	if err != nil {
+		return dtos.AppointmentDetails{}, err
+	}
+
+	switch action {
+	case utils.ActionConfirm:
+		if details.Status == dtos.StatusConfirmed {
+			return details, nil
+		}
+
+		if !canTransition(details.Status, dtos.StatusConfirmed) {
+			return dtos.AppointmentDetails{}, utils.BadRequestError("this appointment can no longer be confirmed")
+		}
+
+		if err := service.Repo.UpdateStatus(ctx, repository.DB, appointmentID, details.Status, dtos.StatusConfirmed, ""); err != nil {
+			return dtos.AppointmentDetails{}, err
+		}
+
+	case utils.ActionCancel:
+		if details.Status == dtos.StatusCancelled {
+			return details, nil
+		}
+
+		if !canTransition(details.Status, dtos.StatusCancelled) {
+			return dtos.AppointmentDetails{}, utils.BadRequestError("this appointment can no longer be cancelled")
+		}
+
+		startsAt := appointmentStart(details)
+		if time.Until(startsAt) < time.Duration(details.MinNoticeHours)*time.Hour {
+			return dtos.AppointmentDetails{}, utils.BadRequestError(fmt.Sprintf("cancellations must be made at least %d hours in advance, please contact your psychologist", details.MinNoticeHours))
+		}
+
+		err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+			if err := service.Repo.UpdateStatus(ctx, tx, appointmentID, details.Status, dtos.StatusCancelled, "patient"); err != nil {
+				return err
+			}
+
+			if err := service.PackageRepo.ReleaseCredit(ctx, tx, appointmentID); err != nil {
+				return err
+			}
+
+			if err := service.notifyAppointmentChange(ctx, tx, appointmentID, mailer.TemplateAppointmentCancelled); err != nil {
+				return err
+			}
+
+			return service.notifyPatientCancellation(ctx, tx, details)
+		})
+		if err != nil {
+			return dtos.AppointmentDetails{}, err
+		}
+
+		service.offerFreedSlot(ctx, details)
+
+	default:
+		return dtos.AppointmentDetails{}, utils.BadRequestError("invalid or expired link")
+	}
+
+	return details, nil
+}
+
+func (service *AppointmentService) notifyPatientCancellation(ctx context.Context, tx repository.DBTX, details dtos.AppointmentDetails) error {
+	msg, err := service.Email.BuildMessage(ctx, details.ClientID, mailer.TemplatePatientCancelled, details.AdminEmail, appointmentData(details))
+	if err != nil {
+		return err
+	}
+
+	return service.OutboxRepo.Enqueue(ctx, tx, details.ClientID, msg)
+}
+
+func (service *AppointmentService) GetCancellationSettings(ctx context.Context, adminID uuid.UUID) (dtos.CancellationSettingsOutput, error) {
+	settings, err := service.Repo.GetCancellationSettings(ctx, adminID)
+	if err != nil {
+		return dtos.CancellationSettingsOutput{}, err
+	}
+
+	settings.PolicyText = cancellationPolicyText(settings.MinNoticeHours, settings.LateCancelFeeCents, settings.NoShowFeeCents, settings.SessionPriceCents, settings.Policy)
+
+	return settings, nil
+}
+
+func (service *AppointmentService) UpdateCancellationSettings(ctx context.Context, input dtos.CancellationSettingsInput, adminID uuid.UUID) (dtos.CancellationSettingsOutput, error) {
+	if *input.MinNoticeHours < 0 || *input.MinNoticeHours > 7*24 {
+		return dtos.CancellationSettingsOutput{}, utils.BadRequestError("min_notice_hours must be between 0 and 168")
+	}
+
+	if input.LateCancelFeeCents != nil && (*input.LateCancelFeeCents < 0 || *input.LateCancelFeeCents > maxPriceCents) {
+		return dtos.CancellationSettingsOutput{}, utils.BadRequestError("late_cancel_fee_cents must be between 0 and 100000000")
+	}
+
+	if input.NoShowFeeCents != nil && (*input.NoShowFeeCents < 0 || *input.NoShowFeeCents > maxPriceCents) {
+		return dtos.CancellationSettingsOutput{}, utils.BadRequestError("no_show_fee_cents must be between 0 and 100000000")
+	}
+
+	input.Policy = strings.TrimSpace(input.Policy)
+
+	if utf8.RuneCountInString(input.Policy) > maxPolicyLength {
+		return dtos.CancellationSettingsOutput{}, utils.BadRequestError(fmt.Sprintf("policy must be at most %d characters", maxPolicyLength))
+	}
+
+	if err := service.Repo.UpdateCancellationSettings(ctx, adminID, input); err != nil {
+		return dtos.CancellationSettingsOutput{}, err
+	}
+
+	return service.GetCancellationSettings(ctx, adminID)
+}
+
+func appointmentStart(details dtos.AppointmentDetails) time.Time {
+	loc := utils.AppLocation()
+
+	return time.Date(
+		details.Date.Year(), details.Date.Month(), details.Date.Day(),
+		details.StartTime.Hour(), details.StartTime.Minute(), 0, 0, loc,
+	)
+}
+
+func withoutQuery(rawURL string) string {
+	if i := strings.Index(rawURL, "?"); i >= 0 {
+		return rawURL[:i]
+	}
+
+	return rawURL
+}
\ No newline at end of file
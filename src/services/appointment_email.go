@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/ical"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// notifyAppointment sends template to the patient of the appointment on the
+// channels they chose, with the calendar event attached to the email. The
+// event always carries the same UID, so calendar clients update or remove the
+// copy they already have.
+func notifyAppointment(ctx context.Context, tx repository.DBTX, notifications *NotificationService, template string, details dtos.AppointmentDetails) error {
+	data := appointmentData(details)
+	method := ical.MethodRequest
+
+	if details.Status == dtos.StatusCancelled {
+		method = ical.MethodCancel
+	} else {
+		confirmURL, cancelURL, err := utils.BuildAppointmentActionLinks(details.ID, appointmentStart(details))
+		if err != nil {
+			utils.LogError("notifyAppointment service (error building action links)", err)
+		}
+
+		data.ConfirmURL = confirmURL
+		data.CancelURL = cancelURL
+	}
+
+	contact := dtos.PatientContact{
+		Name:     details.PatientName,
+		Email:    details.PatientEmail,
+		Phone:    details.PatientPhone,
+		Channels: details.PatientChannels,
+	}
+
+	return notifications.NotifyPatient(ctx, tx, details.ClientID, template, contact, data, mailer.Attachment{
+		Filename:    "atendimento.ics",
+		ContentType: fmt.Sprintf("text/calendar; charset=UTF-8; method=%s", method),
+		Data:        ical.BuildCalendar(method, appointmentEvent(details)),
+	})
+}
+
+func appointmentData(details dtos.AppointmentDetails) mailer.AppointmentData {
+	return mailer.AppointmentData{
+		PatientName: details.PatientName,
+		Date:        details.Date.Format("2006-01-02"),
+		StartTime:   details.StartTime.Format("15:04"),
+		EndTime:     details.EndTime.Format("15:04"),
+		Modality:    details.Modality,
+		Address:     details.Address,
+		VideoURL:    details.VideoURL,
+		CancellationPolicy: cancellationPolicyText(details.MinNoticeHours, details.LateCancelFeeCents, details.NoShowFeeCents, details.SessionPriceCents, details.Policy),
+	}
+}
+
+// cancellationPolicyText is the psychologist's own policy text, or one built
+// from the notice window and fees. A nil fee is the session price.
+func cancellationPolicyText(minNoticeHours int, lateCancelFee, noShowFee *int64, sessionPriceCents int64, custom string) string {
+	if custom != "" {
+		return custom
+	}
+
+	var parts []string
+
+	if fee := describeFee(lateCancelFee, sessionPriceCents); fee != "" && minNoticeHours > 0 {
+		parts = append(parts, fmt.Sprintf("Cancelamentos feitos com menos de %d horas de antecedência têm cobrança %s.", minNoticeHours, fee))
+	}
+
+	if fee := describeFee(noShowFee, sessionPriceCents); fee != "" {
+		parts = append(parts, fmt.Sprintf("Faltas sem aviso têm cobrança %s.", fee))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func describeFee(fee *int64, sessionPriceCents int64) string {
+	switch {
+	case fee == nil && sessionPriceCents > 0:
+		return "do valor da sessão (" + utils.FormatBRL(sessionPriceCents) + ")"
+	case fee == nil:
+		return "do valor da sessão"
+	case *fee > 0:
+		return "de " + utils.FormatBRL(*fee)
+	}
+
+	return ""
+}
+
+func appointmentEvent(details dtos.AppointmentDetails) ical.Event {
+	event := ical.Event{
+		UID:       ical.UID(details.ID),
+		Sequence:  details.Sequence,
+		Start:     appointmentStart(details),
+		End:       appointmentEnd(details),
+		Summary:   "Atendimento com " + details.AdminName,
+		Status:    ical.StatusConfirmed,
+		Organizer: &ical.Person{Name: details.AdminName, Email: details.AdminEmail},
+		Attendee:  &ical.Person{Name: details.PatientName, Email: details.PatientEmail},
+	}
+
+	if details.Modality == dtos.ModalityOnline {
+		event.Location = details.VideoURL
+		event.URL = details.VideoURL
+		event.Description = "Sessão online: " + details.VideoURL
+	} else {
+		event.Location = details.Address
+	}
+
+	if details.Status == dtos.StatusCancelled {
+		event.Status = ical.StatusCancelled
+	}
+
+	return event
+}
+
+func appointmentEnd(details dtos.AppointmentDetails) time.Time {
+	loc := utils.AppLocation()
+
+	return time.Date(
+		details.Date.Year(), details.Date.Month(), details.Date.Day(),
+		details.EndTime.Hour(), details.EndTime.Minute(), 0, 0, loc,
+	)
+}
\ No newline at end of file
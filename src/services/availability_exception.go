@@ -0,0 +1,225 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	goical "github.com/emersion/go-ical"
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type busyInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+func (b busyInterval) overlaps(start, end time.Time) bool {
+	return start.Before(b.end) && b.start.Before(end)
+}
+
+func overlapsBusy(intervals []busyInterval, start, end time.Time) bool {
+	for _, interval := range intervals {
+		if interval.overlaps(start, end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseAvailabilityException reads the event a calendar app stored. A
+// resource holds one event, plus the overridden instances when it recurs.
+func parseAvailabilityException(data []byte) (dtos.AvailabilityException, error) {
+	cal, err := goical.NewDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		return dtos.AvailabilityException{}, fmt.Errorf("invalid calendar data: %w", err)
+	}
+
+	events := cal.Events()
+	if len(events) == 0 {
+		return dtos.AvailabilityException{}, fmt.Errorf("calendar object has no event")
+	}
+
+	loc := utils.AppLocation()
+	var exception dtos.AvailabilityException
+
+	for i, event := range events {
+		uid, err := event.Props.Text(goical.PropUID)
+		if err != nil || uid == "" {
+			return dtos.AvailabilityException{}, fmt.Errorf("event has no UID")
+		}
+
+		if i > 0 && uid != exception.UID {
+			return dtos.AvailabilityException{}, fmt.Errorf("calendar object has events with different UIDs")
+		}
+
+		start, err := event.DateTimeStart(loc)
+		if err != nil || start.IsZero() {
+			return dtos.AvailabilityException{}, fmt.Errorf("event has an invalid DTSTART")
+		}
+
+		end, err := event.DateTimeEnd(loc)
+		if err != nil {
+			return dtos.AvailabilityException{}, fmt.Errorf("event has an invalid DTEND")
+		}
+
+		if i == 0 || start.Before(exception.StartsAt) {
+			exception.StartsAt = start
+		}
+
+		if i == 0 || end.After(exception.EndsAt) {
+			exception.EndsAt = end
+		}
+
+		if event.Props.Get(goical.PropRecurrenceID) != nil {
+			continue
+		}
+
+		exception.UID = uid
+		exception.Summary, _ = event.Props.Text(goical.PropSummary)
+		exception.Busy = isBusy(event)
+		exception.Recurring = event.Props.Get(goical.PropRecurrenceRule) != nil
+	}
+
+	if exception.UID == "" {
+		exception.UID, _ = events[0].Props.Text(goical.PropUID)
+		exception.Busy = isBusy(events[0])
+	}
+
+	if exception.EndsAt.Before(exception.StartsAt) {
+		return dtos.AvailabilityException{}, fmt.Errorf("event ends before it starts")
+	}
+
+	exception.Data = string(data)
+
+	return exception, nil
+}
+
+// isBusy follows what calendar apps show as "busy": opaque, not cancelled.
+func isBusy(event goical.Event) bool {
+	if transp, _ := event.Props.Text(goical.PropTransparency); transp == "TRANSPARENT" {
+		return false
+	}
+
+	if status, _ := event.Props.Text(goical.PropStatus); status == "CANCELLED" {
+		return false
+	}
+
+	return true
+}
+
+// busyIntervals returns the time blocked by availability exceptions in
+// [from, to), expanding recurring ones.
+func busyIntervals(ctx context.Context, repo *repository.AvailabilityExceptionRepository, adminID uuid.UUID, from, to time.Time) ([]busyInterval, error) {
+	exceptions, err := repo.GetBusy(ctx, adminID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var intervals []busyInterval
+
+	for _, exception := range exceptions {
+		if !exception.Recurring {
+			intervals = append(intervals, busyInterval{start: exception.StartsAt, end: exception.EndsAt})
+			continue
+		}
+
+		occurrences, err := expandRecurring(exception.Data, from, to)
+		if err != nil {
+			// the event was accepted on PUT, so this is unexpected: block only
+			// the first occurrence rather than failing the whole availability
+			utils.LogError("busyIntervals service (error expanding recurring exception)", err)
+			intervals = append(intervals, busyInterval{start: exception.StartsAt, end: exception.EndsAt})
+			continue
+		}
+
+		intervals = append(intervals, occurrences...)
+	}
+
+	return intervals, nil
+}
+
+func expandRecurring(data string, from, to time.Time) ([]busyInterval, error) {
+	cal, err := goical.NewDecoder(bytes.NewReader([]byte(data))).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	loc := utils.AppLocation()
+	overridden := make(map[int64]bool)
+
+	var intervals []busyInterval
+	var master *goical.Event
+
+	for _, event := range cal.Events() {
+		event := event
+
+		recurrenceID := event.Props.Get(goical.PropRecurrenceID)
+		if recurrenceID == nil {
+			master = &event
+			continue
+		}
+
+		if id, err := recurrenceID.DateTime(loc); err == nil {
+			overridden[id.Unix()] = true
+		}
+
+		start, err := event.DateTimeStart(loc)
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := event.DateTimeEnd(loc)
+		if err != nil {
+			return nil, err
+		}
+
+		interval := busyInterval{start: start, end: end}
+		if isBusy(event) && interval.overlaps(from, to) {
+			intervals = append(intervals, interval)
+		}
+	}
+
+	if master == nil || !isBusy(*master) {
+		return intervals, nil
+	}
+
+	start, err := master.DateTimeStart(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := master.DateTimeEnd(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := master.RecurrenceSet(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := end.Sub(start)
+
+	if set == nil {
+		return append(intervals, busyInterval{start: start, end: end}), nil
+	}
+
+	for _, occurrence := range set.Between(from.Add(-duration), to, true) {
+		if overridden[occurrence.Unix()] {
+			continue
+		}
+
+		interval := busyInterval{start: occurrence, end: occurrence.Add(duration)}
+		if interval.overlaps(from, to) {
+			intervals = append(intervals, interval)
+		}
+	}
+
+	return intervals, nil
+}
\ No newline at end of file
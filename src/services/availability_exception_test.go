@@ -0,0 +1,186 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// calendar wraps VEVENT lines in a VCALENDAR with CRLF line endings.
+func calendar(lines ...string) string {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Test//Test//PT"}, lines...)
+	all = append(all, "END:VCALENDAR")
+
+	return strings.Join(all, "\r\n") + "\r\n"
+}
+
+func event(lines ...string) []string {
+	all := append([]string{"BEGIN:VEVENT", "DTSTAMP:20250101T000000Z"}, lines...)
+	return append(all, "END:VEVENT")
+}
+
+func utc(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", value)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestParseAvailabilityException(t *testing.T) {
+	weekly := calendar(append(
+		event("UID:curso", "SUMMARY:Curso", "DTSTART:20250303T090000Z", "DTEND:20250303T100000Z", "RRULE:FREQ=WEEKLY;COUNT=4"),
+		event("UID:curso", "RECURRENCE-ID:20250310T090000Z", "DTSTART:20250310T140000Z", "DTEND:20250310T150000Z")...,
+	)...)
+
+	tests := []struct {
+		name          string
+		data          string
+		wantUID       string
+		wantSummary   string
+		wantBusy      bool
+		wantRecurring bool
+		wantStart     time.Time
+		wantEnd       time.Time
+	}{
+		{
+			"busy event",
+			calendar(event("UID:congresso", "SUMMARY:Congresso", "DTSTART:20250312T090000Z", "DTEND:20250312T180000Z")...),
+			"congresso", "Congresso", true, false, utc("2025-03-12 09:00"), utc("2025-03-12 18:00"),
+		},
+		{
+			"transparent event",
+			calendar(event("UID:lembrete", "SUMMARY:Lembrete", "TRANSP:TRANSPARENT", "DTSTART:20250312T090000Z", "DTEND:20250312T093000Z")...),
+			"lembrete", "Lembrete", false, false, utc("2025-03-12 09:00"), utc("2025-03-12 09:30"),
+		},
+		{
+			"cancelled event",
+			calendar(event("UID:cancelado", "STATUS:CANCELLED", "DTSTART:20250312T090000Z", "DTEND:20250312T100000Z")...),
+			"cancelado", "", false, false, utc("2025-03-12 09:00"), utc("2025-03-12 10:00"),
+		},
+		{
+			"recurring event with an override",
+			weekly,
+			"curso", "Curso", true, true, utc("2025-03-03 09:00"), utc("2025-03-10 15:00"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAvailabilityException([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseAvailabilityException() error = %v", err)
+			}
+
+			if got.UID != tt.wantUID || got.Summary != tt.wantSummary || got.Busy != tt.wantBusy || got.Recurring != tt.wantRecurring {
+				t.Errorf("got uid %q, summary %q, busy %v, recurring %v; want %q, %q, %v, %v",
+					got.UID, got.Summary, got.Busy, got.Recurring, tt.wantUID, tt.wantSummary, tt.wantBusy, tt.wantRecurring)
+			}
+
+			if !got.StartsAt.Equal(tt.wantStart) || !got.EndsAt.Equal(tt.wantEnd) {
+				t.Errorf("got %v to %v, want %v to %v", got.StartsAt, got.EndsAt, tt.wantStart, tt.wantEnd)
+			}
+
+			if got.Data != tt.data {
+				t.Error("the original data was not kept")
+			}
+		})
+	}
+}
+
+func TestParseAvailabilityExceptionInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not a calendar", "hello"},
+		{"no event", calendar()},
+		{"no uid", calendar(event("DTSTART:20250312T090000Z", "DTEND:20250312T100000Z")...)},
+		{"no start", calendar(event("UID:a", "DTEND:20250312T100000Z")...)},
+		{"ends before it starts", calendar(event("UID:a", "DTSTART:20250312T100000Z", "DTEND:20250312T090000Z")...)},
+		{"different uids", calendar(append(
+			event("UID:a", "DTSTART:20250312T090000Z", "DTEND:20250312T100000Z"),
+			event("UID:b", "DTSTART:20250313T090000Z", "DTEND:20250313T100000Z")...,
+		)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseAvailabilityException([]byte(tt.data)); err == nil {
+				t.Error("parseAvailabilityException() succeeded")
+			}
+		})
+	}
+}
+
+func TestExpandRecurring(t *testing.T) {
+	master := []string{"UID:curso", "DTSTART:20250303T090000Z", "DTEND:20250303T100000Z", "RRULE:FREQ=WEEKLY;COUNT=4"}
+
+	tests := []struct {
+		name string
+		data string
+		from time.Time
+		to   time.Time
+		want []string
+	}{
+		{
+			"every occurrence in the range",
+			calendar(event(master...)...),
+			utc("2025-03-01 00:00"), utc("2025-04-01 00:00"),
+			[]string{"2025-03-03 09:00", "2025-03-10 09:00", "2025-03-17 09:00", "2025-03-24 09:00"},
+		},
+		{
+			"only the occurrences that overlap the range",
+			calendar(event(master...)...),
+			utc("2025-03-10 09:30"), utc("2025-03-17 09:00"),
+			[]string{"2025-03-10 09:00"},
+		},
+		{
+			"moved occurrence",
+			calendar(append(event(master...), event("UID:curso", "RECURRENCE-ID:20250310T090000Z", "DTSTART:20250310T140000Z", "DTEND:20250310T150000Z")...)...),
+			utc("2025-03-10 00:00"), utc("2025-03-11 00:00"),
+			[]string{"2025-03-10 14:00"},
+		},
+		{
+			"cancelled occurrence",
+			calendar(append(event(master...), event("UID:curso", "RECURRENCE-ID:20250310T090000Z", "STATUS:CANCELLED", "DTSTART:20250310T090000Z", "DTEND:20250310T100000Z")...)...),
+			utc("2025-03-01 00:00"), utc("2025-04-01 00:00"),
+			[]string{"2025-03-03 09:00", "2025-03-17 09:00", "2025-03-24 09:00"},
+		},
+		{
+			"excluded date",
+			calendar(event(append(master, "EXDATE:20250317T090000Z")...)...),
+			utc("2025-03-01 00:00"), utc("2025-04-01 00:00"),
+			[]string{"2025-03-03 09:00", "2025-03-10 09:00", "2025-03-24 09:00"},
+		},
+		{
+			"transparent series",
+			calendar(event(append(master, "TRANSP:TRANSPARENT")...)...),
+			utc("2025-03-01 00:00"), utc("2025-04-01 00:00"),
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intervals, err := expandRecurring(tt.data, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("expandRecurring() error = %v", err)
+			}
+
+			got := make([]string, 0, len(intervals))
+			for _, interval := range intervals {
+				if interval.end.Sub(interval.start) != time.Hour {
+					t.Errorf("interval %v to %v does not last an hour", interval.start, interval.end)
+				}
+
+				got = append(got, interval.start.UTC().Format("2006-01-02 15:04"))
+			}
+
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
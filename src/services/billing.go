@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const maxPriceCents = 100000000
+
+type BillingService struct {
+	Repo *repository.BillingRepository
+	PatientRepo *repository.PatientRepository
+}
+
+func (service *BillingService) GetPricing(ctx context.Context, adminID uuid.UUID) (dtos.PricingOutput, error) {
+	return service.Repo.GetPricing(ctx, adminID)
+}
+
+func (service *BillingService) UpdatePricing(ctx context.Context, input dtos.PricingInput, adminID uuid.UUID) (dtos.PricingOutput, error) {
+	if err := validatePrice(*input.SessionPriceCents); err != nil {
+		return dtos.PricingOutput{}, err
+	}
+
+	if err := service.Repo.UpdatePricing(ctx, adminID, *input.SessionPriceCents); err != nil {
+		return dtos.PricingOutput{}, err
+	}
+
+	return dtos.PricingOutput{SessionPriceCents: *input.SessionPriceCents}, nil
+}
+
+func (service *BillingService) GetPatientPrice(ctx context.Context, patientID, adminID uuid.UUID) (dtos.PatientPriceOutput, error) {
+	return service.Repo.GetPatientPrice(ctx, patientID, adminID)
+}
+
+func (service *BillingService) UpdatePatientPrice(ctx context.Context, patientID, adminID uuid.UUID, input dtos.PatientPriceInput) (dtos.PatientPriceOutput, error) {
+	var price sql.NullInt64
+
+	if input.SessionPriceCents != nil {
+		if err := validatePrice(*input.SessionPriceCents); err != nil {
+			return dtos.PatientPriceOutput{}, err
+		}
+
+		price = sql.NullInt64{Int64: *input.SessionPriceCents, Valid: true}
+	}
+
+	if err := service.Repo.UpdatePatientPrice(ctx, patientID, adminID, price); err != nil {
+		return dtos.PatientPriceOutput{}, err
+	}
+
+	return service.Repo.GetPatientPrice(ctx, patientID, adminID)
+}
+
+// GetLedger returns the patient's account. Pass uuid.Nil as clientID when the
+// patient asks for their own ledger.
+func (service *BillingService) GetLedger(ctx context.Context, patientID, clientID uuid.UUID) (dtos.LedgerOutput, error) {
+	if _, err := service.PatientRepo.GetContact(ctx, patientID, clientID); err != nil {
+		return dtos.LedgerOutput{}, err
+	}
+
+	entries, err := service.Repo.GetLedger(ctx, patientID)
+	if err != nil {
+		return dtos.LedgerOutput{}, err
+	}
+
+	ledger := dtos.LedgerOutput{PatientID: patientID, Entries: entries}
+
+	if len(entries) > 0 {
+		ledger.BalanceCents = entries[len(entries)-1].BalanceCents
+	}
+
+	return ledger, nil
+}
+
+// AddEntry records a manual charge, payment, discount or refund.
+func (service *BillingService) AddEntry(ctx context.Context, patientID, adminID uuid.UUID, input dtos.LedgerEntryInput) (uuid.UUID, error) {
+	switch input.Kind {
+	case dtos.EntryCharge, dtos.EntryPayment, dtos.EntryDiscount, dtos.EntryRefund:
+	default:
+		return uuid.UUID{}, utils.BadRequestError("kind must be charge, payment, discount or refund")
+	}
+
+	if input.AmountCents <= 0 || input.AmountCents > maxPriceCents {
+		return uuid.UUID{}, utils.BadRequestError("amount_cents must be between 1 and 100000000")
+	}
+
+	input.Description = strings.TrimSpace(input.Description)
+	if len(input.Description) > 200 {
+		return uuid.UUID{}, utils.BadRequestError("description must be at most 200 characters")
+	}
+
+	input.Method = strings.ToLower(strings.TrimSpace(input.Method))
+	if len(input.Method) > 40 {
+		return uuid.UUID{}, utils.BadRequestError("method must be at most 40 characters")
+	}
+
+	if _, err := service.PatientRepo.GetContact(ctx, patientID, adminID); err != nil {
+		return uuid.UUID{}, err
+	}
+
+	return service.Repo.CreateEntry(ctx, repository.DB, adminID, patientID, uuid.NullUUID{}, input)
+}
+
+func (service *BillingService) GetBalances(ctx context.Context, adminID uuid.UUID) ([]dtos.PatientBalanceOutput, error) {
+	return service.Repo.GetBalances(ctx, adminID)
+}
+
+// resolveAmount works out what a payment request charges: the amount given,
+// else the ledger charge in entryID, else the patient's whole balance. The
+// description defaults to the charge's.
+func resolveAmount(ctx context.Context, billingRepo *repository.BillingRepository, patientID uuid.UUID, entryIDStr string, amountCents *int64, description string) (uuid.NullUUID, int64, string, error) {
+	var (
+		entryID uuid.NullUUID
+		amount  int64
+		err     error
+	)
+
+	description = strings.TrimSpace(description)
+	if len(description) > 200 {
+		return uuid.NullUUID{}, 0, "", utils.BadRequestError("description must be at most 200 characters")
+	}
+
+	if entryIDStr != "" {
+		id, err := uuid.Parse(entryIDStr)
+		if err != nil {
+			return uuid.NullUUID{}, 0, "", utils.BadRequestError("invalid entry id")
+		}
+
+		entry, err := billingRepo.GetEntry(ctx, id, patientID)
+		if err != nil {
+			return uuid.NullUUID{}, 0, "", err
+		}
+
+		if entry.Kind != dtos.EntryCharge {
+			return uuid.NullUUID{}, 0, "", utils.BadRequestError("entry_id must be a charge")
+		}
+
+		entryID = uuid.NullUUID{UUID: id, Valid: true}
+		amount = entry.AmountCents
+
+		if description == "" {
+			description = entry.Description
+		}
+	} else {
+		amount, err = billingRepo.GetBalance(ctx, patientID)
+		if err != nil {
+			return uuid.NullUUID{}, 0, "", err
+		}
+	}
+
+	if amountCents != nil {
+		amount = *amountCents
+	}
+
+	if amount <= 0 || amount > maxPriceCents {
+		return uuid.NullUUID{}, 0, "", utils.BadRequestError("amount_cents must be between 1 and 100000000")
+	}
+
+	return entryID, amount, description, nil
+}
+
+func validatePrice(priceCents int64) error {
+	if priceCents < 0 || priceCents > maxPriceCents {
+		return utils.BadRequestError("session_price_cents must be between 0 and 100000000")
+	}
+
+	return nil
+}
\ No newline at end of file
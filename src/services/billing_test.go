@@ -0,0 +1,32 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+func TestValidatePrice(t *testing.T) {
+	tests := []struct {
+		cents   int64
+		wantErr bool
+	}{
+		{-1, true},
+		{0, false},
+		{15000, false},
+		{maxPriceCents, false},
+		{maxPriceCents + 1, true},
+	}
+
+	for _, tt := range tests {
+		err := validatePrice(tt.cents)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validatePrice(%d) error = %v, wantErr %v", tt.cents, err, tt.wantErr)
+		}
+
+		if err != nil && utils.GetStatusCode(err) != http.StatusBadRequest {
+			t.Errorf("validatePrice(%d) status = %d, want %d", tt.cents, utils.GetStatusCode(err), http.StatusBadRequest)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const maxBookingHoldHours = 7 * 24
+
+type BookingService struct {
+	Repo          *repository.BookingRepository
+	Admin         *AdminService
+	Notifications *NotificationService
+	Waitlist      *WaitlistService
+}
+
+func (service *BookingService) GetSettings(ctx context.Context, adminID uuid.UUID) (dtos.BookingSettingsOutput, error) {
+	return service.Repo.GetSettings(ctx, adminID)
+}
+
+func (service *BookingService) UpdateSettings(ctx context.Context, input dtos.BookingSettingsInput, adminID uuid.UUID) (dtos.BookingSettingsOutput, error) {
+	if input.Mode != dtos.BookingInstant && input.Mode != dtos.BookingApproval {
+		return dtos.BookingSettingsOutput{}, utils.BadRequestError("mode must be instant or approval")
+	}
+
+	if *input.HoldHours < 1 || *input.HoldHours > maxBookingHoldHours {
+		return dtos.BookingSettingsOutput{}, utils.BadRequestError("hold_hours must be between 1 and 168")
+	}
+
+	if err := service.Repo.UpdateSettings(ctx, adminID, input.Mode, *input.HoldHours); err != nil {
+		return dtos.BookingSettingsOutput{}, err
+	}
+
+	return dtos.BookingSettingsOutput{Mode: input.Mode, HoldHours: *input.HoldHours}, nil
+}
+
+func (service *BookingService) GetRules(ctx context.Context, adminID uuid.UUID) (dtos.BookingRulesOutput, error) {
+	return service.Admin.Repo.GetBookingRules(ctx, adminID)
+}
+
+func (service *BookingService) UpdateRules(ctx context.Context, input dtos.BookingRulesInput, adminID uuid.UUID) (dtos.BookingRulesOutput, error) {
+	rules := dtos.BookingRulesOutput{
+		MinNoticeHours: *input.MinNoticeHours,
+		MaxHorizonDays: *input.MaxHorizonDays,
+		MaxPerDay: *input.MaxPerDay,
+		MaxPerWeek: *input.MaxPerWeek,
+		MaxOpenPerPatient: *input.MaxOpenPerPatient,
+	}
+
+	if rules.MinNoticeHours < 0 || rules.MinNoticeHours > 30*24 {
+		return dtos.BookingRulesOutput{}, utils.BadRequestError("min_notice_hours must be between 0 and 720")
+	}
+
+	if rules.MaxHorizonDays < 1 || rules.MaxHorizonDays > 365 {
+		return dtos.BookingRulesOutput{}, utils.BadRequestError("max_horizon_days must be between 1 and 365")
+	}
+
+	if rules.MinNoticeHours > rules.MaxHorizonDays*24 {
+		return dtos.BookingRulesOutput{}, utils.BadRequestError("min_notice_hours must fit within max_horizon_days")
+	}
+
+	if rules.MaxPerDay < 0 || rules.MaxPerWeek < 0 || rules.MaxOpenPerPatient < 0 {
+		return dtos.BookingRulesOutput{}, utils.BadRequestError("limits must be 0 (no limit) or greater")
+	}
+
+	if err := service.Repo.UpdateRules(ctx, adminID, rules); err != nil {
+		return dtos.BookingRulesOutput{}, err
+	}
+
+	return rules, nil
+}
+
+// Book books an available slot for the patient. In approval mode the slot is
+// only held by a request until the psychologist answers it.
+func (service *BookingService) Book(ctx context.Context, patientID uuid.UUID, input dtos.BookingInput) (dtos.BookingOutput, error) {
+	clientID, settings, err := service.Repo.GetPatientSettings(ctx, patientID)
+	if err != nil {
+		return dtos.BookingOutput{}, err
+	}
+
+	parsedDate, err := utils.ParseDate(input.Date)
+	if err != nil {
+		return dtos.BookingOutput{}, utils.BadRequestError("invalid format date")
+	}
+
+	start, err := utils.ParseTime(input.StartTime)
+	if err != nil {
+		return dtos.BookingOutput{}, utils.BadRequestError("invalid format start_time")
+	}
+
+	end, err := utils.ParseTime(input.EndTime)
+	if err != nil {
+		return dtos.BookingOutput{}, utils.BadRequestError("invalid format end_time")
+	}
+
+	startsAt, err := utils.ParseDateTimeInLocation(input.Date, input.StartTime)
+	if err != nil {
+		return dtos.BookingOutput{}, utils.BadRequestError("invalid format start_time")
+	}
+
+	rules, err := service.Admin.Repo.GetBookingRules(ctx, clientID)
+	if err != nil {
+		return dtos.BookingOutput{}, err
+	}
+
+	if err := checkBookingNotice(rules, startsAt); err != nil {
+		return dtos.BookingOutput{}, err
+	}
+
+	if err := service.Admin.checkBookingDay(ctx, clientID, rules, parsedDate); err != nil {
+		return dtos.BookingOutput{}, err
+	}
+
+	now := time.Now()
+
+	locationID, modality, err := resolveLocation(ctx, service.Admin.LocationRepo, clientID, input.LocationID, input.Modality)
+	if err != nil {
+		return dtos.BookingOutput{}, err
+	}
+
+	if settings.Mode != dtos.BookingApproval {
+		appointment := dtos.AppointmentInput{
+			PatientID: patientID.String(),
+			Date: input.Date,
+			StartTime: input.StartTime,
+			EndTime: input.EndTime,
+			LocationID: input.LocationID,
+			Modality: modality,
+		}
+
+		var appointmentID uuid.UUID
+
+		err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+			if err := service.checkOpenBookings(ctx, tx, patientID, rules, now); err != nil {
+				return err
+			}
+
+			if err := service.lockSlot(ctx, tx, clientID, parsedDate, input, modality); err != nil {
+				return err
+			}
+
+			appointmentID, err = service.Admin.bookAppointment(ctx, tx, appointment, parsedDate, start, end, locationID, clientID)
+			return err
+		})
+		if err != nil {
+			return dtos.BookingOutput{}, err
+		}
+
+		return dtos.BookingOutput{Status: dtos.StatusScheduled, AppointmentID: &appointmentID}, nil
+	}
+
+	expiresAt := now.Add(time.Duration(settings.HoldHours) * time.Hour)
+	if expiresAt.After(startsAt) {
+		expiresAt = startsAt
+	}
+
+	var requestID uuid.UUID
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		if err := service.checkOpenBookings(ctx, tx, patientID, rules, now); err != nil {
+			return err
+		}
+
+		if err := service.lockSlot(ctx, tx, clientID, parsedDate, input, modality); err != nil {
+			return err
+		}
+
+		// a request that ran out of time still holds the slot in the
+		// database until the sweep closes it
+		ids, err := service.Repo.ExpireSlotRequests(ctx, tx, clientID, parsedDate, start, now)
+		if err != nil {
+			return err
+		}
+
+		if _, err := service.closeExpired(ctx, tx, ids); err != nil {
+			return err
+		}
+
+		requestID, err = service.Repo.CreateRequest(ctx, tx, clientID, patientID, parsedDate, start, end, locationID, modality, expiresAt)
+		if err != nil {
+			return err
+		}
+
+		request, err := service.Repo.GetRequest(ctx, tx, requestID)
+		if err != nil {
+			return err
+		}
+
+		msg, err := service.Notifications.Email.BuildMessage(ctx, clientID, mailer.TemplateBookingRequested, request.AdminEmail, bookingData(request))
+		if err != nil {
+			return err
+		}
+
+		return service.Notifications.OutboxRepo.Enqueue(ctx, tx, clientID, msg)
+	})
+	if err != nil {
+		return dtos.BookingOutput{}, err
+	}
+
+	return dtos.BookingOutput{Status: dtos.RequestPending, RequestID: &requestID, ExpiresAt: &expiresAt}, nil
+}
+
+func (service *BookingService) GetRequests(ctx context.Context, adminID uuid.UUID, status string) ([]dtos.BookingRequestOutput, error) {
+	switch status {
+	case "", dtos.RequestPending, dtos.RequestAccepted, dtos.RequestDeclined, dtos.RequestExpired, dtos.RequestWithdrawn:
+	default:
+		return nil, utils.BadRequestError("status must be pending, accepted, declined, expired or withdrawn")
+	}
+
+	return service.Repo.GetRequests(ctx, adminID, uuid.Nil, status)
+}
+
+func (service *BookingService) GetPatientRequests(ctx context.Context, patientID uuid.UUID) ([]dtos.BookingRequestOutput, error) {
+	return service.Repo.GetRequests(ctx, uuid.Nil, patientID, "")
+}
+
+// Accept turns the request into an appointment. The patient receives the
+// usual confirmation message. When the time was booked some other way while
+// the request waited, the request is declined instead.
+func (service *BookingService) Accept(ctx context.Context, requestID, adminID uuid.UUID) (uuid.UUID, error) {
+	var appointmentID uuid.UUID
+
+	taken := false
+
+	err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+		request, err := service.openRequest(ctx, tx, requestID)
+		if err != nil {
+			return err
+		}
+
+		if request.ClientID != adminID {
+			return utils.NotFoundError("booking request not found")
+		}
+
+		// bookings of the day wait for the answer, so the time stays free
+		// between the check and the insert
+		if err := service.Admin.Repo.LockDay(ctx, tx, request.ClientID, request.Date); err != nil {
+			return err
+		}
+
+		available, err := service.requestSlotFree(ctx, request)
+		if err != nil {
+			return err
+		}
+
+		if !available {
+			taken = true
+
+			reason := "O horário foi ocupado por outro atendimento."
+			if err := service.Repo.Respond(ctx, tx, requestID, dtos.RequestDeclined, uuid.NullUUID{}, reason); err != nil {
+				return err
+			}
+
+			return service.notifyDeclined(ctx, tx, request, reason)
+		}
+
+		input := dtos.AppointmentInput{
+			PatientID: request.PatientID.String(),
+			Date: request.Date.Format("2006-01-02"),
+			StartTime: request.StartTime.Format("15:04"),
+			EndTime: request.EndTime.Format("15:04"),
+			Modality: request.Modality,
+		}
+
+		appointmentID, err = service.Admin.bookAppointment(ctx, tx, input, request.Date, request.StartTime, request.EndTime, request.LocationID, request.ClientID)
+		if err != nil {
+			return err
+		}
+
+		return service.Repo.Respond(ctx, tx, requestID, dtos.RequestAccepted, uuid.NullUUID{UUID: appointmentID, Valid: true}, "")
+	})
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	if taken {
+		return uuid.UUID{}, utils.ConflictError("this time is no longer available")
+	}
+
+	return appointmentID, nil
+}
+
+// Decline refuses the request, tells the patient why and releases the slot.
+func (service *BookingService) Decline(ctx context.Context, requestID, adminID uuid.UUID, input dtos.BookingDeclineInput) error {
+	reason := strings.TrimSpace(input.Reason)
+	if len(reason) > 500 {
+		return utils.BadRequestError("reason must be at most 500 characters")
+	}
+
+	var request dtos.BookingRequest
+
+	err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+		var err error
+
+		request, err = service.openRequest(ctx, tx, requestID)
+		if err != nil {
+			return err
+		}
+
+		if request.ClientID != adminID {
+			return utils.NotFoundError("booking request not found")
+		}
+
+		if err := service.Repo.Respond(ctx, tx, requestID, dtos.RequestDeclined, uuid.NullUUID{}, reason); err != nil {
+			return err
+		}
+
+		return service.notifyDeclined(ctx, tx, request, reason)
+	})
+	if err != nil {
+		return err
+	}
+
+	service.releaseSlot(ctx, request)
+
+	return nil
+}
+
+// Withdraw lets the patient drop a request still waiting for an answer.
+func (service *BookingService) Withdraw(ctx context.Context, requestID, patientID uuid.UUID) error {
+	var request dtos.BookingRequest
+
+	err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+		var err error
+
+		request, err = service.Repo.GetRequest(ctx, tx, requestID)
+		if err != nil {
+			return err
+		}
+
+		if request.PatientID != patientID {
+			return utils.NotFoundError("booking request not found")
+		}
+
+		return service.Repo.Respond(ctx, tx, requestID, dtos.RequestWithdrawn, uuid.NullUUID{}, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	service.releaseSlot(ctx, request)
+
+	return nil
+}
+
+// ExpireRequests closes the requests the psychologist did not answer in time.
+// Patients are told the request was not accepted and the slots go back to
+// the agenda.
+func (service *BookingService) ExpireRequests(ctx context.Context) error {
+	var expired []dtos.BookingRequest
+
+	err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+		ids, err := service.Repo.ExpireRequests(ctx, tx, time.Now())
+		if err != nil {
+			return err
+		}
+
+		expired, err = service.closeExpired(ctx, tx, ids)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, request := range expired {
+		service.releaseSlot(ctx, request)
+	}
+
+	return nil
+}
+
+// closeExpired tells the patients of the expired requests they were not
+// accepted.
+func (service *BookingService) closeExpired(ctx context.Context, tx repository.DBTX, ids []uuid.UUID) ([]dtos.BookingRequest, error) {
+	expired := make([]dtos.BookingRequest, 0, len(ids))
+
+	for _, id := range ids {
+		request, err := service.Repo.GetRequest(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := service.notifyDeclined(ctx, tx, request, "O horário não foi confirmado a tempo."); err != nil {
+			return nil, err
+		}
+
+		expired = append(expired, request)
+	}
+
+	return expired, nil
+}
+
+// openRequest locks the request and checks it can still be answered.
+func (service *BookingService) openRequest(ctx context.Context, tx repository.DBTX, requestID uuid.UUID) (dtos.BookingRequest, error) {
+	request, err := service.Repo.GetRequest(ctx, tx, requestID)
+	if err != nil {
+		return dtos.BookingRequest{}, err
+	}
+
+	if request.Status != dtos.RequestPending {
+		return dtos.BookingRequest{}, utils.ConflictError("this booking request was already answered")
+	}
+
+	if !time.Now().Before(request.ExpiresAt) {
+		return dtos.BookingRequest{}, utils.BadRequestError("this booking request has expired")
+	}
+
+	return request, nil
+}
+
+func (service *BookingService) notifyDeclined(ctx context.Context, tx repository.DBTX, request dtos.BookingRequest, reason string) error {
+	data := bookingData(request)
+	data.Reason = reason
+
+	return service.Notifications.NotifyPatient(ctx, tx, request.ClientID, mailer.TemplateBookingDeclined, request.Patient, data)
+}
+
+// releaseSlot offers the slot the request held to the waitlist. The request
+// is already closed, so failures are only logged.
+func (service *BookingService) releaseSlot(ctx context.Context, request dtos.BookingRequest) {
+	if service.Waitlist == nil {
+		return
+	}
+
+	if err := service.Waitlist.OfferDate(ctx, request.ClientID, request.Date); err != nil {
+		utils.LogError("releaseSlot booking service (error offering slot to the waitlist)", err)
+	}
+}
+
+// checkOpenBookings enforces MaxOpenPerPatient. The patient stays locked
+// until tx ends, so bookings made at the same time, on any day, cannot all
+// pass the check.
+func (service *BookingService) checkOpenBookings(ctx context.Context, tx repository.DBTX, patientID uuid.UUID, rules dtos.BookingRulesOutput, now time.Time) error {
+	if rules.MaxOpenPerPatient == 0 {
+		return nil
+	}
+
+	if err := service.Repo.LockPatient(ctx, tx, patientID); err != nil {
+		return err
+	}
+
+	open, err := service.Repo.CountOpenBookings(ctx, tx, patientID, now.In(utils.AppLocation()).Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+
+	if open >= rules.MaxOpenPerPatient {
+		return utils.ConflictError(fmt.Sprintf("you can have at most %d upcoming sessions booked", rules.MaxOpenPerPatient))
+	}
+
+	return nil
+}
+
+// lockSlot waits for the other bookings of the day to finish and then checks
+// the slot is still free. The lock lasts until tx ends, so nothing can take
+// the slot between the check and the insert.
+func (service *BookingService) lockSlot(ctx context.Context, tx repository.DBTX, clientID uuid.UUID, date time.Time, input dtos.BookingInput, modality string) error {
+	if err := service.Admin.Repo.LockDay(ctx, tx, clientID, date); err != nil {
+		return err
+	}
+
+	slots, err := service.Admin.GetAvaliableSlots(ctx, clientID, input.Date, strings.TrimSpace(input.LocationID), modality)
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range slots {
+		if slot.StartTime == input.StartTime && slot.EndTime == input.EndTime {
+			return nil
+		}
+	}
+
+	return utils.ConflictError("this time is no longer available")
+}
+
+// requestSlotFree reports whether no appointment overlaps the request. The
+// request's own hold is not counted, so the usual slot list does not fit.
+func (service *BookingService) requestSlotFree(ctx context.Context, request dtos.BookingRequest) (bool, error) {
+	appointments, err := service.Admin.Repo.GetAppointmentsByDate(ctx, request.ClientID, request.Date.Format("2006-01-02"))
+	if err != nil {
+		return false, err
+	}
+
+	start, end := request.StartTime.Format("15:04"), request.EndTime.Format("15:04")
+
+	for _, appointment := range appointments {
+		if appointment.StartTime < end && start < appointment.EndTime {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func bookingData(request dtos.BookingRequest) mailer.AppointmentData {
+	data := mailer.AppointmentData{
+		PatientName: request.Patient.Name,
+		Date:        request.Date.Format("2006-01-02"),
+		StartTime:   request.StartTime.Format("15:04"),
+		EndTime:     request.EndTime.Format("15:04"),
+		Modality:    request.Modality,
+		ExpiresAt:   request.ExpiresAt.In(utils.AppLocation()).Format("02/01/2006 15:04"),
+	}
+
+	if request.Modality != dtos.ModalityOnline {
+		data.Address = request.Address
+	}
+
+	return data
+}
\ No newline at end of file
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// checkBookingDay tells whether patients can still book sessions on day
+// under the psychologist's booking horizon and daily and weekly caps.
+func (service *AdminService) checkBookingDay(ctx context.Context, adminID uuid.UUID, rules dtos.BookingRulesOutput, day time.Time) error {
+	loc := utils.AppLocation()
+	now := time.Now().In(loc)
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+
+	if day.After(today.AddDate(0, 0, rules.MaxHorizonDays)) {
+		return utils.BadRequestError(fmt.Sprintf("sessions can be booked at most %d days in advance", rules.MaxHorizonDays))
+	}
+
+	if rules.MaxPerDay > 0 {
+		date := day.Format("2006-01-02")
+
+		total, err := service.Repo.CountBookings(ctx, adminID, date, date)
+		if err != nil {
+			return err
+		}
+
+		if total >= rules.MaxPerDay {
+			return utils.ConflictError("the daily session limit was reached for this date")
+		}
+	}
+
+	if rules.MaxPerWeek > 0 {
+		// weeks run from monday to sunday
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+
+		total, err := service.Repo.CountBookings(ctx, adminID, monday.Format("2006-01-02"), monday.AddDate(0, 0, 6).Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+
+		if total >= rules.MaxPerWeek {
+			return utils.ConflictError("the weekly session limit was reached for this date")
+		}
+	}
+
+	return nil
+}
+
+// checkBookingNotice tells whether a session starting at startsAt respects
+// the minimum advance notice.
+func checkBookingNotice(rules dtos.BookingRulesOutput, startsAt time.Time) error {
+	if startsAt.Before(time.Now().Add(time.Duration(rules.MinNoticeHours) * time.Hour)) {
+		if rules.MinNoticeHours == 0 {
+			return utils.BadRequestError("this time has already passed")
+		}
+
+		return utils.BadRequestError(fmt.Sprintf("sessions must be booked at least %d hours in advance", rules.MinNoticeHours))
+	}
+
+	return nil
+}
\ No newline at end of file
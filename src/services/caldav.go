@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/ical"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// caldavHistory is how far back appointments are listed in the CalDAV calendar.
+const caldavHistory = 90 * 24 * time.Hour
+
+// CalDAVService backs the CalDAV calendar of each psychologist. Appointments
+// are listed read-only; everything else in the calendar is an availability
+// exception created from the calendar app.
+type CalDAVService struct {
+	Repo *repository.CalDAVRepository
+	FeedRepo *repository.CalendarFeedRepository
+	ExceptionRepo *repository.AvailabilityExceptionRepository
+}
+
+func (service *CalDAVService) GetAccount(ctx context.Context, adminID uuid.UUID) (dtos.CalDAVAccountOutput, error) {
+	email, enabled, err := service.Repo.GetAccount(ctx, adminID)
+	if err != nil {
+		return dtos.CalDAVAccountOutput{}, err
+	}
+
+	return caldavAccount(adminID, email, enabled), nil
+}
+
+// GeneratePassword issues the app password calendar apps log in with. The
+// account password is never used, so it does not end up stored on phones.
+func (service *CalDAVService) GeneratePassword(ctx context.Context, adminID uuid.UUID) (dtos.CalDAVAccountOutput, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		utils.LogError("generatePassword caldav service (error generating password)", err)
+		return dtos.CalDAVAccountOutput{}, utils.InternalServerError("error generating caldav password")
+	}
+
+	password := base64.RawURLEncoding.EncodeToString(buf)
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		utils.LogError("generatePassword caldav service (error hashing password)", err)
+		return dtos.CalDAVAccountOutput{}, utils.InternalServerError("error generating caldav password")
+	}
+
+	email, err := service.Repo.SetPassword(ctx, adminID, hashedPassword)
+	if err != nil {
+		return dtos.CalDAVAccountOutput{}, err
+	}
+
+	account := caldavAccount(adminID, email, true)
+	account.Password = password
+
+	return account, nil
+}
+
+func (service *CalDAVService) RevokePassword(ctx context.Context, adminID uuid.UUID) error {
+	return service.Repo.ClearPassword(ctx, adminID)
+}
+
+// Authenticate checks basic auth credentials. Calendar apps send them on
+// every request, so successful bcrypt checks are cached against the stored
+// hash: changing or revoking the password invalidates them at once.
+func (service *CalDAVService) Authenticate(ctx context.Context, username, password string) (uuid.UUID, error) {
+	adminID, passwordHash, err := service.Repo.FindAccountByEmail(ctx, username)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	sum := sha256.Sum256([]byte(passwordHash + "\x00" + password))
+	cacheKey := "caldav_auth_" + hex.EncodeToString(sum[:])
+
+	if _, found := utils.Cache.Get(cacheKey); found {
+		return adminID, nil
+	}
+
+	if err := utils.CheckPassword(passwordHash, password); err != nil {
+		return uuid.UUID{}, utils.UnauthorizedError("invalid credentials")
+	}
+
+	utils.Cache.Set(cacheKey, true, 5*time.Minute)
+
+	return adminID, nil
+}
+
+func (service *CalDAVService) ListObjects(ctx context.Context, adminID uuid.UUID) ([]dtos.CalendarObject, error) {
+	from := feedStart().Add(-caldavHistory)
+
+	appointments, err := service.FeedRepo.GetAppointments(ctx, adminID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := service.ExceptionRepo.GetExceptions(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]dtos.CalendarObject, 0, len(appointments)+len(exceptions))
+
+	for _, appt := range appointments {
+		objects = append(objects, appointmentObject(appt))
+	}
+
+	for _, exception := range exceptions {
+		objects = append(objects, exceptionObject(exception))
+	}
+
+	return objects, nil
+}
+
+func (service *CalDAVService) GetObject(ctx context.Context, adminID uuid.UUID, name string) (dtos.CalendarObject, error) {
+	if appointmentID, ok := appointmentObjectID(name); ok {
+		appt, err := service.FeedRepo.GetAppointment(ctx, adminID, appointmentID)
+		if err == nil {
+			return appointmentObject(appt), nil
+		}
+
+		if utils.GetStatusCode(err) != http.StatusNotFound {
+			return dtos.CalendarObject{}, err
+		}
+	}
+
+	exception, err := service.ExceptionRepo.GetExceptionByName(ctx, adminID, name)
+	if err != nil {
+		return dtos.CalendarObject{}, err
+	}
+
+	return exceptionObject(exception), nil
+}
+
+// PutObject stores an event created or edited in the calendar app. ifMatch
+// and ifNoneMatch carry the conditional headers ("*" or an ETag).
+func (service *CalDAVService) PutObject(ctx context.Context, adminID uuid.UUID, name string, data []byte, ifMatch, ifNoneMatch string) (dtos.CalendarObject, error) {
+	if err := service.checkNotAppointment(ctx, adminID, name); err != nil {
+		return dtos.CalendarObject{}, err
+	}
+
+	existing, err := service.ExceptionRepo.GetExceptionByName(ctx, adminID, name)
+	exists := err == nil
+	if err != nil && utils.GetStatusCode(err) != http.StatusNotFound {
+		return dtos.CalendarObject{}, err
+	}
+
+	if ifNoneMatch != "" && exists && (ifNoneMatch == "*" || ifNoneMatch == existing.ETag) {
+		return dtos.CalendarObject{}, utils.PreconditionFailedError("calendar object already exists")
+	}
+
+	if ifMatch != "" && (!exists || (ifMatch != "*" && ifMatch != existing.ETag)) {
+		return dtos.CalendarObject{}, utils.PreconditionFailedError("calendar object was changed")
+	}
+
+	exception, err := parseAvailabilityException(data)
+	if err != nil {
+		return dtos.CalendarObject{}, utils.BadRequestError(err.Error())
+	}
+
+	if strings.HasSuffix(exception.UID, "@clinify") {
+		return dtos.CalendarObject{}, utils.ForbiddenError("appointments can only be changed in Clinify")
+	}
+
+	sum := sha256.Sum256(data)
+
+	exception.Name = name
+	exception.ETag = hex.EncodeToString(sum[:16])
+
+	exception.UpdatedAt, err = service.ExceptionRepo.SaveException(ctx, adminID, exception)
+	if err != nil {
+		return dtos.CalendarObject{}, err
+	}
+
+	return exceptionObject(exception), nil
+}
+
+func (service *CalDAVService) DeleteObject(ctx context.Context, adminID uuid.UUID, name string) error {
+	if err := service.checkNotAppointment(ctx, adminID, name); err != nil {
+		return err
+	}
+
+	return service.ExceptionRepo.DeleteException(ctx, adminID, name)
+}
+
+func (service *CalDAVService) checkNotAppointment(ctx context.Context, adminID uuid.UUID, name string) error {
+	appointmentID, ok := appointmentObjectID(name)
+	if !ok {
+		return nil
+	}
+
+	_, err := service.FeedRepo.GetAppointment(ctx, adminID, appointmentID)
+	if err == nil {
+		return utils.ForbiddenError("appointments can only be changed in Clinify")
+	}
+
+	if utils.GetStatusCode(err) != http.StatusNotFound {
+		return err
+	}
+
+	return nil
+}
+
+func appointmentObjectID(name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(strings.TrimSuffix(name, ".ics"))
+	return id, err == nil
+}
+
+func appointmentObject(appt dtos.FeedAppointment) dtos.CalendarObject {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s", appt.ID, appt.Sequence, appt.UpdatedAt.UTC().Format(time.RFC3339Nano))))
+
+	return dtos.CalendarObject{
+		Name:    appt.ID.String() + ".ics",
+		ETag:    hex.EncodeToString(sum[:16]),
+		ModTime: appt.UpdatedAt,
+		Data:    ical.BuildObject(feedEvent(appt)),
+	}
+}
+
+func exceptionObject(exception dtos.AvailabilityException) dtos.CalendarObject {
+	return dtos.CalendarObject{
+		Name:    exception.Name,
+		ETag:    exception.ETag,
+		ModTime: exception.UpdatedAt,
+		Data:    []byte(exception.Data),
+	}
+}
+
+func caldavAccount(adminID uuid.UUID, email string, enabled bool) dtos.CalDAVAccountOutput {
+	baseURL := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+
+	return dtos.CalDAVAccountOutput{
+		Enabled:     enabled,
+		ServerURL:   baseURL + "/caldav/",
+		CalendarURL: fmt.Sprintf("%s/caldav/%s/calendars/clinify/", baseURL, adminID),
+		Username:    email,
+	}
+}
\ No newline at end of file
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/ical"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// feedRefresh is the polling interval suggested to calendar clients.
+const feedRefresh = 15 * time.Minute
+
+type CalendarFeedService struct {
+	Repo *repository.CalendarFeedRepository
+}
+
+func (service *CalendarFeedService) GetStatus(ctx context.Context, adminID uuid.UUID) (dtos.CalendarFeedOutput, error) {
+	return service.Repo.GetStatus(ctx, adminID)
+}
+
+// Regenerate issues a new feed token, which also invalidates the previous one.
+func (service *CalendarFeedService) Regenerate(ctx context.Context, adminID uuid.UUID) (dtos.CalendarFeedOutput, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		utils.LogError("regenerate calendar feed service (error generating token)", err)
+		return dtos.CalendarFeedOutput{}, utils.InternalServerError("error generating calendar feed")
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	createdAt, err := service.Repo.SetToken(ctx, adminID, hashFeedToken(token))
+	if err != nil {
+		return dtos.CalendarFeedOutput{}, err
+	}
+
+	baseURL := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+
+	return dtos.CalendarFeedOutput{
+		Enabled:   true,
+		URL:       fmt.Sprintf("%s/api/v1/calendar/%s.ics", baseURL, token),
+		CreatedAt: &createdAt,
+	}, nil
+}
+
+func (service *CalendarFeedService) Revoke(ctx context.Context, adminID uuid.UUID) error {
+	return service.Repo.ClearToken(ctx, adminID)
+}
+
+// GetVersion resolves the token and returns the validators of the current
+// feed. The feed changes at midnight too, as past sessions drop out, so
+// LastModified is never before the start of the day and the ETag is derived
+// from it.
+func (service *CalendarFeedService) GetVersion(ctx context.Context, token string) (dtos.CalendarFeedVersion, error) {
+	if token == "" {
+		return dtos.CalendarFeedVersion{}, utils.NotFoundError("calendar not found")
+	}
+
+	from := feedStart()
+
+	version, count, err := service.Repo.GetVersion(ctx, hashFeedToken(token), from)
+	if err != nil {
+		return dtos.CalendarFeedVersion{}, err
+	}
+
+	midnight := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, utils.AppLocation())
+	if midnight.After(version.LastModified) {
+		version.LastModified = midnight
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", version.ClientID, version.LastModified.UTC().Format(time.RFC3339Nano), count)))
+	version.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	return version, nil
+}
+
+func (service *CalendarFeedService) Render(ctx context.Context, version dtos.CalendarFeedVersion) ([]byte, error) {
+	appointments, err := service.Repo.GetAppointments(ctx, version.ClientID, feedStart())
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]ical.Event, 0, len(appointments))
+
+	for _, appt := range appointments {
+		events = append(events, feedEvent(appt))
+	}
+
+	return ical.BuildFeed("Clinify - "+version.ClientName, feedRefresh, events...), nil
+}
+
+// feedEvent only carries the patient initials and the bare video room link:
+// the feed ends up synced to third party calendar services, outside the
+// clinic's control, so join tokens stay out of it.
+func feedEvent(appt dtos.FeedAppointment) ical.Event {
+	loc := utils.AppLocation()
+
+	event := ical.Event{
+		UID:          ical.UID(appt.ID),
+		Sequence:     appt.Sequence,
+		Start:        time.Date(appt.Date.Year(), appt.Date.Month(), appt.Date.Day(), appt.StartTime.Hour(), appt.StartTime.Minute(), 0, 0, loc),
+		End:          time.Date(appt.Date.Year(), appt.Date.Month(), appt.Date.Day(), appt.EndTime.Hour(), appt.EndTime.Minute(), 0, 0, loc),
+		Summary:      "Atendimento " + initials(appt.PatientName),
+		Status:       ical.StatusConfirmed,
+		LastModified: appt.UpdatedAt,
+	}
+
+	if appt.Modality == dtos.ModalityOnline {
+		event.Location = "Online"
+		event.URL = withoutQuery(appt.VideoURL)
+	} else {
+		event.Location = appt.Address
+	}
+
+	return event
+}
+
+var nameParticles = map[string]bool{"da": true, "das": true, "de": true, "do": true, "dos": true, "e": true}
+
+// initials turns "Maria da Silva" into "M.S.".
+func initials(name string) string {
+	var parts []string
+
+	for _, word := range strings.Fields(name) {
+		if nameParticles[strings.ToLower(word)] {
+			continue
+		}
+
+		first, _ := utf8.DecodeRuneInString(word)
+
+		parts = append(parts, string(unicode.ToUpper(first)))
+	}
+
+	if len(parts) > 2 {
+		parts = []string{parts[0], parts[len(parts)-1]}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return strings.Join(parts, ".") + "."
+}
+
+func feedStart() time.Time {
+	now := time.Now().In(utils.AppLocation())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
\ No newline at end of file
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/certificate"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type CertificateService struct {
+	Repo *repository.CertificateRepository
+	AppointmentRepo *repository.AppointmentRepository
+	ReceiptRepo *repository.ReceiptRepository
+}
+
+// PDF renders the attendance certificate of a completed appointment, issuing
+// it on the first request. Either adminID or patientID must own the
+// appointment; pass uuid.Nil for the other.
+func (service *CertificateService) PDF(ctx context.Context, appointmentID, adminID, patientID uuid.UUID) ([]byte, string, error) {
+	record, err := service.issue(ctx, appointmentID, adminID, patientID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	loc := utils.AppLocation()
+
+	doc := certificate.Certificate{
+		Code: record.Code,
+		VerifyURL: certificateURL(record.Code),
+		IssuedAt: record.CreatedAt.In(loc),
+		IssuerName: record.IssuerName,
+		IssuerCRP: record.IssuerCRP,
+		PatientName: record.PatientName,
+		Date: record.Date,
+		StartTime: record.StartTime,
+		EndTime: record.EndTime,
+		Signature: record.Signature,
+	}
+
+	pdf, err := doc.PDF()
+	if err != nil {
+		utils.LogError("pdf certificate service (error rendering pdf)", err)
+		return nil, "", utils.InternalServerError("error creating certificate pdf")
+	}
+
+	return pdf, doc.Filename(), nil
+}
+
+// Verify returns the certificate a verification code belongs to, once its
+// signature checks out. Codes are matched ignoring case, spaces and dashes,
+// as people type them in by hand.
+func (service *CertificateService) Verify(ctx context.Context, code string) (dtos.Certificate, error) {
+	code, ok := normalizeCertificateCode(code)
+	if !ok {
+		return dtos.Certificate{}, utils.NotFoundError("certificate not found")
+	}
+
+	record, err := service.Repo.GetByCode(ctx, code)
+	if err != nil {
+		return dtos.Certificate{}, err
+	}
+
+	// certificates are signed on insert, so a blank signature was tampered with
+	if record.Signature == "" {
+		return dtos.Certificate{}, utils.ConflictError("the certificate does not match its signature")
+	}
+
+	signature, err := signCertificate(record)
+	if err != nil {
+		utils.LogError("verify certificate service (error signing certificate)", err)
+		return dtos.Certificate{}, utils.InternalServerError("error verifying certificate")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(record.Signature)) {
+		return dtos.Certificate{}, utils.ConflictError("the certificate does not match its signature")
+	}
+
+	return record, nil
+}
+
+func (service *CertificateService) issue(ctx context.Context, appointmentID, adminID, patientID uuid.UUID) (dtos.Certificate, error) {
+	details, err := service.AppointmentRepo.GetAppointmentDetails(ctx, repository.DB, appointmentID)
+	if err != nil {
+		return dtos.Certificate{}, err
+	}
+
+	if (adminID != uuid.Nil && details.ClientID != adminID) || (patientID != uuid.Nil && details.PatientID != patientID) {
+		return dtos.Certificate{}, utils.NotFoundError("appointment not found")
+	}
+
+	if details.Status != dtos.StatusCompleted {
+		return dtos.Certificate{}, utils.BadRequestError("certificates can only be issued for completed appointments")
+	}
+
+	record, err := service.Repo.GetByAppointment(ctx, appointmentID)
+	if utils.GetStatusCode(err) != http.StatusNotFound {
+		return record, err
+	}
+
+	issuer, err := service.ReceiptRepo.GetSettings(ctx, repository.DB, details.ClientID, false)
+	if err != nil {
+		return dtos.Certificate{}, err
+	}
+
+	if issuer.Crp == "" {
+		return dtos.Certificate{}, utils.BadRequestError("the psychologist's crp is required on certificates")
+	}
+
+	code, err := newCertificateCode()
+	if err != nil {
+		utils.LogError("issue certificate service (error generating code)", err)
+		return dtos.Certificate{}, utils.InternalServerError("error creating certificate")
+	}
+
+	record = dtos.Certificate{
+		ClientID: details.ClientID,
+		PatientID: details.PatientID,
+		AppointmentID: appointmentID,
+		Code: code,
+		IssuerName: issuer.FullName,
+		IssuerCRP: issuer.Crp,
+		PatientName: details.PatientName,
+		Date: details.Date,
+		StartTime: details.StartTime,
+		EndTime: details.EndTime,
+		CreatedAt: time.Now(),
+	}
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		record.Signature, err = signCertificate(record)
+		if err != nil {
+			utils.LogError("issue certificate service (error signing certificate)", err)
+			return utils.InternalServerError("error creating certificate")
+		}
+
+		return service.Repo.CreateCertificate(ctx, tx, record)
+	})
+	if err != nil {
+		return dtos.Certificate{}, err
+	}
+
+	// read back, a concurrent request may have issued it first
+	return service.Repo.GetByAppointment(ctx, appointmentID)
+}
+
+// signCertificate is the HMAC-SHA256, in hex, of everything the certificate
+// states, keyed with CERTIFICATE_SECRET (JWT_SECRET when unset).
+func signCertificate(record dtos.Certificate) (string, error) {
+	secret := os.Getenv("CERTIFICATE_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+
+	if secret == "" {
+		return "", fmt.Errorf("CERTIFICATE_SECRET is not set")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+
+	mac.Write([]byte(strings.Join([]string{
+		record.Code,
+		record.PatientName,
+		record.Date.Format("2006-01-02"),
+		record.StartTime.Format("15:04"),
+		record.EndTime.Format("15:04"),
+		record.IssuerName,
+		record.IssuerCRP,
+		record.CreatedAt.In(utils.AppLocation()).Format("2006-01-02"),
+	}, "\n")))
+
+	return strings.ToUpper(hex.EncodeToString(mac.Sum(nil))), nil
+}
+
+// newCertificateCode returns 80 random bits as "XXXX-XXXX-XXXX-XXXX".
+func newCertificateCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code, _ := normalizeCertificateCode(base32.StdEncoding.EncodeToString(buf))
+	return code, nil
+}
+
+func normalizeCertificateCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != 16 {
+		return "", false
+	}
+
+	if _, err := base32.StdEncoding.DecodeString(code); err != nil {
+		return "", false
+	}
+
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], true
+}
+
+func certificateURL(code string) string {
+	baseURL := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+
+	return fmt.Sprintf("%s/api/v1/certificates/%s", baseURL, code)
+}
\ No newline at end of file
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+)
+
+func TestSignCertificate(t *testing.T) {
+	t.Setenv("CERTIFICATE_SECRET", "test-secret")
+
+	record := dtos.Certificate{
+		Code: "ABCD-EFGH-IJKL-MNOP",
+		IssuerName: "Ana Souza",
+		IssuerCRP: "06/123456",
+		PatientName: "Bruno Lima",
+		Date: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		StartTime: time.Date(0, 1, 1, 14, 0, 0, 0, time.UTC),
+		EndTime: time.Date(0, 1, 1, 14, 50, 0, 0, time.UTC),
+		CreatedAt: time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC),
+	}
+
+	signature, err := signCertificate(record)
+	if err != nil {
+		t.Fatalf("signCertificate() error = %v", err)
+	}
+
+	if len(signature) != 64 {
+		t.Errorf("signature = %q, want 64 hex digits", signature)
+	}
+
+	again, _ := signCertificate(record)
+	if again != signature {
+		t.Errorf("signing twice gave %q and %q", signature, again)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*dtos.Certificate)
+	}{
+		{"patient", func(c *dtos.Certificate) { c.PatientName = "Bruno Lima Filho" }},
+		{"date", func(c *dtos.Certificate) { c.Date = c.Date.AddDate(0, 0, 1) }},
+		{"start", func(c *dtos.Certificate) { c.StartTime = c.StartTime.Add(-time.Hour) }},
+		{"end", func(c *dtos.Certificate) { c.EndTime = c.EndTime.Add(time.Hour) }},
+		{"issuer", func(c *dtos.Certificate) { c.IssuerName = "Outra Pessoa" }},
+		{"crp", func(c *dtos.Certificate) { c.IssuerCRP = "06/654321" }},
+		{"code", func(c *dtos.Certificate) { c.Code = "ABCD-EFGH-IJKL-MNOQ" }},
+		{"issue date", func(c *dtos.Certificate) { c.CreatedAt = c.CreatedAt.AddDate(0, 0, 2) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := record
+			tt.change(&changed)
+
+			got, err := signCertificate(changed)
+			if err != nil {
+				t.Fatalf("signCertificate() error = %v", err)
+			}
+
+			if got == signature {
+				t.Errorf("changing the %s kept the signature", tt.name)
+			}
+		})
+	}
+
+	t.Setenv("CERTIFICATE_SECRET", "another-secret")
+
+	if other, _ := signCertificate(record); other == signature {
+		t.Error("a different secret gave the same signature")
+	}
+}
+
+func TestSignCertificateWithoutSecret(t *testing.T) {
+	t.Setenv("CERTIFICATE_SECRET", "")
+	t.Setenv("JWT_SECRET", "")
+
+	if _, err := signCertificate(dtos.Certificate{}); err == nil {
+		t.Error("signCertificate() without a secret succeeded")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+var hexColorRegex = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+type EmailService struct {
+	Repo *repository.EmailTemplateRepository
+}
+
+// BuildMessage renders template name with the clinic's branding and template
+// override, if any.
+func (service *EmailService) BuildMessage(ctx context.Context, adminID uuid.UUID, name, to string, data any) (mailer.Message, error) {
+	branding, err := service.Repo.GetBranding(ctx, adminID)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	override, err := service.Repo.GetOverride(ctx, adminID, name)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	msg, err := mailer.Render(name, to, toMailerBranding(branding), data, mailer.TemplateOverride(override))
+	if err != nil {
+		utils.LogError("buildMessage email service (error rendering template)", err)
+		return mailer.Message{}, utils.InternalServerError("error rendering email")
+	}
+
+	return msg, nil
+}
+
+func (service *EmailService) GetBranding(ctx context.Context, adminID uuid.UUID) (dtos.EmailBrandingOutput, error) {
+	branding, err := service.Repo.GetBranding(ctx, adminID)
+	if err != nil {
+		return dtos.EmailBrandingOutput{}, err
+	}
+
+	return branding.EmailBrandingOutput, nil
+}
+
+func (service *EmailService) UpdateBranding(ctx context.Context, adminID uuid.UUID, input dtos.EmailBrandingInput) (dtos.EmailBrandingOutput, error) {
+	input.ClinicName = strings.TrimSpace(input.ClinicName)
+	input.PrimaryColor = strings.TrimSpace(input.PrimaryColor)
+	input.LogoURL = strings.TrimSpace(input.LogoURL)
+	input.Footer = strings.TrimSpace(input.Footer)
+
+	if input.PrimaryColor != "" && !hexColorRegex.MatchString(input.PrimaryColor) {
+		return dtos.EmailBrandingOutput{}, utils.BadRequestError("primary_color must be a hex color like #4f46e5")
+	}
+
+	if input.LogoURL != "" {
+		parsed, err := url.Parse(input.LogoURL)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return dtos.EmailBrandingOutput{}, utils.BadRequestError("logo_url must be an https url")
+		}
+	}
+
+	if err := service.Repo.UpdateBranding(ctx, adminID, input); err != nil {
+		return dtos.EmailBrandingOutput{}, err
+	}
+
+	return dtos.EmailBrandingOutput(input), nil
+}
+
+func (service *EmailService) GetTemplates(ctx context.Context, adminID uuid.UUID) ([]dtos.EmailTemplateOutput, error) {
+	overrides, err := service.Repo.GetOverrides(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]dtos.EmailTemplateOutput, 0)
+
+	for _, name := range mailer.TemplateNames() {
+		override, ok := overrides[name]
+
+		templates = append(templates, dtos.EmailTemplateOutput{
+			Name: name,
+			Overridden: ok,
+			Subject: override.Subject,
+			HTML: override.HTML,
+			Text: override.Text,
+		})
+	}
+
+	return templates, nil
+}
+
+func (service *EmailService) SaveTemplate(ctx context.Context, adminID uuid.UUID, name string, input dtos.EmailTemplateInput) error {
+	if strings.ContainsAny(input.Subject, "\r\n") {
+		return utils.BadRequestError("subject must be a single line")
+	}
+
+	if err := mailer.ValidateOverride(name, mailer.TemplateOverride(input)); err != nil {
+		return utils.BadRequestError(err.Error())
+	}
+
+	return service.Repo.UpsertOverride(ctx, adminID, name, input)
+}
+
+func (service *EmailService) DeleteTemplate(ctx context.Context, adminID uuid.UUID, name string) error {
+	return service.Repo.DeleteOverride(ctx, adminID, name)
+}
+
+func toMailerBranding(branding dtos.EmailBrandingDB) mailer.Branding {
+	result := mailer.Branding{
+		ClinicName: branding.ClinicName,
+		LogoURL: branding.LogoURL,
+		PrimaryColor: branding.PrimaryColor,
+		Footer: branding.Footer,
+	}
+
+	if result.ClinicName == "" {
+		result.ClinicName = branding.FullName
+	}
+
+	if result.LogoURL == "" {
+		result.LogoURL = branding.ProfileImageURL
+	}
+
+	return result
+}
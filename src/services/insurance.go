@@ -0,0 +1,298 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const maxAuthorizationSessions = 100
+
+type InsuranceService struct {
+	Repo *repository.InsuranceRepository
+	AppointmentRepo *repository.AppointmentRepository
+}
+
+func (service *InsuranceService) GetPlans(ctx context.Context, adminID uuid.UUID) ([]dtos.InsurancePlanOutput, error) {
+	return service.Repo.GetPlans(ctx, adminID)
+}
+
+func (service *InsuranceService) CreatePlan(ctx context.Context, adminID uuid.UUID, input dtos.InsurancePlanInput) (dtos.InsurancePlanOutput, error) {
+	if err := validatePlan(&input); err != nil {
+		return dtos.InsurancePlanOutput{}, err
+	}
+
+	return service.Repo.CreatePlan(ctx, adminID, input, input.Active == nil || *input.Active)
+}
+
+func (service *InsuranceService) UpdatePlan(ctx context.Context, id, adminID uuid.UUID, input dtos.InsurancePlanInput) (dtos.InsurancePlanOutput, error) {
+	if err := validatePlan(&input); err != nil {
+		return dtos.InsurancePlanOutput{}, err
+	}
+
+	return service.Repo.UpdatePlan(ctx, id, adminID, input, input.Active == nil || *input.Active)
+}
+
+func (service *InsuranceService) DeletePlan(ctx context.Context, id, adminID uuid.UUID) error {
+	return service.Repo.DeactivatePlan(ctx, id, adminID)
+}
+
+func (service *InsuranceService) CreateCoverage(ctx context.Context, patientID, adminID uuid.UUID, input dtos.CoverageInput) (dtos.CoverageOutput, error) {
+	planID, err := uuid.Parse(input.PlanID)
+	if err != nil {
+		return dtos.CoverageOutput{}, utils.BadRequestError("invalid plan id")
+	}
+
+	cardNumber := strings.TrimSpace(input.CardNumber)
+	if cardNumber == "" {
+		return dtos.CoverageOutput{}, utils.BadRequestError("card_number is required")
+	}
+
+	var validUntil sql.NullTime
+
+	if input.ValidUntil != "" {
+		date, err := time.Parse("2006-01-02", input.ValidUntil)
+		if err != nil {
+			return dtos.CoverageOutput{}, utils.BadRequestError("invalid format valid_until, use YYYY-MM-DD")
+		}
+
+		validUntil = sql.NullTime{Time: date, Valid: true}
+	}
+
+	id, err := service.Repo.CreateCoverage(ctx, patientID, adminID, planID, cardNumber, validUntil)
+	if err != nil {
+		return dtos.CoverageOutput{}, err
+	}
+
+	return service.Repo.GetCoverage(ctx, id, adminID)
+}
+
+func (service *InsuranceService) GetCoverages(ctx context.Context, patientID, adminID uuid.UUID) ([]dtos.CoverageOutput, error) {
+	return service.Repo.GetCoverages(ctx, patientID, adminID)
+}
+
+func (service *InsuranceService) CreateAuthorization(ctx context.Context, coverageID, adminID uuid.UUID, input dtos.AuthorizationInput) (dtos.AuthorizationOutput, error) {
+	input.Number = strings.TrimSpace(input.Number)
+	if input.Number == "" {
+		return dtos.AuthorizationOutput{}, utils.BadRequestError("number is required")
+	}
+
+	if input.Sessions < 1 || input.Sessions > maxAuthorizationSessions {
+		return dtos.AuthorizationOutput{}, utils.BadRequestError(fmt.Sprintf("sessions must be between 1 and %d", maxAuthorizationSessions))
+	}
+
+	validFrom, err := time.Parse("2006-01-02", input.ValidFrom)
+	if err != nil {
+		return dtos.AuthorizationOutput{}, utils.BadRequestError("invalid format valid_from, use YYYY-MM-DD")
+	}
+
+	validUntil, err := time.Parse("2006-01-02", input.ValidUntil)
+	if err != nil {
+		return dtos.AuthorizationOutput{}, utils.BadRequestError("invalid format valid_until, use YYYY-MM-DD")
+	}
+
+	if validUntil.Before(validFrom) {
+		return dtos.AuthorizationOutput{}, utils.BadRequestError("valid_until must not be before valid_from")
+	}
+
+	id, err := service.Repo.CreateAuthorization(ctx, coverageID, adminID, input, validFrom, validUntil)
+	if err != nil {
+		return dtos.AuthorizationOutput{}, err
+	}
+
+	authorization, err := service.Repo.GetAuthorization(ctx, id, adminID)
+	if err != nil {
+		return dtos.AuthorizationOutput{}, err
+	}
+
+	return authorization.AuthorizationOutput, nil
+}
+
+func (service *InsuranceService) GetAuthorizations(ctx context.Context, coverageID, adminID uuid.UUID) ([]dtos.AuthorizationOutput, error) {
+	if _, err := service.Repo.GetCoverage(ctx, coverageID, adminID); err != nil {
+		return nil, err
+	}
+
+	return service.Repo.GetAuthorizations(ctx, coverageID, adminID)
+}
+
+// SetAppointmentAuthorization puts the appointment under a guide of the same
+// patient. It is saved even when the appointment goes beyond the guide, as
+// plans often extend guides afterwards, but the response warns about it.
+func (service *InsuranceService) SetAppointmentAuthorization(ctx context.Context, appointmentID, adminID uuid.UUID, input dtos.AppointmentAuthorizationInput) (dtos.AppointmentAuthorizationOutput, error) {
+	output := dtos.AppointmentAuthorizationOutput{AppointmentID: appointmentID, Warnings: make([]string, 0)}
+
+	details, err := service.AppointmentRepo.GetAppointmentDetails(ctx, repository.DB, appointmentID)
+	if err != nil {
+		return output, err
+	}
+
+	if details.ClientID != adminID {
+		return output, utils.NotFoundError("appointment not found")
+	}
+
+	if input.AuthorizationID == nil {
+		return output, service.Repo.SetAppointmentAuthorization(ctx, appointmentID, adminID, uuid.NullUUID{})
+	}
+
+	authorizationID, err := uuid.Parse(*input.AuthorizationID)
+	if err != nil {
+		return output, utils.BadRequestError("invalid authorization id")
+	}
+
+	authorization, err := service.Repo.GetAuthorization(ctx, authorizationID, adminID)
+	if err != nil {
+		return output, err
+	}
+
+	if authorization.PatientID != details.PatientID {
+		return output, utils.BadRequestError("the authorization belongs to another patient")
+	}
+
+	if err := service.Repo.SetAppointmentAuthorization(ctx, appointmentID, adminID, uuid.NullUUID{UUID: authorizationID, Valid: true}); err != nil {
+		return output, err
+	}
+
+	// read again so the count includes this appointment
+	authorization, err = service.Repo.GetAuthorization(ctx, authorizationID, adminID)
+	if err != nil {
+		return output, err
+	}
+
+	output.Authorization = &authorization.AuthorizationOutput
+	output.Warnings = authorizationWarnings(authorization, details.Date.Format("2006-01-02"))
+
+	return output, nil
+}
+
+// CreateBatch bills the plan for the sessions completed in the month that no
+// earlier batch has billed, so sessions completed late go into a new batch
+// for the same month.
+func (service *InsuranceService) CreateBatch(ctx context.Context, planID, adminID uuid.UUID, input dtos.InsuranceBatchInput) (dtos.InsuranceBatchOutput, error) {
+	from, err := time.Parse("2006-01", input.Month)
+	if err != nil {
+		return dtos.InsuranceBatchOutput{}, utils.BadRequestError("invalid format month, use YYYY-MM")
+	}
+
+	var id uuid.UUID
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		plan, err := service.Repo.GetPlan(ctx, tx, planID, adminID, true)
+		if err != nil {
+			return err
+		}
+
+		items, err := service.Repo.GetUnbilledSessions(ctx, tx, planID, adminID, from, from.AddDate(0, 1, 0), plan.SessionPriceCents)
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return utils.BadRequestError("there are no completed sessions left to bill to this plan in this month")
+		}
+
+		batch := dtos.InsuranceBatchOutput{PlanID: plan.ID, PlanName: plan.Name, Items: items}
+
+		for _, item := range items {
+			batch.TotalCents += item.AmountCents
+		}
+
+		id, err = service.Repo.CreateBatch(ctx, tx, adminID, batch, from)
+		return err
+	})
+	if err != nil {
+		return dtos.InsuranceBatchOutput{}, err
+	}
+
+	return service.Repo.GetBatch(ctx, id, adminID)
+}
+
+func (service *InsuranceService) GetBatches(ctx context.Context, adminID, planID uuid.UUID) ([]dtos.InsuranceBatchOutput, error) {
+	return service.Repo.GetBatches(ctx, adminID, planID)
+}
+
+func (service *InsuranceService) GetBatch(ctx context.Context, id, adminID uuid.UUID) (dtos.InsuranceBatchOutput, error) {
+	return service.Repo.GetBatch(ctx, id, adminID)
+}
+
+// BatchCSV exports the batch for submission to the plan, one session per
+// line.
+func (service *InsuranceService) BatchCSV(ctx context.Context, id, adminID uuid.UUID) ([]byte, string, error) {
+	batch, err := service.Repo.GetBatch(ctx, id, adminID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+
+	w.Write([]string{"data", "inicio", "fim", "paciente", "carteirinha", "guia", "valor"})
+
+	for _, item := range batch.Items {
+		date, err := time.Parse("2006-01-02", item.Date)
+		if err != nil {
+			utils.LogError("batchCSV insurance service (error parsing date)", err)
+			return nil, "", utils.InternalServerError("error exporting billing batch")
+		}
+
+		w.Write([]string{
+			date.Format("02/01/2006"),
+			item.StartTime,
+			item.EndTime,
+			csvText(item.PatientName),
+			csvText(item.CardNumber),
+			csvText(item.AuthorizationNumber),
+			formatCSVAmount(item.AmountCents),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		utils.LogError("batchCSV insurance service (error writing csv)", err)
+		return nil, "", utils.InternalServerError("error exporting billing batch")
+	}
+
+	return buf.Bytes(), fmt.Sprintf("lote-%s-%s.csv", batch.Month, batch.ID.String()[:8]), nil
+}
+
+// authorizationWarnings explains how an appointment on date goes beyond the
+// guide, if it does.
+func authorizationWarnings(authorization dtos.Authorization, date string) []string {
+	warnings := make([]string, 0)
+
+	if authorization.Exceeded {
+		warnings = append(warnings, fmt.Sprintf("authorization %s allows %d sessions and now has %d", authorization.Number, authorization.Sessions, authorization.Used))
+	}
+
+	if date < authorization.ValidFrom || date > authorization.ValidUntil {
+		warnings = append(warnings, fmt.Sprintf("the appointment on %s is outside the validity of authorization %s (%s to %s)", date, authorization.Number, authorization.ValidFrom, authorization.ValidUntil))
+	}
+
+	if authorization.CardValidUntil != nil && date > *authorization.CardValidUntil {
+		warnings = append(warnings, fmt.Sprintf("the patient's insurance card expires on %s, before the appointment", *authorization.CardValidUntil))
+	}
+
+	return warnings
+}
+
+func validatePlan(input *dtos.InsurancePlanInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	input.ANSCode = strings.TrimSpace(input.ANSCode)
+
+	if input.Name == "" {
+		return utils.BadRequestError("name is required")
+	}
+
+	return validatePrice(*input.SessionPriceCents)
+}
\ No newline at end of file
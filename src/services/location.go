@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+	"github.com/patrickmn/go-cache"
+)
+
+type LocationService struct {
+	Repo *repository.LocationRepository
+}
+
+func (service *LocationService) CreateLocation(ctx context.Context, input dtos.LocationInput, adminID uuid.UUID) (uuid.UUID, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Address = strings.TrimSpace(input.Address)
+
+	if input.Name == "" || input.Address == "" {
+		return uuid.UUID{}, utils.BadRequestError("name and address are required")
+	}
+
+	id, err := service.Repo.CreateLocation(ctx, input, adminID)
+	if err != nil {
+		utils.LogError("createLocation service (error call to repository)", err)
+		return uuid.UUID{}, err
+	}
+
+	utils.Cache.Delete(fmt.Sprintf("locations_%s", adminID))
+
+	return id, nil
+}
+
+func (service *LocationService) GetLocations(ctx context.Context, adminID uuid.UUID) ([]dtos.LocationOutput, error) {
+	cacheKey := fmt.Sprintf("locations_%s", adminID)
+
+	if cached, found := utils.Cache.Get(cacheKey); found {
+		return cached.(*utils.LocationsCache).Data, nil
+	}
+
+	locations, err := service.Repo.GetLocations(ctx, adminID)
+	if err != nil {
+		utils.LogError("getLocations service (error call to repository)", err)
+		return nil, err
+	}
+
+	utils.Cache.Set(cacheKey, &utils.LocationsCache{
+		Data: locations,
+	}, cache.DefaultExpiration)
+
+	return locations, nil
+}
+
+func (service *LocationService) DeleteLocation(ctx context.Context, locationID, adminID uuid.UUID) error {
+	if locationID == uuid.Nil {
+		return utils.BadRequestError("invalid location id")
+	}
+
+	if err := service.Repo.DeleteLocation(ctx, locationID, adminID); err != nil {
+		return err
+	}
+
+	utils.Cache.Delete(fmt.Sprintf("locations_%s", adminID))
+
+	return nil
+}
+
+// resolveLocation validates the modality/location pair sent by the client and
+// makes sure the location belongs to the admin.
+func resolveLocation(ctx context.Context, repo *repository.LocationRepository, adminID uuid.UUID, locationIDStr, modality string) (uuid.NullUUID, string, error) {
+	if modality == "" {
+		modality = dtos.ModalityInPerson
+	}
+
+	if modality != dtos.ModalityInPerson && modality != dtos.ModalityOnline {
+		return uuid.NullUUID{}, "", utils.BadRequestError("modality must be in_person or online")
+	}
+
+	if strings.TrimSpace(locationIDStr) == "" {
+		return uuid.NullUUID{}, modality, nil
+	}
+
+	if modality == dtos.ModalityOnline {
+		return uuid.NullUUID{}, "", utils.BadRequestError("online sessions cannot have a location")
+	}
+
+	locationID, err := uuid.Parse(locationIDStr)
+	if err != nil {
+		return uuid.NullUUID{}, "", utils.BadRequestError("invalid location id")
+	}
+
+	if _, err := repo.GetLocationByID(ctx, locationID, adminID); err != nil {
+		return uuid.NullUUID{}, "", err
+	}
+
+	return uuid.NullUUID{UUID: locationID, Valid: true}, modality, nil
+}
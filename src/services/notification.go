@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/notify"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type NotificationService struct {
+	OutboxRepo *repository.OutboxRepository
+	Email      *EmailService
+}
+
+// NotifyPatient queues template on every channel the patient opted into, in
+// tx. Phone channels are skipped when no provider is configured or the patient
+// has no valid phone, so email keeps working on its own. Attachments only go
+// with the email.
+func (service *NotificationService) NotifyPatient(ctx context.Context, tx repository.DBTX, clientID uuid.UUID, template string, contact dtos.PatientContact, data mailer.AppointmentData, attachments ...mailer.Attachment) error {
+	channels := contact.Channels
+	if len(channels) == 0 {
+		channels = []string{notify.ChannelEmail}
+	}
+
+	clinicName := ""
+
+	for _, channel := range channels {
+		if channel == notify.ChannelEmail {
+			msg, err := service.Email.BuildMessage(ctx, clientID, template, contact.Email, data)
+			if err != nil {
+				return err
+			}
+
+			msg.Attachments = append(msg.Attachments, attachments...)
+
+			if err := service.OutboxRepo.Enqueue(ctx, tx, clientID, msg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !notify.Enabled(channel) {
+			continue
+		}
+
+		phone, err := notify.NormalizePhone(contact.Phone)
+		if err != nil {
+			utils.LogError("notifyPatient service (skipping "+channel+" notification)", err)
+			continue
+		}
+
+		if clinicName == "" {
+			branding, err := service.Email.Repo.GetBranding(ctx, clientID)
+			if err != nil {
+				return err
+			}
+
+			clinicName = toMailerBranding(branding).ClinicName
+		}
+
+		msg, ok := notify.AppointmentMessage(channel, template, phone, clinicName, data)
+		if !ok {
+			continue
+		}
+
+		if err := service.OutboxRepo.EnqueueNotification(ctx, tx, clientID, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
\ No newline at end of file
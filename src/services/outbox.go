@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+type OutboxService struct {
+	Repo *repository.OutboxRepository
+}
+
+func (service *OutboxService) GetMessages(ctx context.Context, adminID uuid.UUID, status string, page, limit int) ([]dtos.OutboxOutput, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	switch status {
+	case "", dtos.OutboxPending, dtos.OutboxSending, dtos.OutboxSent, dtos.OutboxDead:
+	default:
+		return nil, 0, utils.BadRequestError("status must be pending, sending, sent or dead")
+	}
+
+	return service.Repo.GetMessages(ctx, adminID, status, page, limit)
+}
+
+func (service *OutboxService) Resend(ctx context.Context, id, adminID uuid.UUID) error {
+	if id == uuid.Nil {
+		return utils.BadRequestError("invalid message id")
+	}
+
+	return service.Repo.Resend(ctx, id, adminID)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const (
+	maxPackageSessions     = 100
+	maxPackageValidityDays = 3650
+)
+
+type PackageService struct {
+	Repo *repository.PackageRepository
+	BillingRepo *repository.BillingRepository
+	PatientRepo *repository.PatientRepository
+}
+
+func (service *PackageService) GetPackages(ctx context.Context, adminID uuid.UUID) ([]dtos.PackageOutput, error) {
+	return service.Repo.GetPackages(ctx, adminID, uuid.Nil)
+}
+
+// GetPatientPackages lists the packages the patient's psychologist has on sale.
+func (service *PackageService) GetPatientPackages(ctx context.Context, patientID uuid.UUID) ([]dtos.PackageOutput, error) {
+	return service.Repo.GetPackages(ctx, uuid.Nil, patientID)
+}
+
+func (service *PackageService) CreatePackage(ctx context.Context, adminID uuid.UUID, input dtos.PackageInput) (dtos.PackageOutput, error) {
+	if err := validatePackage(&input); err != nil {
+		return dtos.PackageOutput{}, err
+	}
+
+	return service.Repo.CreatePackage(ctx, adminID, input, input.Active == nil || *input.Active)
+}
+
+func (service *PackageService) UpdatePackage(ctx context.Context, id, adminID uuid.UUID, input dtos.PackageInput) (dtos.PackageOutput, error) {
+	if err := validatePackage(&input); err != nil {
+		return dtos.PackageOutput{}, err
+	}
+
+	return service.Repo.UpdatePackage(ctx, id, adminID, input, input.Active == nil || *input.Active)
+}
+
+func (service *PackageService) DeletePackage(ctx context.Context, id, adminID uuid.UUID) error {
+	return service.Repo.DeactivatePackage(ctx, id, adminID)
+}
+
+// Purchase sells a package to the patient and charges its price on the
+// ledger. Pass uuid.Nil as adminID when the patient buys it themselves.
+func (service *PackageService) Purchase(ctx context.Context, packageID, patientID, adminID uuid.UUID) (dtos.PatientPackageOutput, error) {
+	today := time.Now().In(utils.AppLocation()).Format("2006-01-02")
+
+	var purchase dtos.PatientPackage
+
+	err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+		var err error
+
+		purchase, err = service.Repo.CreatePurchase(ctx, tx, packageID, patientID, adminID, today)
+		if err != nil {
+			return err
+		}
+
+		if purchase.PriceCents == 0 {
+			return nil
+		}
+
+		_, err = service.BillingRepo.CreateEntry(ctx, tx, purchase.ClientID, patientID, uuid.NullUUID{}, dtos.LedgerEntryInput{
+			Kind: dtos.EntryCharge,
+			AmountCents: purchase.PriceCents,
+			Description: fmt.Sprintf("Pacote %s (%d sessões)", purchase.Name, purchase.Sessions),
+		})
+
+		return err
+	})
+	if err != nil {
+		return dtos.PatientPackageOutput{}, err
+	}
+
+	return purchase.PatientPackageOutput, nil
+}
+
+// GetCredits returns the patient's packages and the credits left on them.
+// Pass uuid.Nil as adminID when the patient asks for their own credits.
+func (service *PackageService) GetCredits(ctx context.Context, patientID, adminID uuid.UUID) (dtos.CreditsOutput, error) {
+	if _, err := service.PatientRepo.GetContact(ctx, patientID, adminID); err != nil {
+		return dtos.CreditsOutput{}, err
+	}
+
+	today := time.Now().In(utils.AppLocation()).Format("2006-01-02")
+
+	packages, err := service.Repo.GetPurchases(ctx, patientID, today)
+	if err != nil {
+		return dtos.CreditsOutput{}, err
+	}
+
+	credits := dtos.CreditsOutput{PatientID: patientID, Packages: packages}
+
+	for _, pkg := range packages {
+		credits.Remaining += pkg.Remaining
+	}
+
+	return credits, nil
+}
+
+func validatePackage(input *dtos.PackageInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+
+	if input.Name == "" {
+		return utils.BadRequestError("name is required")
+	}
+
+	if input.Sessions < 1 || input.Sessions > maxPackageSessions {
+		return utils.BadRequestError(fmt.Sprintf("sessions must be between 1 and %d", maxPackageSessions))
+	}
+
+	if *input.PriceCents < 0 || *input.PriceCents > maxPriceCents {
+		return utils.BadRequestError("price_cents must be between 0 and 100000000")
+	}
+
+	if input.ValidityDays != nil && (*input.ValidityDays < 1 || *input.ValidityDays > maxPackageValidityDays) {
+		return utils.BadRequestError(fmt.Sprintf("validity_days must be between 1 and %d", maxPackageValidityDays))
+	}
+
+	return nil
+}
\ No newline at end of file
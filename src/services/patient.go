@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/notify"
 	"github.com/jhonnydsl/clinify-backend/src/repository"
 	"github.com/jhonnydsl/clinify-backend/src/utils"
 )
@@ -48,4 +49,89 @@ func (service *PatientService) CreatePatient(ctx context.Context, patient dtos.P
 	}
 
 	return id, nil
+}
+
+func (service *PatientService) GetAppointments(ctx context.Context, patientID uuid.UUID, page, limit int) ([]dtos.PatientAppointmentOutput, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	appointments, total, err := service.Repo.GetAppointments(ctx, patientID, page, limit)
+	if err != nil {
+		utils.LogError("getAppointments patient service (error call to repository)", err)
+		return nil, 0, err
+	}
+
+	for i := range appointments {
+		if appointments[i].Status == dtos.StatusCancelled {
+			appointments[i].VideoURL = ""
+		}
+	}
+
+	return appointments, total, nil
+}
+
+// GetNotificationPreferences returns the channels the patient chose along with
+// the ones the clinic can currently send. Pass uuid.Nil as clientID when the
+// patient asks for their own preferences.
+func (service *PatientService) GetNotificationPreferences(ctx context.Context, patientID, clientID uuid.UUID) (dtos.NotificationPreferencesOutput, error) {
+	contact, err := service.Repo.GetContact(ctx, patientID, clientID)
+	if err != nil {
+		return dtos.NotificationPreferencesOutput{}, err
+	}
+
+	return dtos.NotificationPreferencesOutput{Channels: contact.Channels, Available: availableChannels()}, nil
+}
+
+func (service *PatientService) UpdateNotificationPreferences(ctx context.Context, patientID, clientID uuid.UUID, input dtos.NotificationPreferencesInput) (dtos.NotificationPreferencesOutput, error) {
+	contact, err := service.Repo.GetContact(ctx, patientID, clientID)
+	if err != nil {
+		return dtos.NotificationPreferencesOutput{}, err
+	}
+
+	channels := make([]string, 0, len(input.Channels))
+	seen := make(map[string]bool)
+
+	for _, channel := range input.Channels {
+		if !notify.IsChannel(channel) {
+			return dtos.NotificationPreferencesOutput{}, utils.BadRequestError("channels must be email, sms or whatsapp")
+		}
+
+		if seen[channel] {
+			continue
+		}
+		seen[channel] = true
+
+		if channel != notify.ChannelEmail {
+			if _, err := notify.NormalizePhone(contact.Phone); err != nil {
+				return dtos.NotificationPreferencesOutput{}, utils.BadRequestError("a valid phone number is required for " + channel + " notifications")
+			}
+		}
+
+		channels = append(channels, channel)
+	}
+
+	if len(channels) == 0 {
+		return dtos.NotificationPreferencesOutput{}, utils.BadRequestError("at least one channel is required")
+	}
+
+	if err := service.Repo.UpdateNotificationChannels(ctx, patientID, clientID, channels); err != nil {
+		return dtos.NotificationPreferencesOutput{}, err
+	}
+
+	return dtos.NotificationPreferencesOutput{Channels: channels, Available: availableChannels()}, nil
+}
+
+func availableChannels() []string {
+	available := make([]string, 0, len(notify.Channels))
+	for _, channel := range notify.Channels {
+		if notify.Enabled(channel) {
+			available = append(available, channel)
+		}
+	}
+
+	return available
 }
\ No newline at end of file
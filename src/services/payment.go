@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/payment"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const (
+	boletoDefaultDueDays = 3
+	boletoMaxDueDays     = 60
+)
+
+var paymentMethodNames = map[string]string{
+	payment.MethodCard:   "Cartão",
+	payment.MethodBoleto: "Boleto",
+}
+
+type PaymentService struct {
+	Repo *repository.PaymentRepository
+	BillingRepo *repository.BillingRepository
+	PatientRepo *repository.PatientRepository
+	Gateway payment.Gateway
+}
+
+// CreatePayment charges the patient through the gateway. A declined card is
+// not an error: the payment is stored as failed with the gateway's reason.
+func (service *PaymentService) CreatePayment(ctx context.Context, patientID, adminID uuid.UUID, input dtos.PaymentInput) (dtos.PaymentOutput, error) {
+	if service.Gateway == nil {
+		return dtos.PaymentOutput{}, utils.BadRequestError("no payment gateway is configured")
+	}
+
+	if input.Method != payment.MethodCard && input.Method != payment.MethodBoleto {
+		return dtos.PaymentOutput{}, utils.BadRequestError("method must be card or boleto")
+	}
+
+	if input.Method == payment.MethodCard && input.CardToken == "" {
+		return dtos.PaymentOutput{}, utils.BadRequestError("card_token is required for card payments")
+	}
+
+	contact, err := service.PatientRepo.GetContact(ctx, patientID, adminID)
+	if err != nil {
+		return dtos.PaymentOutput{}, err
+	}
+
+	entryID, amount, description, err := resolveAmount(ctx, service.BillingRepo, patientID, input.EntryID, input.AmountCents, input.Description)
+	if err != nil {
+		return dtos.PaymentOutput{}, err
+	}
+
+	var dueDate sql.NullTime
+
+	if input.Method == payment.MethodBoleto {
+		due, err := boletoDueDate(input.DueDate)
+		if err != nil {
+			return dtos.PaymentOutput{}, err
+		}
+
+		dueDate = sql.NullTime{Time: due, Valid: true}
+	}
+
+	// boleto issuers register the payer's cpf, who is not always the patient
+	document := contact.PayerCPF
+	if document == "" {
+		document = contact.CPF
+	}
+
+	capture := input.Capture == nil || *input.Capture
+	id := uuid.New()
+
+	charge, err := service.Gateway.CreateCharge(ctx, payment.ChargeRequest{
+		Reference: id.String(),
+		Method: input.Method,
+		AmountCents: amount,
+		Description: description,
+		Customer: payment.Customer{Name: contact.Name, Email: contact.Email, Document: document},
+		CardToken: input.CardToken,
+		Capture: capture,
+		DueDate: dueDate.Time,
+	})
+	if err != nil {
+		utils.LogError("createPayment payment service (error creating gateway charge)", err)
+		return dtos.PaymentOutput{}, utils.BadRequestError("the payment gateway refused the charge")
+	}
+
+	record := dtos.Payment{
+		PaymentOutput: dtos.PaymentOutput{
+			ID: id,
+			PatientID: patientID,
+			Method: input.Method,
+			Status: payment.StatusPending,
+			AmountCents: amount,
+			Description: description,
+			BoletoURL: charge.BoletoURL,
+			BoletoLine: charge.BoletoLine,
+		},
+		ClientID: adminID,
+		Gateway: service.Gateway.Name(),
+		GatewayChargeID: charge.ID,
+	}
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		if err := service.Repo.CreatePayment(ctx, tx, record, entryID, dueDate); err != nil {
+			return err
+		}
+
+		return service.apply(ctx, tx, record, charge)
+	})
+	if err != nil {
+		// the charge exists at the gateway but not here; log it for reconciliation
+		utils.LogError("createPayment payment service (gateway charge "+charge.ID+" not stored)", err)
+		return dtos.PaymentOutput{}, err
+	}
+
+	return service.getPayment(ctx, id)
+}
+
+func (service *PaymentService) GetPayments(ctx context.Context, adminID uuid.UUID, status string) ([]dtos.PaymentOutput, error) {
+	switch status {
+	case "", payment.StatusPending, payment.StatusAuthorized, payment.StatusPaid, payment.StatusFailed, payment.StatusCancelled, payment.StatusRefunded:
+	default:
+		return nil, utils.BadRequestError("status must be pending, authorized, paid, failed, cancelled or refunded")
+	}
+
+	return service.Repo.GetPayments(ctx, adminID, uuid.Nil, status)
+}
+
+func (service *PaymentService) GetPatientPayments(ctx context.Context, patientID uuid.UUID) ([]dtos.PaymentOutput, error) {
+	return service.Repo.GetPayments(ctx, uuid.Nil, patientID, "")
+}
+
+// Capture collects an authorized card payment.
+func (service *PaymentService) Capture(ctx context.Context, id, adminID uuid.UUID, input dtos.PaymentAmountInput) (dtos.PaymentOutput, error) {
+	return service.update(ctx, id, adminID, func(record dtos.Payment) (payment.Charge, error) {
+		if record.Status != payment.StatusAuthorized {
+			return payment.Charge{}, utils.ConflictError("only authorized payments can be captured")
+		}
+
+		if input.AmountCents < 0 || input.AmountCents > record.AmountCents {
+			return payment.Charge{}, utils.BadRequestError("amount_cents must not exceed the authorized amount")
+		}
+
+		return service.Gateway.Capture(ctx, record.GatewayChargeID, input.AmountCents)
+	})
+}
+
+// Refund returns money of a paid payment, or releases an authorization.
+func (service *PaymentService) Refund(ctx context.Context, id, adminID uuid.UUID, input dtos.PaymentAmountInput) (dtos.PaymentOutput, error) {
+	return service.update(ctx, id, adminID, func(record dtos.Payment) (payment.Charge, error) {
+		if record.Status != payment.StatusPaid && record.Status != payment.StatusAuthorized {
+			return payment.Charge{}, utils.ConflictError("only paid or authorized payments can be refunded")
+		}
+
+		if input.AmountCents < 0 || input.AmountCents > record.CapturedCents-record.RefundedCents {
+			return payment.Charge{}, utils.BadRequestError("amount_cents must not exceed what is left to refund")
+		}
+
+		return service.Gateway.Refund(ctx, record.GatewayChargeID, input.AmountCents)
+	})
+}
+
+// Sync polls the gateway for the payment's current status.
+func (service *PaymentService) Sync(ctx context.Context, id, adminID uuid.UUID) (dtos.PaymentOutput, error) {
+	return service.update(ctx, id, adminID, func(record dtos.Payment) (payment.Charge, error) {
+		return service.Gateway.GetCharge(ctx, record.GatewayChargeID)
+	})
+}
+
+// SyncOpen polls the gateway for every payment still pending or authorized,
+// in case a webhook was lost.
+func (service *PaymentService) SyncOpen(ctx context.Context) error {
+	if service.Gateway == nil {
+		return nil
+	}
+
+	ids, err := service.Repo.GetOpenPayments(ctx, service.Gateway.Name())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		_, err := service.update(ctx, id, uuid.Nil, func(record dtos.Payment) (payment.Charge, error) {
+			return service.Gateway.GetCharge(ctx, record.GatewayChargeID)
+		})
+		if err != nil {
+			utils.LogError("syncOpen payment service (error syncing payment "+id.String()+")", err)
+		}
+	}
+
+	return nil
+}
+
+// ReceiveWebhook applies a signed gateway notification. Events are recorded
+// in the same transaction as their effects, so a retried event is applied
+// once. The charge is read back from the gateway instead of trusting the
+// event body, as events may arrive out of order.
+func (service *PaymentService) ReceiveWebhook(ctx context.Context, payload []byte, signature string) error {
+	if service.Gateway == nil {
+		return utils.NotFoundError("no payment gateway is configured")
+	}
+
+	event, err := service.Gateway.ParseWebhook(payload, signature)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		return utils.UnauthorizedError("invalid webhook signature")
+	}
+	if err != nil {
+		return utils.BadRequestError(err.Error())
+	}
+
+	return repository.WithTx(ctx, func(tx repository.DBTX) error {
+		fresh, err := service.Repo.RecordEvent(ctx, tx, service.Gateway.Name(), event.ID)
+		if err != nil || !fresh {
+			return err
+		}
+
+		record, err := service.Repo.GetPaymentByCharge(ctx, tx, service.Gateway.Name(), event.Charge.ID)
+		if utils.GetStatusCode(err) == http.StatusNotFound {
+			utils.LogError("receiveWebhook payment service (event "+event.ID+" for unknown charge "+event.Charge.ID+")", err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		charge, err := service.Gateway.GetCharge(ctx, record.GatewayChargeID)
+		if err != nil {
+			utils.LogError("receiveWebhook payment service (error reading gateway charge)", err)
+			return utils.InternalServerError("error reading payment from the gateway")
+		}
+
+		return service.apply(ctx, tx, record, charge)
+	})
+}
+
+// update locks the payment, runs call against the gateway and applies the
+// charge it returns. A nil adminID skips the ownership check.
+func (service *PaymentService) update(ctx context.Context, id, adminID uuid.UUID, call func(dtos.Payment) (payment.Charge, error)) (dtos.PaymentOutput, error) {
+	if service.Gateway == nil {
+		return dtos.PaymentOutput{}, utils.BadRequestError("no payment gateway is configured")
+	}
+
+	err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+		record, err := service.Repo.GetPayment(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if adminID != uuid.Nil && record.ClientID != adminID {
+			return utils.NotFoundError("payment not found")
+		}
+
+		if record.Gateway != service.Gateway.Name() {
+			return utils.ConflictError("this payment belongs to another payment gateway")
+		}
+
+		charge, err := call(record)
+		if err != nil {
+			var apiErr *dtos.APIError
+			if errors.As(err, &apiErr) {
+				return err
+			}
+
+			utils.LogError("update payment service (gateway error)", err)
+			return utils.BadRequestError("the payment gateway refused the operation")
+		}
+
+		return service.apply(ctx, tx, record, charge)
+	})
+	if err != nil {
+		return dtos.PaymentOutput{}, err
+	}
+
+	return service.getPayment(ctx, id)
+}
+
+// apply brings the payment in line with the gateway's charge, booking on the
+// ledger whatever was captured or refunded since the last update. Gateway
+// totals only grow, so stale reads never undo ledger entries.
+func (service *PaymentService) apply(ctx context.Context, tx repository.DBTX, record dtos.Payment, charge payment.Charge) error {
+	name := paymentMethodNames[record.Method]
+	captured := max(record.CapturedCents, charge.CapturedCents)
+	refunded := max(record.RefundedCents, charge.RefundedCents)
+
+	if delta := captured - record.CapturedCents; delta > 0 {
+		if err := service.BillingRepo.CreatePaymentEntry(ctx, tx, record, dtos.EntryPayment, delta, name+" "+record.Description); err != nil {
+			return err
+		}
+	}
+
+	if delta := refunded - record.RefundedCents; delta > 0 {
+		if err := service.BillingRepo.CreatePaymentEntry(ctx, tx, record, dtos.EntryRefund, delta, "Estorno "+name+" "+record.Description); err != nil {
+			return err
+		}
+	}
+
+	return service.Repo.UpdateFromGateway(ctx, tx, record.ID, charge.Status, captured, refunded, charge.FailureReason)
+}
+
+func (service *PaymentService) getPayment(ctx context.Context, id uuid.UUID) (dtos.PaymentOutput, error) {
+	record, err := service.Repo.GetPayment(ctx, repository.DB, id)
+	if err != nil {
+		return dtos.PaymentOutput{}, err
+	}
+
+	return record.PaymentOutput, nil
+}
+
+func boletoDueDate(date string) (time.Time, error) {
+	loc := utils.AppLocation()
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	if date == "" {
+		return today.AddDate(0, 0, boletoDefaultDueDays), nil
+	}
+
+	due, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return time.Time{}, utils.BadRequestError("invalid format due_date")
+	}
+
+	if due.Before(today) || due.After(today.AddDate(0, 0, boletoMaxDueDays)) {
+		return time.Time{}, utils.BadRequestError("due_date must be between today and 60 days from now")
+	}
+
+	return due, nil
+}
\ No newline at end of file
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/pix"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const pixQRCodeSize = 320
+
+type PixService struct {
+	Repo *repository.PixRepository
+	BillingRepo *repository.BillingRepository
+	PatientRepo *repository.PatientRepository
+	Notifications *NotificationService
+	Provider pix.Provider
+}
+
+func (service *PixService) GetSettings(ctx context.Context, adminID uuid.UUID) (dtos.PixSettingsOutput, error) {
+	settings, err := service.Repo.GetSettings(ctx, adminID)
+	if err != nil {
+		return dtos.PixSettingsOutput{}, err
+	}
+
+	if settings.Key != "" {
+		_, settings.KeyType, _ = pix.NormalizeKey(settings.Key)
+	}
+
+	return settings, nil
+}
+
+func (service *PixService) UpdateSettings(ctx context.Context, input dtos.PixSettingsInput, adminID uuid.UUID) (dtos.PixSettingsOutput, error) {
+	key, keyType, err := pix.NormalizeKey(input.Key)
+	if err != nil {
+		return dtos.PixSettingsOutput{}, utils.BadRequestError("key must be a valid cpf, cnpj, e-mail, phone (+55...) or random pix key")
+	}
+
+	settings := dtos.PixSettingsOutput{
+		Key: key,
+		KeyType: keyType,
+		MerchantName: strings.TrimSpace(input.MerchantName),
+		MerchantCity: strings.TrimSpace(input.MerchantCity),
+	}
+
+	if len(settings.MerchantName) > 25 || len(settings.MerchantCity) > 15 {
+		return dtos.PixSettingsOutput{}, utils.BadRequestError("merchant_name must be at most 25 characters and merchant_city at most 15")
+	}
+
+	if err := service.Repo.UpdateSettings(ctx, adminID, settings); err != nil {
+		return dtos.PixSettingsOutput{}, err
+	}
+
+	return settings, nil
+}
+
+// CreateCharge generates a BR Code for the patient to pay. Codes are dynamic
+// when a provider is configured and static otherwise.
+func (service *PixService) CreateCharge(ctx context.Context, patientID, adminID uuid.UUID, input dtos.PixChargeInput) (dtos.PixChargeOutput, error) {
+	settings, err := service.Repo.GetSettings(ctx, adminID)
+	if err != nil {
+		return dtos.PixChargeOutput{}, err
+	}
+
+	if settings.Key == "" {
+		return dtos.PixChargeOutput{}, utils.BadRequestError("set up a pix key before charging patients")
+	}
+
+	contact, err := service.PatientRepo.GetContact(ctx, patientID, adminID)
+	if err != nil {
+		return dtos.PixChargeOutput{}, err
+	}
+
+	entryID, amount, description, err := resolveAmount(ctx, service.BillingRepo, patientID, input.EntryID, input.AmountCents, input.Description)
+	if err != nil {
+		return dtos.PixChargeOutput{}, err
+	}
+
+	payload := pix.Payload{
+		Key: settings.Key,
+		Description: description,
+		MerchantName: settings.MerchantName,
+		MerchantCity: settings.MerchantCity,
+		AmountCents: amount,
+	}
+
+	// dynamic txids take 26 to 35 characters, static ones at most 25
+	txid := strings.ReplaceAll(uuid.New().String(), "-", "")
+
+	if service.Provider != nil {
+		location, err := service.Provider.CreateCharge(ctx, pix.Charge{TxID: txid, Key: settings.Key, AmountCents: amount, Description: description})
+		if err != nil {
+			utils.LogError("createCharge pix service (error creating charge with the provider)", err)
+			return dtos.PixChargeOutput{}, utils.InternalServerError("error creating pix charge")
+		}
+
+		payload.Key = ""
+		payload.Location = location
+	} else {
+		txid = txid[:25]
+	}
+
+	payload.TxID = txid
+
+	brCode, err := payload.Encode()
+	if err != nil {
+		utils.LogError("createCharge pix service (error encoding br code)", err)
+		return dtos.PixChargeOutput{}, utils.BadRequestError("could not build a pix code from the pix settings")
+	}
+
+	var id uuid.UUID
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		id, err = service.Repo.CreateCharge(ctx, tx, adminID, patientID, entryID, txid, amount, description, brCode)
+		if err != nil {
+			return err
+		}
+
+		if !input.SendEmail {
+			return nil
+		}
+
+		return service.emailCharge(ctx, tx, adminID, contact, brCode, amount, description)
+	})
+	if err != nil {
+		return dtos.PixChargeOutput{}, err
+	}
+
+	charge, err := service.Repo.GetCharge(ctx, repository.DB, id)
+	if err != nil {
+		return dtos.PixChargeOutput{}, err
+	}
+
+	return charge.PixChargeOutput, nil
+}
+
+func (service *PixService) emailCharge(ctx context.Context, tx repository.DBTX, adminID uuid.UUID, contact dtos.PatientContact, brCode string, amount int64, description string) error {
+	png, err := pix.QRCodePNG(brCode, pixQRCodeSize)
+	if err != nil {
+		utils.LogError("emailCharge pix service (error rendering qr code)", err)
+		return utils.InternalServerError("error creating pix qr code")
+	}
+
+	data := mailer.PaymentData{
+		PatientName: contact.Name,
+		Amount: utils.FormatBRL(amount),
+		Description: description,
+		PixCode: brCode,
+		QRCodeCID: "pix-qrcode",
+	}
+
+	msg, err := service.Notifications.Email.BuildMessage(ctx, adminID, mailer.TemplatePixPayment, contact.Email, data)
+	if err != nil {
+		return err
+	}
+
+	msg.Attachments = append(msg.Attachments, mailer.Attachment{
+		Filename: "pix.png",
+		ContentType: "image/png",
+		ContentID: data.QRCodeCID,
+		Data: png,
+	})
+
+	return service.Notifications.OutboxRepo.Enqueue(ctx, tx, adminID, msg)
+}
+
+func (service *PixService) GetCharges(ctx context.Context, adminID uuid.UUID, status string) ([]dtos.PixChargeOutput, error) {
+	switch status {
+	case "", dtos.PixPending, dtos.PixPaid, dtos.PixCancelled:
+	default:
+		return nil, utils.BadRequestError("status must be pending, paid or cancelled")
+	}
+
+	return service.Repo.GetCharges(ctx, adminID, uuid.Nil, status)
+}
+
+func (service *PixService) GetPatientCharges(ctx context.Context, patientID uuid.UUID) ([]dtos.PixChargeOutput, error) {
+	return service.Repo.GetCharges(ctx, uuid.Nil, patientID, "")
+}
+
+// QRCode renders the charge as a PNG. Either adminID or patientID must own
+// the charge; pass uuid.Nil for the other.
+func (service *PixService) QRCode(ctx context.Context, id, adminID, patientID uuid.UUID) ([]byte, error) {
+	charge, err := service.Repo.GetCharge(ctx, repository.DB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if (adminID != uuid.Nil && charge.ClientID != adminID) || (patientID != uuid.Nil && charge.PatientID != patientID) {
+		return nil, utils.NotFoundError("pix charge not found")
+	}
+
+	png, err := pix.QRCodePNG(charge.BRCode, pixQRCodeSize)
+	if err != nil {
+		utils.LogError("qrCode pix service (error rendering qr code)", err)
+		return nil, utils.InternalServerError("error creating pix qr code")
+	}
+
+	return png, nil
+}
+
+// MarkPaid records a payment the psychologist confirmed in their bank app.
+func (service *PixService) MarkPaid(ctx context.Context, id, adminID uuid.UUID) error {
+	return repository.WithTx(ctx, func(tx repository.DBTX) error {
+		charge, err := service.Repo.GetCharge(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if charge.ClientID != adminID {
+			return utils.NotFoundError("pix charge not found")
+		}
+
+		if charge.Status == dtos.PixPaid {
+			return utils.ConflictError("this pix charge was already paid")
+		}
+
+		return service.settle(ctx, tx, charge, pix.Payment{TxID: charge.TxID, AmountCents: charge.AmountCents, PaidAt: time.Now()})
+	})
+}
+
+func (service *PixService) CancelCharge(ctx context.Context, id, adminID uuid.UUID) error {
+	return service.Repo.CancelCharge(ctx, id, adminID)
+}
+
+// ReceiveWebhook settles the charges paid in a provider notification.
+// Providers retry notifications, so payments already recorded are skipped.
+func (service *PixService) ReceiveWebhook(ctx context.Context, r *http.Request) error {
+	if service.Provider == nil {
+		return utils.NotFoundError("pix provider not configured")
+	}
+
+	payments, err := service.Provider.ParseWebhook(r)
+	if errors.Is(err, pix.ErrUnauthorized) {
+		return utils.UnauthorizedError("invalid webhook credentials")
+	}
+	if err != nil {
+		return utils.BadRequestError(err.Error())
+	}
+
+	for _, payment := range payments {
+		err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+			charge, err := service.Repo.GetChargeByTxID(ctx, tx, payment.TxID)
+			if err != nil {
+				return err
+			}
+
+			if charge.Status == dtos.PixPaid {
+				return nil
+			}
+
+			return service.settle(ctx, tx, charge, payment)
+		})
+		if utils.GetStatusCode(err) == http.StatusNotFound {
+			// not one of ours, e.g. a transfer made straight to the key
+			utils.LogError("receiveWebhook pix service (payment for unknown txid "+payment.TxID+")", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settle books the payment on the patient's ledger and marks the charge as
+// paid. The amount actually received is booked, even when it differs from
+// the charge.
+func (service *PixService) settle(ctx context.Context, tx repository.DBTX, charge dtos.PixCharge, payment pix.Payment) error {
+	entry := dtos.LedgerEntryInput{
+		Kind: dtos.EntryPayment,
+		AmountCents: payment.AmountCents,
+		Description: "Pix " + charge.TxID,
+		Method: "pix",
+	}
+
+	entryID, err := service.BillingRepo.CreateEntry(ctx, tx, charge.ClientID, charge.PatientID, uuid.NullUUID{}, entry)
+	if err != nil {
+		return err
+	}
+
+	return service.Repo.MarkPaid(ctx, tx, charge.ID, payment.EndToEndID, entryID, payment.PaidAt)
+}
\ No newline at end of file
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/receipt"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+var monthNames = [...]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"}
+
+type ReceiptService struct {
+	Repo *repository.ReceiptRepository
+	BillingRepo *repository.BillingRepository
+	PatientRepo *repository.PatientRepository
+	Notifications *NotificationService
+}
+
+func (service *ReceiptService) GetSettings(ctx context.Context, adminID uuid.UUID) (dtos.ReceiptSettingsOutput, error) {
+	return service.Repo.GetSettings(ctx, repository.DB, adminID, false)
+}
+
+func (service *ReceiptService) UpdateSettings(ctx context.Context, input dtos.CPFInput, adminID uuid.UUID) (dtos.ReceiptSettingsOutput, error) {
+	cpf, ok := utils.NormalizeCPF(input.CPF)
+	if !ok {
+		return dtos.ReceiptSettingsOutput{}, utils.BadRequestError("invalid cpf")
+	}
+
+	if err := service.Repo.UpdateCPF(ctx, adminID, cpf); err != nil {
+		return dtos.ReceiptSettingsOutput{}, err
+	}
+
+	return service.Repo.GetSettings(ctx, repository.DB, adminID, false)
+}
+
+// UpdatePatientCPF sets the cpf printed on the patient's receipts. Pass
+// uuid.Nil as adminID when the patient sets their own.
+func (service *ReceiptService) UpdatePatientCPF(ctx context.Context, patientID, adminID uuid.UUID, input dtos.CPFInput) error {
+	cpf, ok := utils.NormalizeCPF(input.CPF)
+	if !ok {
+		return utils.BadRequestError("invalid cpf")
+	}
+
+	return service.Repo.UpdatePatientCPF(ctx, patientID, adminID, cpf)
+}
+
+// Issue creates the receipt for a payment or a month. Asking again for the
+// same payment or month returns the receipt already issued. A payment goes on
+// one receipt only, its own or its month's.
+func (service *ReceiptService) Issue(ctx context.Context, patientID, adminID uuid.UUID, input dtos.ReceiptInput) (dtos.ReceiptOutput, error) {
+	if (input.EntryID == "") == (input.Month == "") {
+		return dtos.ReceiptOutput{}, utils.BadRequestError("either entry_id or month is required")
+	}
+
+	loc := utils.AppLocation()
+
+	var (
+		entryID uuid.NullUUID
+		period  sql.NullTime
+		entry   dtos.LedgerEntryOutput
+	)
+
+	if input.EntryID != "" {
+		id, err := uuid.Parse(input.EntryID)
+		if err != nil {
+			return dtos.ReceiptOutput{}, utils.BadRequestError("invalid entry id")
+		}
+
+		entry, err = service.BillingRepo.GetEntry(ctx, id, patientID)
+		if err != nil {
+			return dtos.ReceiptOutput{}, err
+		}
+
+		if entry.Kind != dtos.EntryPayment {
+			return dtos.ReceiptOutput{}, utils.BadRequestError("receipts can only be issued for payments")
+		}
+
+		entryID = uuid.NullUUID{UUID: id, Valid: true}
+	} else {
+		month, err := time.ParseInLocation("2006-01", input.Month, loc)
+		if err != nil {
+			return dtos.ReceiptOutput{}, utils.BadRequestError("invalid format month, use YYYY-MM")
+		}
+
+		// a receipt is final, so the month must be closed before it is issued
+		if month.AddDate(0, 1, 0).After(time.Now()) {
+			return dtos.ReceiptOutput{}, utils.BadRequestError("month must have ended")
+		}
+
+		period = sql.NullTime{Time: month, Valid: true}
+	}
+
+	var id uuid.UUID
+
+	err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+		issuer, err := service.Repo.GetSettings(ctx, tx, adminID, true)
+		if err != nil {
+			return err
+		}
+
+		if issuer.CPF == "" || issuer.Crp == "" {
+			return utils.BadRequestError("set your cpf and crp before issuing receipts")
+		}
+
+		name, cpf, err := service.Repo.GetPayer(ctx, tx, patientID, adminID)
+		if err != nil {
+			return err
+		}
+
+		if cpf == "" {
+			return utils.BadRequestError("the patient's cpf is required on receipts")
+		}
+
+		id, err = service.Repo.FindReceipt(ctx, tx, patientID, entryID, period)
+		if err != nil || id != uuid.Nil {
+			return err
+		}
+
+		record := dtos.Receipt{
+			ReceiptOutput: dtos.ReceiptOutput{PatientID: patientID, PatientName: name},
+			ClientID: adminID,
+			Issuer: issuer,
+			PatientCPF: cpf,
+		}
+
+		if entryID.Valid {
+			paidAt := entry.CreatedAt.In(loc)
+			month := time.Date(paidAt.Year(), paidAt.Month(), 1, 0, 0, 0, 0, loc)
+
+			// monthly receipts take every payment of the month without one
+			monthly, err := service.Repo.FindReceipt(ctx, tx, patientID, uuid.NullUUID{}, sql.NullTime{Time: month, Valid: true})
+			if err != nil {
+				return err
+			}
+
+			if monthly != uuid.Nil {
+				return utils.ConflictError("this payment is already on the receipt for " + month.Format("01/2006"))
+			}
+
+			description := entry.Description
+			if description == "" {
+				description = "Pagamento"
+			}
+
+			record.Reference = "atendimento psicológico, pagamento de " + paidAt.Format("02/01/2006")
+			record.Items = []dtos.ReceiptItem{{Date: paidAt.Format("2006-01-02"), Description: description, AmountCents: entry.AmountCents}}
+		} else {
+			record.Items, err = service.Repo.GetMonthItems(ctx, tx, patientID, adminID, period.Time, period.Time.AddDate(0, 1, 0))
+			if err != nil {
+				return err
+			}
+
+			record.Reference = fmt.Sprintf("atendimento psicológico em %s de %d", monthNames[period.Time.Month()-1], period.Time.Year())
+		}
+
+		for _, item := range record.Items {
+			record.TotalCents += item.AmountCents
+		}
+
+		if record.TotalCents <= 0 {
+			return utils.BadRequestError("the patient paid nothing in this month that is not on another receipt")
+		}
+
+		id, err = service.Repo.CreateReceipt(ctx, tx, record, entryID, period)
+		return err
+	})
+	if err != nil {
+		return dtos.ReceiptOutput{}, err
+	}
+
+	issued, err := service.Repo.GetReceipt(ctx, id)
+	if err != nil {
+		return dtos.ReceiptOutput{}, err
+	}
+
+	return issued.ReceiptOutput, nil
+}
+
+func (service *ReceiptService) GetReceipts(ctx context.Context, adminID, patientID uuid.UUID) ([]dtos.ReceiptOutput, error) {
+	return service.Repo.GetReceipts(ctx, adminID, patientID)
+}
+
+func (service *ReceiptService) GetPatientReceipts(ctx context.Context, patientID uuid.UUID) ([]dtos.ReceiptOutput, error) {
+	return service.Repo.GetReceipts(ctx, uuid.Nil, patientID)
+}
+
+// PDF renders the receipt and returns it with its file name. Either adminID
+// or patientID must own the receipt; pass uuid.Nil for the other.
+func (service *ReceiptService) PDF(ctx context.Context, id, adminID, patientID uuid.UUID) ([]byte, string, error) {
+	record, err := service.getReceipt(ctx, id, adminID, patientID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	doc := receiptDocument(record)
+
+	pdf, err := doc.PDF()
+	if err != nil {
+		utils.LogError("pdf receipt service (error rendering pdf)", err)
+		return nil, "", utils.InternalServerError("error creating receipt pdf")
+	}
+
+	return pdf, doc.Filename(), nil
+}
+
+// Email sends the receipt to the patient as a PDF attachment.
+func (service *ReceiptService) Email(ctx context.Context, id, adminID uuid.UUID) error {
+	record, err := service.getReceipt(ctx, id, adminID, uuid.Nil)
+	if err != nil {
+		return err
+	}
+
+	contact, err := service.PatientRepo.GetContact(ctx, record.PatientID, adminID)
+	if err != nil {
+		return err
+	}
+
+	doc := receiptDocument(record)
+
+	pdf, err := doc.PDF()
+	if err != nil {
+		utils.LogError("email receipt service (error rendering pdf)", err)
+		return utils.InternalServerError("error creating receipt pdf")
+	}
+
+	data := mailer.ReceiptData{
+		PatientName: contact.Name,
+		Number: fmt.Sprintf("%04d", record.Number),
+		Amount: utils.FormatBRL(record.TotalCents),
+		Reference: record.Reference,
+	}
+
+	msg, err := service.Notifications.Email.BuildMessage(ctx, adminID, mailer.TemplateReceipt, contact.Email, data)
+	if err != nil {
+		return err
+	}
+
+	msg.Attachments = append(msg.Attachments, mailer.Attachment{
+		Filename: doc.Filename(),
+		ContentType: "application/pdf",
+		Data: pdf,
+	})
+
+	return service.Notifications.OutboxRepo.Enqueue(ctx, repository.DB, adminID, msg)
+}
+
+func (service *ReceiptService) getReceipt(ctx context.Context, id, adminID, patientID uuid.UUID) (dtos.Receipt, error) {
+	record, err := service.Repo.GetReceipt(ctx, id)
+	if err != nil {
+		return dtos.Receipt{}, err
+	}
+
+	if (adminID != uuid.Nil && record.ClientID != adminID) || (patientID != uuid.Nil && record.PatientID != patientID) {
+		return dtos.Receipt{}, utils.NotFoundError("receipt not found")
+	}
+
+	return record, nil
+}
+
+func receiptDocument(record dtos.Receipt) receipt.Receipt {
+	loc := utils.AppLocation()
+
+	doc := receipt.Receipt{
+		Number: record.Number,
+		IssuedAt: record.CreatedAt.In(loc),
+		Issuer: receipt.Issuer{
+			Name: record.Issuer.FullName,
+			CRP: record.Issuer.Crp,
+			CPF: record.Issuer.CPF,
+			Address: record.Issuer.OfficeAddress,
+		},
+		Payer: receipt.Payer{Name: record.PatientName, CPF: record.PatientCPF},
+		Reference: record.Reference,
+		TotalCents: record.TotalCents,
+	}
+
+	for _, item := range record.Items {
+		date, _ := time.ParseInLocation("2006-01-02", item.Date, loc)
+		doc.Items = append(doc.Items, receipt.Item{Date: date, Description: item.Description, AmountCents: item.AmountCents})
+	}
+
+	return doc
+}
\ No newline at end of file
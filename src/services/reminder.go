@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const maxReminderOffsetMinutes = 7 * 24 * 60
+
+type ReminderService struct {
+	Repo *repository.ReminderRepository
+}
+
+func (service *ReminderService) GetSettings(ctx context.Context, adminID uuid.UUID) (dtos.ReminderSettingsOutput, error) {
+	settings, err := service.Repo.GetSettings(ctx, adminID)
+	if err != nil {
+		utils.LogError("getSettings reminder service (error call to repository)", err)
+		return dtos.ReminderSettingsOutput{}, err
+	}
+
+	return settings, nil
+}
+
+func (service *ReminderService) UpdateSettings(ctx context.Context, input dtos.ReminderSettingsInput, adminID uuid.UUID) (dtos.ReminderSettingsOutput, error) {
+	if len(input.OffsetsMinutes) > 5 {
+		return dtos.ReminderSettingsOutput{}, utils.BadRequestError("at most 5 reminders are allowed")
+	}
+
+	seen := make(map[int]bool)
+	offsets := make([]int, 0, len(input.OffsetsMinutes))
+
+	for _, offset := range input.OffsetsMinutes {
+		if offset < 1 || offset > maxReminderOffsetMinutes {
+			return dtos.ReminderSettingsOutput{}, utils.BadRequestError("reminder offsets must be between 1 minute and 7 days")
+		}
+
+		if !seen[offset] {
+			seen[offset] = true
+			offsets = append(offsets, offset)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+
+	if err := service.Repo.UpdateSettings(ctx, adminID, *input.Enabled, offsets); err != nil {
+		utils.LogError("updateSettings reminder service (error call to repository)", err)
+		return dtos.ReminderSettingsOutput{}, err
+	}
+
+	return dtos.ReminderSettingsOutput{Enabled: *input.Enabled, OffsetsMinutes: offsets}, nil
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// maxReportDays caps report ranges at about five years.
+const maxReportDays = 5 * 366
+
+type ReportService struct {
+	Repo *repository.ReportRepository
+	TaxRepo *repository.TaxRepository
+}
+
+// Report builds the named report for the range between from and to
+// ("2006-01-02", both inclusive). Without from the range starts on the first
+// of January of to's year, and without to it ends today.
+func (service *ReportService) Report(ctx context.Context, adminID uuid.UUID, name, from, to string) (any, error) {
+	start, end, err := reportRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report, _, err := service.build(ctx, adminID, name, start, end)
+	return report, err
+}
+
+// ReportCSV is the report laid out as CSV, with a header line.
+func (service *ReportService) ReportCSV(ctx context.Context, adminID uuid.UUID, name, from, to string) ([]byte, string, error) {
+	start, end, err := reportRange(from, to)
+	if err != nil {
+		return nil, "", err
+	}
+
+	_, rows, err := service.build(ctx, adminID, name, start, end)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+
+	if err := w.WriteAll(rows); err != nil {
+		utils.LogError("reportCSV report service (error writing csv)", err)
+		return nil, "", utils.InternalServerError("error exporting report")
+	}
+
+	return buf.Bytes(), fmt.Sprintf("%s-%s-%s.csv", name, start.Format("2006-01-02"), end.Format("2006-01-02")), nil
+}
+
+// build returns the report along with its CSV rows. from and to are
+// midnights in the app's timezone.
+func (service *ReportService) build(ctx context.Context, adminID uuid.UUID, name string, from, to time.Time) (any, [][]string, error) {
+	if name == dtos.ReportBalances {
+		balances, err := service.Repo.GetOutstandingBalances(ctx, adminID, to.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rows := [][]string{{"paciente", "saldo_devedor", "ultimo_lancamento"}}
+
+		for _, balance := range balances {
+			rows = append(rows, []string{
+				csvText(balance.PatientName),
+				formatCSVAmount(balance.BalanceCents),
+				balance.LastEntryAt.In(utils.AppLocation()).Format("02/01/2006"),
+			})
+		}
+
+		return balances, rows, nil
+	}
+
+	if name != dtos.ReportRevenueByMonth && name != dtos.ReportRevenueByPatient && name != dtos.ReportRevenueByModality && name != dtos.ReportAverageTicket {
+		return nil, nil, utils.NotFoundError("report not found")
+	}
+
+	sessions, err := service.Repo.GetSessions(ctx, adminID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	income, err := service.TaxRepo.GetIncome(ctx, adminID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch name {
+	case dtos.ReportRevenueByMonth:
+		months := revenueByMonth(sessions, income, from, to)
+
+		rows := [][]string{{"mes", "sessoes", "faturado", "recebido", "ticket_medio"}}
+		for _, month := range months {
+			rows = append(rows, append([]string{month.Month}, revenueColumns(month.Revenue)...))
+		}
+
+		return months, rows, nil
+
+	case dtos.ReportRevenueByPatient:
+		patients := revenueByPatient(sessions, income)
+
+		rows := [][]string{{"paciente", "sessoes", "faturado", "recebido", "ticket_medio"}}
+		for _, patient := range patients {
+			rows = append(rows, append([]string{csvText(patient.PatientName)}, revenueColumns(patient.Revenue)...))
+		}
+
+		return patients, rows, nil
+
+	case dtos.ReportRevenueByModality:
+		modalities := revenueByModality(sessions)
+
+		rows := [][]string{{"modalidade", "sessoes", "faturado", "ticket_medio"}}
+		for _, modality := range modalities {
+			rows = append(rows, []string{
+				csvText(modality.Modality),
+				strconv.Itoa(modality.Sessions),
+				formatCSVAmount(modality.BilledCents),
+				formatCSVAmount(modality.AverageTicketCents),
+			})
+		}
+
+		return modalities, rows, nil
+	}
+
+	ticket := dtos.AverageTicketOutput{From: from.Format("2006-01-02"), To: to.Format("2006-01-02")}
+
+	for _, session := range sessions {
+		addSession(&ticket.Revenue, session)
+	}
+
+	for _, entry := range income {
+		addIncome(&ticket.Revenue, entry)
+	}
+
+	setAverageTicket(&ticket.Revenue)
+
+	rows := [][]string{
+		{"inicio", "fim", "sessoes", "faturado", "recebido", "ticket_medio"},
+		append([]string{ticket.From, ticket.To}, revenueColumns(ticket.Revenue)...),
+	}
+
+	return ticket, rows, nil
+}
+
+func revenueByMonth(sessions []dtos.ReportSession, income []dtos.IncomeEntry, from, to time.Time) []dtos.MonthRevenueOutput {
+	months := make([]dtos.MonthRevenueOutput, 0)
+	index := make(map[string]int)
+
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !month.After(to); month = month.AddDate(0, 1, 0) {
+		index[month.Format("2006-01")] = len(months)
+		months = append(months, dtos.MonthRevenueOutput{Month: month.Format("2006-01")})
+	}
+
+	for _, session := range sessions {
+		addSession(&months[index[session.Date.Format("2006-01")]].Revenue, session)
+	}
+
+	loc := utils.AppLocation()
+
+	for _, entry := range income {
+		addIncome(&months[index[entry.CreatedAt.In(loc).Format("2006-01")]].Revenue, entry)
+	}
+
+	for i := range months {
+		setAverageTicket(&months[i].Revenue)
+	}
+
+	return months
+}
+
+// revenueByPatient sorts patients by what they were billed, highest first.
+func revenueByPatient(sessions []dtos.ReportSession, income []dtos.IncomeEntry) []dtos.PatientRevenueOutput {
+	patients := make([]dtos.PatientRevenueOutput, 0)
+	index := make(map[uuid.UUID]int)
+
+	patient := func(id uuid.UUID, name string) *dtos.PatientRevenueOutput {
+		i, ok := index[id]
+		if !ok {
+			i = len(patients)
+			index[id] = i
+			patients = append(patients, dtos.PatientRevenueOutput{PatientID: id, PatientName: name})
+		}
+
+		return &patients[i]
+	}
+
+	for _, session := range sessions {
+		addSession(&patient(session.PatientID, session.PatientName).Revenue, session)
+	}
+
+	for _, entry := range income {
+		addIncome(&patient(entry.PatientID, entry.PatientName).Revenue, entry)
+	}
+
+	for i := range patients {
+		setAverageTicket(&patients[i].Revenue)
+	}
+
+	sort.SliceStable(patients, func(i, j int) bool {
+		if patients[i].BilledCents != patients[j].BilledCents {
+			return patients[i].BilledCents > patients[j].BilledCents
+		}
+
+		return patients[i].PatientName < patients[j].PatientName
+	})
+
+	return patients
+}
+
+func revenueByModality(sessions []dtos.ReportSession) []dtos.ModalityRevenueOutput {
+	modalities := make([]dtos.ModalityRevenueOutput, 0)
+	index := make(map[string]int)
+
+	for _, session := range sessions {
+		i, ok := index[session.Modality]
+		if !ok {
+			i = len(modalities)
+			index[session.Modality] = i
+			modalities = append(modalities, dtos.ModalityRevenueOutput{Modality: session.Modality})
+		}
+
+		modalities[i].Sessions++
+		modalities[i].BilledCents += session.ValueCents
+	}
+
+	for i := range modalities {
+		modalities[i].AverageTicketCents = averageCents(modalities[i].BilledCents, modalities[i].Sessions)
+	}
+
+	sort.Slice(modalities, func(i, j int) bool {
+		return modalities[i].Modality < modalities[j].Modality
+	})
+
+	return modalities
+}
+
+func addSession(revenue *dtos.Revenue, session dtos.ReportSession) {
+	revenue.Sessions++
+	revenue.BilledCents += session.ValueCents
+}
+
+func addIncome(revenue *dtos.Revenue, entry dtos.IncomeEntry) {
+	if entry.Kind == dtos.EntryRefund {
+		revenue.ReceivedCents -= entry.AmountCents
+		return
+	}
+
+	revenue.ReceivedCents += entry.AmountCents
+}
+
+func setAverageTicket(revenue *dtos.Revenue) {
+	revenue.AverageTicketCents = averageCents(revenue.BilledCents, revenue.Sessions)
+}
+
+// averageCents divides rounding to the nearest cent.
+func averageCents(totalCents int64, count int) int64 {
+	if count == 0 {
+		return 0
+	}
+
+	return (totalCents + int64(count)/2) / int64(count)
+}
+
+func revenueColumns(revenue dtos.Revenue) []string {
+	return []string{
+		strconv.Itoa(revenue.Sessions),
+		formatCSVAmount(revenue.BilledCents),
+		formatCSVAmount(revenue.ReceivedCents),
+		formatCSVAmount(revenue.AverageTicketCents),
+	}
+}
+
+// reportRange parses the range of a report, filling in the defaults
+// described on Report.
+func reportRange(from, to string) (time.Time, time.Time, error) {
+	loc := utils.AppLocation()
+
+	now := time.Now().In(loc)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	if to != "" {
+		var err error
+
+		end, err = time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, utils.BadRequestError("invalid format to, use YYYY-MM-DD")
+		}
+	}
+
+	start := time.Date(end.Year(), time.January, 1, 0, 0, 0, 0, loc)
+
+	if from != "" {
+		var err error
+
+		start, err = time.ParseInLocation("2006-01-02", from, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, utils.BadRequestError("invalid format from, use YYYY-MM-DD")
+		}
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, utils.BadRequestError("to must not be before from")
+	}
+
+	if end.Sub(start) > maxReportDays*24*time.Hour {
+		return time.Time{}, time.Time{}, utils.BadRequestError(fmt.Sprintf("reports cover at most %d days", maxReportDays))
+	}
+
+	return start, end, nil
+}
\ No newline at end of file
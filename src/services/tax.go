@@ -0,0 +1,243 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+// carneLeaoAccount is the Carnê-Leão account for income from self-employed
+// work received from individuals.
+const carneLeaoAccount = "R01.001.001"
+
+type TaxService struct {
+	Repo *repository.TaxRepository
+}
+
+// UpdatePayerCPF sets who pays for the patient's sessions; an empty cpf
+// makes the patient the payer again.
+func (service *TaxService) UpdatePayerCPF(ctx context.Context, patientID, adminID uuid.UUID, input dtos.PayerCPFInput) error {
+	cpf := ""
+
+	if strings.TrimSpace(input.PayerCPF) != "" {
+		var ok bool
+
+		cpf, ok = utils.NormalizeCPF(input.PayerCPF)
+		if !ok {
+			return utils.BadRequestError("invalid payer_cpf")
+		}
+	}
+
+	return service.Repo.UpdatePayerCPF(ctx, patientID, adminID, cpf)
+}
+
+// CarneLeaoCSV exports what each patient paid in the month, net of refunds,
+// in the Carnê-Leão import layout: one line per patient with the date of
+// their last payment, the account, the amount, a description, the payer's
+// cpf and the beneficiary's cpf, separated by semicolons.
+func (service *TaxService) CarneLeaoCSV(ctx context.Context, adminID uuid.UUID, month string) ([]byte, string, error) {
+	loc := utils.AppLocation()
+
+	from, err := time.ParseInLocation("2006-01", month, loc)
+	if err != nil {
+		return nil, "", utils.BadRequestError("invalid format month, use YYYY-MM")
+	}
+
+	entries, err := service.Repo.GetIncome(ctx, adminID, from, from.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows := monthlyIncome(entries, loc)
+
+	var missing []string
+
+	for _, row := range rows {
+		if row.PatientCPF == "" {
+			missing = append(missing, row.PatientName)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, "", utils.BadRequestError("set the cpf of these patients before exporting: " + strings.Join(missing, ", "))
+	}
+
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	w.UseCRLF = true
+
+	for _, row := range rows {
+		err := w.Write([]string{
+			row.PaidAt.Format("02/01/2006"),
+			carneLeaoAccount,
+			formatCSVAmount(row.TotalCents),
+			"Atendimento psicológico",
+			row.PayerCPF,
+			row.PatientCPF,
+		})
+		if err != nil {
+			utils.LogError("carneLeaoCSV tax service (error writing csv)", err)
+			return nil, "", utils.InternalServerError("error exporting income")
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		utils.LogError("carneLeaoCSV tax service (error writing csv)", err)
+		return nil, "", utils.InternalServerError("error exporting income")
+	}
+
+	return buf.Bytes(), "carne-leao-" + from.Format("2006-01") + ".csv", nil
+}
+
+// YearSummary totals what was received in the year, by month and by
+// patient. An empty year means the current one.
+func (service *TaxService) YearSummary(ctx context.Context, adminID uuid.UUID, year string) (dtos.IncomeSummaryOutput, error) {
+	loc := utils.AppLocation()
+
+	y := time.Now().In(loc).Year()
+
+	if year != "" {
+		var err error
+
+		y, err = strconv.Atoi(year)
+		if err != nil || y < 2000 || y > 9999 {
+			return dtos.IncomeSummaryOutput{}, utils.BadRequestError("invalid format year, use YYYY")
+		}
+	}
+
+	from := time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+
+	entries, err := service.Repo.GetIncome(ctx, adminID, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return dtos.IncomeSummaryOutput{}, err
+	}
+
+	summary := dtos.IncomeSummaryOutput{
+		Year: y,
+		Months: make([]dtos.MonthIncomeOutput, 12),
+		Patients: make([]dtos.PatientIncomeOutput, 0),
+	}
+
+	for i := range summary.Months {
+		summary.Months[i].Month = from.AddDate(0, i, 0).Format("2006-01")
+	}
+
+	patients := make(map[uuid.UUID]int)
+
+	for _, row := range monthlyIncome(entries, loc) {
+		summary.TotalCents += row.TotalCents
+		summary.Months[row.PaidAt.Month()-1].TotalCents += row.TotalCents
+
+		i, ok := patients[row.PatientID]
+		if !ok {
+			i = len(summary.Patients)
+			patients[row.PatientID] = i
+
+			summary.Patients = append(summary.Patients, dtos.PatientIncomeOutput{
+				PatientID: row.PatientID,
+				PatientName: row.PatientName,
+				PatientCPF: row.PatientCPF,
+				PayerCPF: row.PayerCPF,
+			})
+
+			if row.PatientCPF == "" {
+				summary.MissingCPF++
+			}
+		}
+
+		summary.Patients[i].TotalCents += row.TotalCents
+	}
+
+	return summary, nil
+}
+
+type incomeRow struct {
+	PatientID   uuid.UUID
+	PatientName string
+	PatientCPF  string
+	PayerCPF    string
+	PaidAt      time.Time
+	TotalCents  int64
+}
+
+// monthlyIncome nets payments and refunds per patient and month, in the
+// order patients first paid. Rows where refunds cancel out the payments are
+// left out.
+func monthlyIncome(entries []dtos.IncomeEntry, loc *time.Location) []incomeRow {
+	type key struct {
+		patientID uuid.UUID
+		month     string
+	}
+
+	var rows []incomeRow
+	index := make(map[key]int)
+
+	for _, entry := range entries {
+		paidAt := entry.CreatedAt.In(loc)
+		k := key{entry.PatientID, paidAt.Format("2006-01")}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(rows)
+			index[k] = i
+
+			payer := entry.PayerCPF
+			if payer == "" {
+				payer = entry.PatientCPF
+			}
+
+			rows = append(rows, incomeRow{
+				PatientID: entry.PatientID,
+				PatientName: entry.PatientName,
+				PatientCPF: entry.PatientCPF,
+				PayerCPF: payer,
+			})
+		}
+
+		if entry.Kind == dtos.EntryRefund {
+			rows[i].TotalCents -= entry.AmountCents
+			continue
+		}
+
+		rows[i].TotalCents += entry.AmountCents
+		rows[i].PaidAt = paidAt
+	}
+
+	income := make([]incomeRow, 0, len(rows))
+
+	for _, row := range rows {
+		if row.TotalCents > 0 {
+			income = append(income, row)
+		}
+	}
+
+	return income
+}
+
+// formatCSVAmount writes cents the way Brazilian spreadsheets and Carnê-Leão
+// read them, with a decimal comma and no thousands separator.
+func formatCSVAmount(cents int64) string {
+	return fmt.Sprintf("%d,%02d", cents/100, cents%100)
+}
+
+// csvText guards a free text cell against formula injection: spreadsheets
+// run cells starting with =, +, - or @, so those get a leading quote.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
\ No newline at end of file
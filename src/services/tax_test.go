@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+)
+
+func TestMonthlyIncome(t *testing.T) {
+	loc := time.FixedZone("BRT", -3*60*60)
+	ana, bruno, carla := uuid.New(), uuid.New(), uuid.New()
+
+	entries := []dtos.IncomeEntry{
+		{PatientID: bruno, PatientName: "Bruno", PatientCPF: "11111111111", PayerCPF: "22222222222", Kind: dtos.EntryPayment, AmountCents: 20000, CreatedAt: time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC)},
+		{PatientID: ana, PatientName: "Ana", PatientCPF: "33333333333", Kind: dtos.EntryPayment, AmountCents: 15000, CreatedAt: time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)},
+		{PatientID: bruno, PatientName: "Bruno", PatientCPF: "11111111111", PayerCPF: "22222222222", Kind: dtos.EntryRefund, AmountCents: 5000, CreatedAt: time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC)},
+		{PatientID: carla, PatientName: "Carla", Kind: dtos.EntryPayment, AmountCents: 10000, CreatedAt: time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{PatientID: carla, PatientName: "Carla", Kind: dtos.EntryRefund, AmountCents: 10000, CreatedAt: time.Date(2025, 3, 16, 12, 0, 0, 0, time.UTC)},
+		// still March in Brasília
+		{PatientID: ana, PatientName: "Ana", PatientCPF: "33333333333", Kind: dtos.EntryPayment, AmountCents: 15000, CreatedAt: time.Date(2025, 4, 1, 2, 0, 0, 0, time.UTC)},
+		{PatientID: ana, PatientName: "Ana", PatientCPF: "33333333333", Kind: dtos.EntryPayment, AmountCents: 15000, CreatedAt: time.Date(2025, 4, 7, 12, 0, 0, 0, time.UTC)},
+	}
+
+	want := []incomeRow{
+		{PatientID: bruno, PatientName: "Bruno", PatientCPF: "11111111111", PayerCPF: "22222222222", PaidAt: time.Date(2025, 3, 5, 9, 0, 0, 0, loc), TotalCents: 15000},
+		{PatientID: ana, PatientName: "Ana", PatientCPF: "33333333333", PayerCPF: "33333333333", PaidAt: time.Date(2025, 3, 31, 23, 0, 0, 0, loc), TotalCents: 30000},
+		{PatientID: ana, PatientName: "Ana", PatientCPF: "33333333333", PayerCPF: "33333333333", PaidAt: time.Date(2025, 4, 7, 9, 0, 0, 0, loc), TotalCents: 15000},
+	}
+
+	got := monthlyIncome(entries, loc)
+	if len(got) != len(want) {
+		t.Fatalf("monthlyIncome() returned %d rows, want %d: %+v", len(got), len(want), got)
+	}
+
+	for i := range want {
+		g, w := got[i], want[i]
+
+		if g.PatientID != w.PatientID || g.PatientName != w.PatientName || g.PatientCPF != w.PatientCPF || g.PayerCPF != w.PayerCPF || g.TotalCents != w.TotalCents || !g.PaidAt.Equal(w.PaidAt) {
+			t.Errorf("row %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestFormatCSVAmount(t *testing.T) {
+	tests := []struct {
+		cents int64
+		want  string
+	}{
+		{0, "0,00"},
+		{5, "0,05"},
+		{90, "0,90"},
+		{100, "1,00"},
+		{15050, "150,50"},
+		{123456789, "1234567,89"},
+	}
+
+	for _, tt := range tests {
+		if got := formatCSVAmount(tt.cents); got != tt.want {
+			t.Errorf("formatCSVAmount(%d) = %q, want %q", tt.cents, got, tt.want)
+		}
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Ana Souza", "Ana Souza"},
+		{"123456", "123456"},
+		{"Ana =1+1", "Ana =1+1"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+5511", "'+5511"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+	}
+
+	for _, tt := range tests {
+		if got := csvText(tt.value); got != tt.want {
+			t.Errorf("csvText(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jhonnydsl/clinify-backend/src/dtos"
+	"github.com/jhonnydsl/clinify-backend/src/mailer"
+	"github.com/jhonnydsl/clinify-backend/src/repository"
+	"github.com/jhonnydsl/clinify-backend/src/utils"
+)
+
+const (
+	// waitlistOfferTTL is how long a patient has to claim an offered slot
+	// before it rolls over to the next person in line.
+	waitlistOfferTTL = 4 * time.Hour
+	// waitlistMinNotice keeps offers away from slots starting too soon for the
+	// patient to answer and get there.
+	waitlistMinNotice = 2 * time.Hour
+	// waitlistHorizonDays is how far ahead new availability is offered.
+	waitlistHorizonDays = 28
+)
+
+type WaitlistService struct {
+	Repo          *repository.WaitlistRepository
+	Admin         *AdminService
+	Notifications *NotificationService
+}
+
+func (service *WaitlistService) Join(ctx context.Context, patientID uuid.UUID, input dtos.WaitlistInput) (dtos.WaitlistEntryOutput, error) {
+	if len(input.Weekdays) == 0 {
+		return dtos.WaitlistEntryOutput{}, utils.BadRequestError("at least one weekday is required")
+	}
+
+	seen := make(map[int]bool)
+	weekdays := make([]int, 0, len(input.Weekdays))
+
+	for _, weekday := range input.Weekdays {
+		if weekday < 0 || weekday > 6 {
+			return dtos.WaitlistEntryOutput{}, utils.BadRequestError("weekdays must be between 0 (sunday) and 6 (saturday)")
+		}
+
+		if !seen[weekday] {
+			seen[weekday] = true
+			weekdays = append(weekdays, weekday)
+		}
+	}
+
+	input.Weekdays = weekdays
+
+	start, err := utils.ParseTime(input.StartTime)
+	if err != nil {
+		return dtos.WaitlistEntryOutput{}, utils.BadRequestError("invalid format start_time")
+	}
+
+	end, err := utils.ParseTime(input.EndTime)
+	if err != nil {
+		return dtos.WaitlistEntryOutput{}, utils.BadRequestError("invalid format end_time")
+	}
+
+	if !start.Before(end) {
+		return dtos.WaitlistEntryOutput{}, utils.BadRequestError("start_time must be before end_time")
+	}
+
+	if input.Modality != "" && input.Modality != dtos.ModalityInPerson && input.Modality != dtos.ModalityOnline {
+		return dtos.WaitlistEntryOutput{}, utils.BadRequestError("modality must be in_person or online")
+	}
+
+	if _, err := service.Repo.CreateEntry(ctx, patientID, input, start, end); err != nil {
+		return dtos.WaitlistEntryOutput{}, err
+	}
+
+	return service.Repo.GetEntryByPatient(ctx, patientID)
+}
+
+func (service *WaitlistService) GetEntry(ctx context.Context, patientID uuid.UUID) (dtos.WaitlistEntryOutput, error) {
+	return service.Repo.GetEntryByPatient(ctx, patientID)
+}
+
+func (service *WaitlistService) GetEntries(ctx context.Context, adminID uuid.UUID) ([]dtos.WaitlistEntryOutput, error) {
+	return service.Repo.GetEntries(ctx, adminID)
+}
+
+// Leave takes the patient out of the line and passes the offer they held, if
+// any, to the next person.
+func (service *WaitlistService) Leave(ctx context.Context, patientID uuid.UUID) error {
+	var days []dtos.OpenDay
+
+	err := repository.WithTx(ctx, func(tx repository.DBTX) error {
+		var err error
+		days, err = service.Repo.LeaveWaitlist(ctx, tx, patientID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	service.offerDays(ctx, days)
+
+	return nil
+}
+
+// OfferDate offers each free time of date to the first matching patient in
+// line. Times that already have an open offer are left alone.
+func (service *WaitlistService) OfferDate(ctx context.Context, adminID uuid.UUID, date time.Time) error {
+	day := date.Format("2006-01-02")
+	now := time.Now().In(utils.AppLocation())
+
+	slots, err := service.Admin.GetAvaliableSlots(ctx, adminID, day, "", "")
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range slots {
+		startsAt, err := utils.ParseDateTimeInLocation(day, slot.StartTime)
+		if err != nil || startsAt.Before(now.Add(waitlistMinNotice)) {
+			continue
+		}
+
+		expiresAt := now.Add(waitlistOfferTTL)
+		if latest := startsAt.Add(-waitlistMinNotice); expiresAt.After(latest) {
+			expiresAt = latest
+		}
+
+		if err := service.offerSlot(ctx, adminID, date, slot, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OfferWeekday offers the free times of the coming weeks that fall on
+// weekday, after the psychologist opened new availability.
+func (service *WaitlistService) OfferWeekday(ctx context.Context, adminID uuid.UUID, weekday int) error {
+	today := time.Now().In(utils.AppLocation())
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < waitlistHorizonDays; i++ {
+		date := today.AddDate(0, 0, i)
+		if int(date.Weekday()) != weekday {
+			continue
+		}
+
+		if err := service.OfferDate(ctx, adminID, date); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExpireOffers closes the offers nobody claimed in time and rolls their slots
+// over to the next patients in line.
+func (service *WaitlistService) ExpireOffers(ctx context.Context) error {
+	days, err := service.Repo.ExpireOffers(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	service.offerDays(ctx, days)
+
+	return nil
+}
+
+func (service *WaitlistService) offerDays(ctx context.Context, days []dtos.OpenDay) {
+	seen := make(map[string]bool)
+
+	for _, day := range days {
+		key := day.ClientID.String() + day.Date.Format("2006-01-02")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if err := service.OfferDate(ctx, day.ClientID, day.Date); err != nil {
+			utils.LogError("offerDays waitlist service (error offering slots)", err)
+		}
+	}
+}
+
+// offerSlot creates the offer and queues its message in one transaction.
+func (service *WaitlistService) offerSlot(ctx context.Context, adminID uuid.UUID, date time.Time, slot dtos.AvailableSlotOutput, expiresAt time.Time) error {
+	start, err := utils.ParseTime(slot.StartTime)
+	if err != nil {
+		return utils.InternalServerError("error offering slot")
+	}
+
+	end, err := utils.ParseTime(slot.EndTime)
+	if err != nil {
+		return utils.InternalServerError("error offering slot")
+	}
+
+	var locationID uuid.NullUUID
+	if slot.LocationID != nil {
+		locationID = uuid.NullUUID{UUID: *slot.LocationID, Valid: true}
+	}
+
+	return repository.WithTx(ctx, func(tx repository.DBTX) error {
+		offerID, created, err := service.Repo.CreateOffer(ctx, tx, adminID, date, start, end, locationID, slot.Modality, expiresAt)
+		if err != nil || !created {
+			return err
+		}
+
+		offer, err := service.Repo.GetOffer(ctx, tx, offerID)
+		if err != nil {
+			return err
+		}
+
+		claimURL, declineURL, err := utils.BuildWaitlistOfferLinks(offer.ID, offer.ExpiresAt)
+		if err != nil {
+			utils.LogError("offerSlot waitlist service (error building offer links)", err)
+			return utils.InternalServerError("error offering slot")
+		}
+
+		data := mailer.AppointmentData{
+			PatientName: offer.Patient.Name,
+			Date:        offer.Date.Format("2006-01-02"),
+			StartTime:   offer.StartTime.Format("15:04"),
+			EndTime:     offer.EndTime.Format("15:04"),
+			Modality:    offer.Modality,
+			ClaimURL:    claimURL,
+			DeclineURL:  declineURL,
+			ExpiresAt:   offer.ExpiresAt.In(utils.AppLocation()).Format("02/01/2006 15:04"),
+		}
+
+		if offer.Modality != dtos.ModalityOnline {
+			data.Address = offer.Address
+		}
+
+		return service.Notifications.NotifyPatient(ctx, tx, offer.ClientID, mailer.TemplateWaitlistOffer, offer.Patient, data)
+	})
+}
+
+// ClaimOffer books the offered slot for the patient holding the link.
+// Claiming the same offer twice is not an error.
+func (service *WaitlistService) ClaimOffer(ctx context.Context, token string) error {
+	offerID, action, err := utils.ParseActionToken(token)
+	if err != nil || action != utils.ActionClaim {
+		return utils.BadRequestError("invalid or expired link")
+	}
+
+	taken := false
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		offer, err := service.Repo.GetOffer(ctx, tx, offerID)
+		if err != nil {
+			return err
+		}
+
+		if offer.Status == dtos.OfferClaimed {
+			return nil
+		}
+
+		if offer.Status != dtos.OfferPending || !time.Now().Before(offer.ExpiresAt) {
+			return utils.BadRequestError("this offer has expired")
+		}
+
+		// bookings of the day wait for the claim, so the slot stays free
+		// between the check and the insert
+		if err := service.Admin.Repo.LockDay(ctx, tx, offer.ClientID, offer.Date); err != nil {
+			return err
+		}
+
+		available, err := service.slotAvailable(ctx, offer)
+		if err != nil {
+			return err
+		}
+
+		// someone booked the time meanwhile: close the offer instead of
+		// leaving it open until it expires
+		if !available {
+			taken = true
+			return service.Repo.RespondOffer(ctx, tx, offerID, dtos.OfferExpired, uuid.NullUUID{})
+		}
+
+		input := dtos.AppointmentInput{
+			PatientID: offer.PatientID.String(),
+			Date:      offer.Date.Format("2006-01-02"),
+			StartTime: offer.StartTime.Format("15:04"),
+			EndTime:   offer.EndTime.Format("15:04"),
+			Modality:  offer.Modality,
+		}
+
+		appointmentID, err := service.Admin.bookAppointment(ctx, tx, input, offer.Date, offer.StartTime, offer.EndTime, offer.LocationID, offer.ClientID)
+		if err != nil {
+			return err
+		}
+
+		if err := service.Repo.RespondOffer(ctx, tx, offerID, dtos.OfferClaimed, uuid.NullUUID{UUID: appointmentID, Valid: true}); err != nil {
+			return err
+		}
+
+		return service.Repo.SetEntryStatus(ctx, tx, offer.EntryID, dtos.WaitlistBooked)
+	})
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return utils.ConflictError("this time is no longer available")
+	}
+
+	return nil
+}
+
+// DeclineOffer releases the offered slot to the next patient in line. The
+// patient stays on the waitlist for other times.
+func (service *WaitlistService) DeclineOffer(ctx context.Context, token string) error {
+	offerID, action, err := utils.ParseActionToken(token)
+	if err != nil || action != utils.ActionDecline {
+		return utils.BadRequestError("invalid or expired link")
+	}
+
+	var offer dtos.WaitlistOffer
+
+	err = repository.WithTx(ctx, func(tx repository.DBTX) error {
+		offer, err = service.Repo.GetOffer(ctx, tx, offerID)
+		if err != nil {
+			return err
+		}
+
+		if offer.Status == dtos.OfferDeclined {
+			return nil
+		}
+
+		if offer.Status != dtos.OfferPending {
+			return utils.BadRequestError("this offer is no longer available")
+		}
+
+		return service.Repo.RespondOffer(ctx, tx, offerID, dtos.OfferDeclined, uuid.NullUUID{})
+	})
+	if err != nil {
+		return err
+	}
+
+	service.offerDays(ctx, []dtos.OpenDay{{ClientID: offer.ClientID, Date: offer.Date}})
+
+	return nil
+}
+
+func (service *WaitlistService) slotAvailable(ctx context.Context, offer dtos.WaitlistOffer) (bool, error) {
+	slots, err := service.Admin.GetAvaliableSlots(ctx, offer.ClientID, offer.Date.Format("2006-01-02"), "", offer.Modality)
+	if err != nil {
+		return false, err
+	}
+
+	for _, slot := range slots {
+		if slot.StartTime == offer.StartTime.Format("15:04") && slot.EndTime == offer.EndTime.Format("15:04") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
\ No newline at end of file
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var actionPageTemplate = template.Must(template.New("action_page").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head><meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>{{.Title}}</title></head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 40px auto; text-align: center;">
+	<h2>{{.Title}}</h2>
+	<p>{{.Message}}</p>
+	{{if .FormAction}}
+	<form method="POST" action="{{.FormAction}}">
+		<button type="submit" style="padding: 10px 24px; font-size: 16px;">{{.ButtonLabel}}</button>
+	</form>
+	{{end}}
+</body>
+</html>`))
+
+// RenderActionPage renders the small page shown when a patient opens a link
+// from an email. Actions only run on the form POST, so link scanners that
+// prefetch URLs in emails do not confirm or cancel sessions by themselves.
+func RenderActionPage(title, message, formAction, buttonLabel string) []byte {
+	var buf bytes.Buffer
+
+	err := actionPageTemplate.Execute(&buf, map[string]string{
+		"Title": title,
+		"Message": message,
+		"FormAction": formAction,
+		"ButtonLabel": buttonLabel,
+	})
+	if err != nil {
+		LogError("renderActionPage (error executing template)", err)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	ActionConfirm = "confirm"
+	ActionCancel  = "cancel"
+	ActionClaim   = "claim"
+	ActionDecline = "decline"
+)
+
+func actionSecret() []byte {
+	secret := os.Getenv("LINK_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+
+	return []byte(secret)
+}
+
+// GenerateActionToken signs (HMAC-SHA256) a token that lets whoever holds it
+// run a single action on a single appointment until expiresAt.
+func GenerateActionToken(appointmentID uuid.UUID, action string, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": appointmentID.String(),
+		"act": action,
+		"typ": "appointment_action",
+		"exp": expiresAt.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(actionSecret())
+}
+
+func ParseActionToken(tokenStr string) (uuid.UUID, string, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return actionSecret(), nil
+	})
+	if err != nil || !token.Valid {
+		return uuid.UUID{}, "", fmt.Errorf("invalid or expired link")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != "appointment_action" {
+		return uuid.UUID{}, "", fmt.Errorf("invalid link")
+	}
+
+	sub, _ := claims["sub"].(string)
+	action, _ := claims["act"].(string)
+
+	appointmentID, err := uuid.Parse(sub)
+	if err != nil {
+		return uuid.UUID{}, "", fmt.Errorf("invalid link")
+	}
+
+	return appointmentID, action, nil
+}
+
+// BuildAppointmentActionLinks returns the confirm and cancel links sent to the
+// patient. Both expire when the session starts.
+func BuildAppointmentActionLinks(appointmentID uuid.UUID, startsAt time.Time) (string, string, error) {
+	confirmToken, err := GenerateActionToken(appointmentID, ActionConfirm, startsAt)
+	if err != nil {
+		return "", "", err
+	}
+
+	cancelToken, err := GenerateActionToken(appointmentID, ActionCancel, startsAt)
+	if err != nil {
+		return "", "", err
+	}
+
+	return actionURL(ActionConfirm, confirmToken), actionURL(ActionCancel, cancelToken), nil
+}
+
+// BuildWaitlistOfferLinks returns the claim and decline links of a waitlist
+// offer. The token subject is the offer id and both expire with the offer.
+func BuildWaitlistOfferLinks(offerID uuid.UUID, expiresAt time.Time) (string, string, error) {
+	claimToken, err := GenerateActionToken(offerID, ActionClaim, expiresAt)
+	if err != nil {
+		return "", "", err
+	}
+
+	declineToken, err := GenerateActionToken(offerID, ActionDecline, expiresAt)
+	if err != nil {
+		return "", "", err
+	}
+
+	return linkURL("waitlist", ActionClaim, claimToken), linkURL("waitlist", ActionDecline, declineToken), nil
+}
+
+func actionURL(action, token string) string {
+	return linkURL("appointments", action, token)
+}
+
+func linkURL(resource, action, token string) string {
+	baseURL := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+	return fmt.Sprintf("%s/api/v1/%s/%s?token=%s", baseURL, resource, action, url.QueryEscape(token))
+}
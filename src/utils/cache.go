@@ -21,4 +21,8 @@ type SlotsCache struct {
 	Data []dtos.CalendarSlotsOutput
 }
 
+type LocationsCache struct {
+	Data []dtos.LocationOutput
+}
+
 var Cache = cache.New(30*time.Second, 1*time.Minute)
\ No newline at end of file
@@ -0,0 +1,45 @@
+package utils
+
+import "strings"
+
+// NormalizeCPF strips the punctuation from a CPF and checks its length and
+// check digits. It returns the 11 digits and whether the CPF is valid.
+func NormalizeCPF(cpf string) (string, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		if r == '.' || r == '-' || r == ' ' {
+			return -1
+		}
+		return 'x'
+	}, cpf)
+
+	if len(digits) != 11 || strings.ContainsRune(digits, 'x') || strings.Count(digits, digits[:1]) == 11 {
+		return "", false
+	}
+
+	for n := 9; n <= 10; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(digits[i]-'0') * (n + 1 - i)
+		}
+
+		digit := sum * 10 % 11 % 10
+		if digit != int(digits[n]-'0') {
+			return "", false
+		}
+	}
+
+	return digits, true
+}
+
+// FormatCPF formats 11 digits as "123.456.789-09". Anything else is returned
+// unchanged.
+func FormatCPF(cpf string) string {
+	if len(cpf) != 11 {
+		return cpf
+	}
+
+	return cpf[:3] + "." + cpf[3:6] + "." + cpf[6:9] + "-" + cpf[9:]
+}
\ No newline at end of file
@@ -18,6 +18,18 @@ func ConflictError(message string) *dtos.APIError {
 	return &dtos.APIError{StatusCode: http.StatusConflict, Message: message}
 }
 
+func UnauthorizedError(message string) *dtos.APIError {
+	return &dtos.APIError{StatusCode: http.StatusUnauthorized, Message: message}
+}
+
+func ForbiddenError(message string) *dtos.APIError {
+	return &dtos.APIError{StatusCode: http.StatusForbidden, Message: message}
+}
+
+func PreconditionFailedError(message string) *dtos.APIError {
+	return &dtos.APIError{StatusCode: http.StatusPreconditionFailed, Message: message}
+}
+
 func InternalServerError(message string) *dtos.APIError {
 	return &dtos.APIError{StatusCode: http.StatusInternalServerError, Message: message}
 }
@@ -71,4 +71,16 @@ func AdminOnlyMiddleware() gin.HandlerFunc {
 		}
 		c.Next()
 	}
+}
+
+func PatientOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if role != "patient" {
+			c.JSON(403, gin.H{"error": "forbidden: patient only"})
+			c.Abort()
+			return 
+		}
+		c.Next()
+	}
 }
\ No newline at end of file
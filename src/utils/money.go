@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatBRL formats an amount in cents as Brazilian reais, e.g. "R$ 1.234,56".
+func FormatBRL(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	units := fmt.Sprintf("%d", cents/100)
+
+	var b strings.Builder
+	for i, r := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+
+	return fmt.Sprintf("%sR$ %s,%02d", sign, b.String(), cents%100)
+}
\ No newline at end of file
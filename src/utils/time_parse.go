@@ -1,6 +1,9 @@
 package utils
 
-import "time"
+import (
+	"os"
+	"time"
+)
 
 func ParseDate(dateStr string) (time.Time, error) {
 	return time.Parse("2006-01-02", dateStr)
@@ -13,4 +16,25 @@ func ParseTime(timeStr string) (time.Time, error) {
 func ParseDateTime(dateStr, timeStr string) (time.Time, error) {
 	layout := "2006-01-02 15:04"
 	return time.Parse(layout, dateStr+" "+timeStr)
-}
\ No newline at end of file
+}
+
+// AppLocation is the timezone appointments are booked in (APP_TIMEZONE, defaults to America/Sao_Paulo).
+func AppLocation() *time.Location {
+	name := os.Getenv("APP_TIMEZONE")
+	if name == "" {
+		name = "America/Sao_Paulo"
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		LogError("appLocation (error loading timezone)", err)
+		return time.Local
+	}
+
+	return loc
+}
+
+func ParseDateTimeInLocation(dateStr, timeStr string) (time.Time, error) {
+	layout := "2006-01-02 15:04"
+	return time.ParseInLocation(layout, dateStr+" "+timeStr, AppLocation())
+}
@@ -0,0 +1,11 @@
+package utils
+
+import "github.com/google/uuid"
+
+func NullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+
+	return &id.UUID
+}
@@ -0,0 +1,85 @@
+package video
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// JitsiProvider builds Jitsi Meet room links locally, without calling any API.
+// Room names are derived from an HMAC of the appointment id so they cannot be
+// guessed from the id alone. When AppID and TokenSecret are set the link also
+// carries a signed join token, for Jitsi deployments with token auth enabled.
+type JitsiProvider struct {
+	BaseURL     string
+	Secret      string
+	AppID       string
+	TokenSecret string
+}
+
+func NewJitsiProvider(baseURL, secret, appID, tokenSecret string) *JitsiProvider {
+	return &JitsiProvider{
+		BaseURL:     strings.TrimRight(baseURL, "/"),
+		Secret:      secret,
+		AppID:       appID,
+		TokenSecret: tokenSecret,
+	}
+}
+
+func (p *JitsiProvider) CreateRoom(ctx context.Context, appointmentID uuid.UUID, start, end time.Time) (Room, error) {
+	if p.Secret == "" {
+		return Room{}, fmt.Errorf("video provider secret not configured")
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.Secret))
+	mac.Write([]byte(appointmentID.String()))
+
+	name := "clinify-" + hex.EncodeToString(mac.Sum(nil))[:24]
+	roomURL := p.BaseURL + "/" + name
+
+	if p.AppID == "" || p.TokenSecret == "" {
+		return Room{Name: name, URL: roomURL}, nil
+	}
+
+	token, err := p.signJoinToken(name, start, end)
+	if err != nil {
+		return Room{}, err
+	}
+
+	return Room{Name: name, URL: roomURL + "?jwt=" + url.QueryEscape(token)}, nil
+}
+
+// signJoinToken issues a token valid from 30 minutes before the session until
+// one hour after it ends.
+func (p *JitsiProvider) signJoinToken(room string, start, end time.Time) (string, error) {
+	parsed, err := url.Parse(p.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid video base url: %w", err)
+	}
+
+	claims := jwt.MapClaims{
+		"aud":  "jitsi",
+		"iss":  p.AppID,
+		"sub":  parsed.Hostname(),
+		"room": room,
+		"nbf":  start.Add(-30 * time.Minute).Unix(),
+		"exp":  end.Add(time.Hour).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	signed, err := token.SignedString([]byte(p.TokenSecret))
+	if err != nil {
+		return "", fmt.Errorf("error signing video token: %w", err)
+	}
+
+	return signed, nil
+}
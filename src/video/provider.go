@@ -0,0 +1,35 @@
+package video
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Room struct {
+	Name string
+	URL  string
+}
+
+// RoomProvider creates the video room a patient and psychologist join for an
+// online appointment. Implementations must return a URL that is unique per
+// appointment and hard to guess.
+type RoomProvider interface {
+	CreateRoom(ctx context.Context, appointmentID uuid.UUID, start, end time.Time) (Room, error)
+}
+
+func NewProviderFromEnv() RoomProvider {
+	baseURL := os.Getenv("VIDEO_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://meet.jit.si"
+	}
+
+	secret := os.Getenv("VIDEO_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+
+	return NewJitsiProvider(baseURL, secret, os.Getenv("VIDEO_JWT_APP_ID"), os.Getenv("VIDEO_JWT_SECRET"))
+}